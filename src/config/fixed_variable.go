@@ -46,10 +46,13 @@ var (
 	PinnedFile       = filepath.Join(SuperFileDataDir, "pinned.json")
 	ToggleDotFile    = filepath.Join(SuperFileDataDir, "toggleDotFile")
 	ToggleFooter     = filepath.Join(SuperFileDataDir, "toggleFooter")
+	CompressChoice   = filepath.Join(SuperFileDataDir, "compressChoice.json")
 
 	// StateDir files
-	LogFile     = filepath.Join(SuperFileStateDir, "superfile.log")
-	LastDirFile = filepath.Join(SuperFileStateDir, "lastdir")
+	LogFile            = filepath.Join(SuperFileStateDir, "superfile.log")
+	LastDirFile        = filepath.Join(SuperFileStateDir, "lastdir")
+	JournalFile        = filepath.Join(SuperFileStateDir, "journal.jsonl")
+	ProcessHistoryFile = filepath.Join(SuperFileStateDir, "process_history.jsonl")
 
 	// Trash Directories
 	DarwinTrashDirectory = filepath.Join(HomeDir, ".Trash")
@@ -42,15 +42,22 @@ func InitIcon(nerdfont bool, directoryIconColor string) {
 		Copy = ""
 		Cut = ""
 		Delete = ""
+		Restore = ""
+		Rename = ""
+		Link = ""
 
 		// other
 		Cursor = ">"
 		Browser = "B"
 		Select = "S"
+		Tree = "T"
+		Flat = "F"
 		Error = ""
 		Warn = ""
 		Done = ""
 		InOperation = ""
+		Pause = ""
+		Queued = ""
 		Directory = ""
 		Search = ""
 		SortAsc = "^"
@@ -26,17 +26,24 @@ var (
 	Copy         = "\U000f018f" // Printable Rune : "󰆏"
 	Cut          = "\U000f0190" // Printable Rune : "󰆐"
 	Delete       = "\U000f01b4" // Printable Rune : "󰆴"
+	Restore      = "\U000f099b" // Printable Rune : "󰦛"
+	Rename       = "\uf044"     // Printable Rune : ""
+	Link         = "\U000f0337" // Printable Rune : "󰌷"
 
 	// other
 	Cursor          = "\uf054"     // Printable Rune : ""
 	Browser         = "\U000f0208" // Printable Rune : "󰈈"
 	Select          = "\U000f01bd" // Printable Rune : "󰆽"
+	Tree            = "\U000f0645" // Printable Rune : "󰙅"
+	Flat            = "\U000f0279" // Printable Rune : "󰉹"
 	CheckboxEmpty   = "\U000f0131" // Printable Rune : "󰄱"
 	CheckboxChecked = "\U000f0856" // Printable Rune : "󰡖"
 	Error           = "\uf530"     // Printable Rune : ""
 	Warn            = "\uf071"     // Printable Rune : ""
 	Done            = "\uf4a4"     // Printable Rune : ""
 	InOperation     = "\U000f0954" // Printable Rune : "󰥔"
+	Pause           = "\uf04c"     // Printable Rune : ""
+	Queued          = "\U000f051f" // Printable Rune : "󰔟"
 	Directory       = "\uf07b"     // Printable Rune : ""
 	Search          = "\ue68f"     // Printable Rune : ""
 	SortAsc         = "\uf0de"     // Printable Rune : ""
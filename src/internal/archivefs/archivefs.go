@@ -0,0 +1,293 @@
+package archivefs
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/yorukot/superfile/src/internal/zipcrypto"
+)
+
+// Stat returns the info of a path inside an archive. The root of an archive is a directory.
+// Any other path is passed to os.Stat
+func Stat(p string) (fs.FileInfo, error) {
+	archive, member, ok := SplitPath(p)
+	if !ok {
+		return os.Stat(p)
+	}
+	idx, err := getIndex(archive)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := idx.members[member]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: p, Err: fs.ErrNotExist}
+	}
+	return info, nil
+}
+
+// ReadDir lists a directory inside an archive, sorted by name like os.ReadDir.
+// Any other path is passed to os.ReadDir
+func ReadDir(p string) ([]os.DirEntry, error) {
+	archive, member, ok := SplitPath(p)
+	if !ok {
+		return os.ReadDir(p)
+	}
+	idx, err := getIndex(archive)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := idx.members[member]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: p, Err: fs.ErrNotExist}
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: p, Err: errors.New("not a directory")}
+	}
+	children := idx.children[member]
+	entries := make([]os.DirEntry, 0, len(children))
+	for _, child := range children {
+		entries = append(entries, fs.FileInfoToDirEntry(idx.members[child]))
+	}
+	slices.SortFunc(entries, func(a, b os.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// Walk calls fn for p and everything under it, directories before their contents.
+// Nothing is read from the archive other than its index
+func Walk(p string, fn func(p string, info fs.FileInfo) error) error {
+	archive, member, ok := SplitPath(p)
+	if !ok {
+		return fmt.Errorf("%s is not inside an archive", p)
+	}
+	idx, err := getIndex(archive)
+	if err != nil {
+		return err
+	}
+	if _, ok = idx.members[member]; !ok {
+		return &fs.PathError{Op: "walk", Path: p, Err: fs.ErrNotExist}
+	}
+	return idx.walk(archive, member, fn)
+}
+
+func (idx *archiveIndex) walk(archive string, member string, fn func(p string, info fs.FileInfo) error) error {
+	if err := fn(memberPath(archive, member), idx.members[member]); err != nil {
+		return err
+	}
+	children := slices.Clone(idx.children[member])
+	slices.Sort(children)
+	for _, child := range children {
+		if err := idx.walk(archive, child, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Open opens a regular file inside an archive for reading. Encrypted files cannot be opened
+func Open(p string) (io.ReadCloser, error) {
+	archive, member, ok := SplitPath(p)
+	if !ok {
+		return nil, fmt.Errorf("%s is not inside an archive", p)
+	}
+	idx, err := getIndex(archive)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := idx.members[member]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: p, Err: fs.ErrNotExist}
+	}
+	if !info.mode.IsRegular() {
+		return nil, &fs.PathError{Op: "open", Path: p, Err: errors.New("not a regular file")}
+	}
+	if info.hardlinkTo != "" {
+		info = idx.members[info.hardlinkTo]
+	}
+
+	if isZip(archive) {
+		return openZipMember(archive, info.seq, "")
+	}
+	tr, closer, err := openTar(archive)
+	if err != nil {
+		return nil, err
+	}
+	for seq := 0; ; seq++ {
+		_, err = tr.Next()
+		if errors.Is(err, io.EOF) {
+			err = fmt.Errorf("%s changed while being read", archive)
+		}
+		if err != nil {
+			closer.Close()
+			return nil, err
+		}
+		if seq == info.seq {
+			return readCloser{Reader: tr, Closer: closer}, nil
+		}
+	}
+}
+
+func openZipMember(archive string, seq int, password string) (io.ReadCloser, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	if seq >= len(r.File) {
+		r.Close()
+		return nil, fmt.Errorf("%s changed while being read", archive)
+	}
+	rc, err := openZipFile(r.File[seq], password)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	return readCloser{Reader: rc, Closer: multiCloser{r, rc}}, nil
+}
+
+func openZipFile(f *zip.File, password string) (io.ReadCloser, error) {
+	if zipcrypto.IsEncrypted(f) {
+		return zipcrypto.Open(f, password)
+	}
+	return f.Open()
+}
+
+// CheckPassword checks password against the first encrypted file at or under p. Returns
+// zipcrypto.ErrPasswordRequired if password is empty, and zipcrypto.ErrWrongPassword if it
+// is wrong. Nothing is checked if no file under p is encrypted
+func CheckPassword(p string, password string) error {
+	archive, member, ok := SplitPath(p)
+	if !ok {
+		return fmt.Errorf("%s is not inside an archive", p)
+	}
+	idx, err := getIndex(archive)
+	if err != nil {
+		return err
+	}
+	if _, ok = idx.members[member]; !ok {
+		return &fs.PathError{Op: "check", Path: p, Err: fs.ErrNotExist}
+	}
+	var encrypted *memberInfo
+	_ = idx.walk(archive, member, func(_ string, info fs.FileInfo) error {
+		if mi, ok := info.(*memberInfo); ok && mi.encrypted {
+			encrypted = mi
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if encrypted == nil {
+		return nil
+	}
+	rc, err := openZipMember(archive, encrypted.seq, password)
+	if err != nil {
+		return err
+	}
+	return rc.Close()
+}
+
+// ReadFiles calls fn for every file at or under p that is not a directory, in a single
+// pass over the archive. The files are visited in the order they are in the archive.
+// password is used for encrypted files, which only zip archives can have
+func ReadFiles(p string, password string, fn func(f File) error) error {
+	archive, member, ok := SplitPath(p)
+	if !ok {
+		return fmt.Errorf("%s is not inside an archive", p)
+	}
+	idx, err := getIndex(archive)
+	if err != nil {
+		return err
+	}
+	if _, ok = idx.members[member]; !ok {
+		return &fs.PathError{Op: "read", Path: p, Err: fs.ErrNotExist}
+	}
+	if isZip(archive) {
+		return idx.readZipFiles(archive, member, password, fn)
+	}
+	return idx.readTarFiles(archive, member, fn)
+}
+
+func (idx *archiveIndex) readZipFiles(archive string, dir string, password string, fn func(f File) error) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for seq, f := range r.File {
+		member := cleanMemberName(f.Name)
+		info, ok := idx.members[member]
+		if !ok || info.seq != seq || info.IsDir() || !isUnder(member, dir) {
+			continue
+		}
+		if err = idx.readZipFile(archive, member, f, password, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (idx *archiveIndex) readZipFile(archive string, member string, f *zip.File, password string,
+	fn func(f File) error) error {
+	rc, err := openZipFile(f, password)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	file := File{Path: memberPath(archive, member), Info: idx.members[member], Reader: rc}
+	// Zip archives keep the target of symlinks as their content
+	if file.Info.Mode()&fs.ModeSymlink != 0 {
+		link, err := io.ReadAll(rc)
+		if err != nil {
+			return err
+		}
+		file.Link = string(link)
+	}
+	return fn(file)
+}
+
+func (idx *archiveIndex) readTarFiles(archive string, dir string, fn func(f File) error) error {
+	tr, closer, err := openTar(archive)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	for seq := 0; ; seq++ {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		member := cleanMemberName(header.Name)
+		info, ok := idx.members[member]
+		if !ok || info.seq != seq || info.IsDir() || !isUnder(member, dir) {
+			continue
+		}
+		file := File{Path: memberPath(archive, member), Info: info, Link: info.link, Reader: tr}
+		if info.hardlinkTo != "" {
+			err = readHardlink(file, fn)
+		} else {
+			err = fn(file)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Hardlinks in tar archives have no content. It is read from the member they refer to
+func readHardlink(file File, fn func(f File) error) error {
+	rc, err := Open(file.Path)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	file.Reader = rc
+	return fn(file)
+}
@@ -0,0 +1,196 @@
+package archivefs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMember struct {
+	name    string
+	content string
+	link    string
+}
+
+func writeTestZip(t *testing.T, archive string, members []testMember) {
+	t.Helper()
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for _, m := range members {
+		fw, err := w.Create(m.name)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(m.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+}
+
+func writeTestTarGz(t *testing.T, archive string, members []testMember) {
+	t.Helper()
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	w := tar.NewWriter(gz)
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, m := range members {
+		header := &tar.Header{Name: m.name, Mode: 0o644, Size: int64(len(m.content)),
+			ModTime: modTime, Typeflag: tar.TypeReg}
+		switch {
+		case m.name[len(m.name)-1] == '/':
+			header.Typeflag, header.Mode = tar.TypeDir, 0o755
+		case m.link != "":
+			header.Typeflag, header.Linkname, header.Size = tar.TypeSymlink, m.link, 0
+		}
+		require.NoError(t, w.WriteHeader(header))
+		_, err = w.Write([]byte(m.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, gz.Close())
+}
+
+func readDirNames(t *testing.T, p string) []string {
+	t.Helper()
+	entries, err := ReadDir(p)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestPaths(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "foo.tar.gz")
+	dir := filepath.Dir(archive)
+
+	t.Run("Split path", func(t *testing.T) {
+		testdata := []struct {
+			path    string
+			archive string
+			member  string
+			ok      bool
+		}{
+			{path: archive + "//a/b", archive: archive, member: "a/b", ok: true},
+			{path: archive + "//", archive: archive, member: "", ok: true},
+			{path: archive + "//a/../../b/", archive: archive, member: "b", ok: true},
+			{path: archive, ok: false},
+			{path: filepath.Join(dir, "foo.txt") + "//a", ok: false},
+			{path: "//foo.zip", ok: false},
+		}
+		for _, tt := range testdata {
+			a, m, ok := SplitPath(tt.path)
+			assert.Equal(t, tt.ok, ok, tt.path)
+			assert.Equal(t, tt.archive, a, tt.path)
+			assert.Equal(t, tt.member, m, tt.path)
+		}
+	})
+
+	t.Run("Join and Dir", func(t *testing.T) {
+		root := Root(archive)
+		assert.Equal(t, archive+"//a", Join(root, "a"))
+		assert.Equal(t, archive+"//a/b", Join(archive+"//a", "b"))
+		assert.Equal(t, root, Join(archive+"//a", ".."))
+		assert.Equal(t, dir, Join(archive+"//a", "../.."))
+		assert.Equal(t, filepath.Join(dir, "other"), Join(root, "../other"))
+		assert.Equal(t, filepath.Join(dir, "a"), Join(dir, "a"))
+		assert.Equal(t, root, Dir(archive+"//a"))
+		assert.Equal(t, dir, Dir(root))
+		assert.Equal(t, dir, Dir(archive))
+		assert.Equal(t, dir, OuterDir(archive+"//a/b"))
+		assert.Equal(t, dir, OuterDir(dir))
+		assert.Equal(t, archive+"//a", Clean(archive+"//a/./b/.."))
+	})
+}
+
+func TestBrowseArchive(t *testing.T) {
+	members := []testMember{
+		{name: "top.txt", content: "top"},
+		{name: "dir/", content: ""},
+		{name: "dir/file.txt", content: "old"},
+		// Its parent directories are not listed in the archive
+		{name: "implied/sub/deep.txt", content: "deep"},
+		{name: "../escape.txt", content: "escape"},
+		{name: "dir/link", link: "file.txt"},
+		// Only the last copy of a member counts
+		{name: "dir/file.txt", content: "new content"},
+	}
+	tempDir := t.TempDir()
+	tarGz := filepath.Join(tempDir, "test.tar.gz")
+	writeTestTarGz(t, tarGz, members)
+	zipFile := filepath.Join(tempDir, "test.zip")
+	writeTestZip(t, zipFile, members[:5])
+
+	for _, archive := range []string{tarGz, zipFile} {
+		t.Run(filepath.Base(archive), func(t *testing.T) {
+			root := Root(archive)
+			info, err := Stat(root)
+			require.NoError(t, err)
+			assert.True(t, info.IsDir())
+			assert.Equal(t, filepath.Base(archive), info.Name())
+
+			assert.Equal(t, []string{"dir", "implied", "top.txt"}, readDirNames(t, root))
+			assert.Equal(t, []string{"sub"}, readDirNames(t, Join(root, "implied")))
+			_, err = ReadDir(Join(root, "top.txt"))
+			require.Error(t, err)
+			_, err = Stat(Join(root, "missing"))
+			require.ErrorIs(t, err, fs.ErrNotExist)
+
+			var walked []string
+			require.NoError(t, Walk(Join(root, "implied"), func(p string, _ fs.FileInfo) error {
+				walked = append(walked, p)
+				return nil
+			}))
+			assert.Equal(t, []string{root + "implied", root + "implied/sub", root + "implied/sub/deep.txt"}, walked)
+
+			rc, err := Open(Join(root, "implied/sub/deep.txt"))
+			require.NoError(t, err)
+			data, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+			assert.Equal(t, "deep", string(data))
+		})
+	}
+
+	t.Run("Duplicates and symlinks", func(t *testing.T) {
+		root := Root(tarGz)
+		assert.Equal(t, []string{"file.txt", "link"}, readDirNames(t, Join(root, "dir")))
+		info, err := Stat(Join(root, "dir/file.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(len("new content")), info.Size())
+
+		files := make(map[string]string)
+		require.NoError(t, ReadFiles(Join(root, "dir"), "", func(f File) error {
+			data, err := io.ReadAll(f)
+			if err != nil {
+				return err
+			}
+			files[f.Path] = string(data) + f.Link
+			return nil
+		}))
+		assert.Equal(t, map[string]string{
+			root + "dir/file.txt": "new content",
+			root + "dir/link":     "file.txt",
+		}, files)
+	})
+
+	t.Run("Index is refreshed when the archive changes", func(t *testing.T) {
+		writeTestZip(t, zipFile, []testMember{{name: "changed.txt"}})
+		// Make sure the modification time differs, even on coarse file systems
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(zipFile, later, later))
+		assert.Equal(t, []string{"changed.txt"}, readDirNames(t, Root(zipFile)))
+	})
+}
@@ -0,0 +1,12 @@
+package archivefs
+
+// Separator between the path of an archive and the path of a member inside it
+const Separator = "//"
+
+// Indexes of the least recently read archives are dropped beyond this
+const maxCachedArchives = 8
+
+// Extensions of the archives that can be browsed
+var archiveExts = []string{ //nolint: gochecknoglobals // This is effectively const.
+	".zip", ".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tbz2", ".tar.xz", ".txz", ".tar.zst", ".tzst",
+}
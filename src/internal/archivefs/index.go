@@ -0,0 +1,260 @@
+package archivefs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+
+	"github.com/yorukot/superfile/src/internal/zipcrypto"
+)
+
+var (
+	cacheMu sync.Mutex                       //nolint: gochecknoglobals // Guards cache
+	cache   = make(map[string]*archiveIndex) //nolint: gochecknoglobals // Indexes are shared by all panels
+)
+
+// getIndex returns the index of archive, reading the archive unless its cached index is up to date
+func getIndex(archive string) (*archiveIndex, error) {
+	info, err := os.Stat(archive)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", archive)
+	}
+
+	cacheMu.Lock()
+	idx, ok := cache[archive]
+	if ok && idx.size == info.Size() && idx.modTime.Equal(info.ModTime()) {
+		idx.lastUsed = time.Now()
+		cacheMu.Unlock()
+		return idx, nil
+	}
+	cacheMu.Unlock()
+
+	// Reading the archive can take a while, so it is done without holding the lock
+	idx, err = readIndex(archive, info)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read archive %s: %w", archive, err)
+	}
+	slog.Debug("Indexed archive", "archive", archive, "members", len(idx.members))
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if len(cache) >= maxCachedArchives {
+		evictOldestIndex()
+	}
+	cache[archive] = idx
+	return idx, nil
+}
+
+// Must be called with cacheMu held
+func evictOldestIndex() {
+	oldest := ""
+	for archive, idx := range cache {
+		if oldest == "" || idx.lastUsed.Before(cache[oldest].lastUsed) {
+			oldest = archive
+		}
+	}
+	delete(cache, oldest)
+}
+
+func readIndex(archive string, archiveInfo fs.FileInfo) (*archiveIndex, error) {
+	idx := &archiveIndex{
+		size:     archiveInfo.Size(),
+		modTime:  archiveInfo.ModTime(),
+		members:  make(map[string]*memberInfo),
+		children: make(map[string][]string),
+		lastUsed: time.Now(),
+	}
+	idx.members[""] = &memberInfo{
+		name:    filepath.Base(archive),
+		mode:    fs.ModeDir | 0o755,
+		modTime: archiveInfo.ModTime(),
+		seq:     -1,
+	}
+
+	if isZip(archive) {
+		r, err := zip.OpenReader(archive)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		for i, f := range r.File {
+			idx.add(f.Name, &memberInfo{
+				size:    int64(f.UncompressedSize64), //nolint:gosec // Sizes beyond int64 are not realistic
+				mode:    f.Mode(),
+				modTime: f.Modified,
+				seq:     i,
+				// Only the contents are encrypted, not the names
+				encrypted: zipcrypto.IsEncrypted(f),
+			})
+		}
+		return idx, nil
+	}
+
+	tr, closer, err := openTar(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	for seq := 0; ; seq++ {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		info := &memberInfo{
+			size:    header.Size,
+			mode:    header.FileInfo().Mode(),
+			modTime: header.ModTime,
+			seq:     seq,
+		}
+		switch header.Typeflag {
+		case tar.TypeSymlink:
+			info.link = header.Linkname
+		case tar.TypeLink:
+			target, ok := idx.members[cleanMemberName(header.Linkname)]
+			if !ok || !target.mode.IsRegular() {
+				slog.Warn("Ignoring hardlink to unknown member", "archive", archive, "member", header.Name)
+				continue
+			}
+			info.size = target.size
+			info.mode = target.mode
+			info.hardlinkTo = cleanMemberName(header.Linkname)
+		}
+		idx.add(header.Name, info)
+	}
+	return idx, nil
+}
+
+// cleanMemberName returns the slash separated path of a member, relative to the root
+// of the archive. It is empty for names that lead outside of the archive
+func cleanMemberName(name string) string {
+	name = path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimPrefix(name, "/")
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return ""
+	}
+	return name
+}
+
+// add adds a member, along with the directories that are only implied by its path
+func (idx *archiveIndex) add(name string, info *memberInfo) {
+	name = cleanMemberName(name)
+	if name == "" {
+		return
+	}
+	info.name = path.Base(name)
+	if _, exists := idx.members[name]; !exists {
+		idx.addParents(name)
+		parent := parentMember(name)
+		idx.children[parent] = append(idx.children[parent], name)
+	}
+	// The last copy of a member is what gets extracted
+	idx.members[name] = info
+}
+
+func (idx *archiveIndex) addParents(name string) {
+	parent := parentMember(name)
+	if _, exists := idx.members[parent]; exists {
+		return
+	}
+	idx.addParents(parent)
+	idx.members[parent] = &memberInfo{
+		name:    path.Base(parent),
+		mode:    fs.ModeDir | 0o755,
+		modTime: idx.modTime,
+		seq:     -1,
+	}
+	grandParent := parentMember(parent)
+	idx.children[grandParent] = append(idx.children[grandParent], parent)
+}
+
+func parentMember(name string) string {
+	parent := path.Dir(name)
+	if parent == "." {
+		return ""
+	}
+	return parent
+}
+
+// Reports whether member is under dir, or is dir itself
+func isUnder(member string, dir string) bool {
+	return dir == "" || member == dir || strings.HasPrefix(member, dir+"/")
+}
+
+func isZip(archive string) bool {
+	return strings.HasSuffix(strings.ToLower(archive), ".zip")
+}
+
+// openTar opens a tar archive, decompressing it based on its extension
+func openTar(archive string) (*tar.Reader, io.Closer, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, nil, err
+	}
+	closers := multiCloser{f}
+	var r io.Reader = bufio.NewReader(f)
+	lower := strings.ToLower(archive)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		var gz *gzip.Reader
+		gz, err = gzip.NewReader(r)
+		if err == nil {
+			r = gz
+			closers = append(closers, gz)
+		}
+	case strings.HasSuffix(lower, ".tar.bz2"), strings.HasSuffix(lower, ".tbz2"):
+		r = bzip2.NewReader(r)
+	case strings.HasSuffix(lower, ".tar.xz"), strings.HasSuffix(lower, ".txz"):
+		r, err = xz.NewReader(r)
+	case strings.HasSuffix(lower, ".tar.zst"), strings.HasSuffix(lower, ".tzst"):
+		var zr *zstd.Decoder
+		zr, err = zstd.NewReader(r)
+		if err == nil {
+			r = zr
+			closers = append(closers, zr.IOReadCloser())
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return tar.NewReader(r), closers, nil
+}
+
+// multiCloser closes everything in it, in reverse order
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var err error
+	for i := len(m) - 1; i >= 0; i-- {
+		err = errors.Join(err, m[i].Close())
+	}
+	return err
+}
+
+// readCloser is a member being read, and the archive it is read from
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
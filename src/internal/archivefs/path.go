@@ -0,0 +1,91 @@
+package archivefs
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IsArchive reports whether the file at p is an archive that can be browsed, by its extension
+func IsArchive(p string) bool {
+	lower := strings.ToLower(p)
+	for _, ext := range archiveExts {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsArchivePath reports whether p is a path inside an archive
+func IsArchivePath(p string) bool {
+	_, _, ok := SplitPath(p)
+	return ok
+}
+
+// SplitPath splits a path inside an archive into the path of the archive, and the slash
+// separated path of the member, which is empty for the root of the archive.
+// ok is false if p is not a path inside an archive
+func SplitPath(p string) (string, string, bool) {
+	idx := strings.Index(p, Separator)
+	if idx <= 0 || !IsArchive(p[:idx]) {
+		return "", "", false
+	}
+	member := path.Clean("/" + p[idx+len(Separator):])
+	return p[:idx], strings.TrimPrefix(member, "/"), true
+}
+
+// Root returns the path of the root directory of archive
+func Root(archive string) string {
+	return memberPath(archive, "")
+}
+
+func memberPath(archive string, member string) string {
+	return archive + Separator + member
+}
+
+// Clean returns the shortest form of p, like filepath.Clean, without
+// losing the separator of paths inside archives
+func Clean(p string) string {
+	archive, member, ok := SplitPath(p)
+	if !ok {
+		return filepath.Clean(p)
+	}
+	return memberPath(filepath.Clean(archive), member)
+}
+
+// Join joins a relative path to dir. Going above the root of an archive leads
+// to the directory the archive is in
+func Join(dir string, elem string) string {
+	archive, member, ok := SplitPath(dir)
+	if !ok {
+		return filepath.Join(dir, elem)
+	}
+	joined := path.Join(member, filepath.ToSlash(elem))
+	if joined == ".." || strings.HasPrefix(joined, "../") {
+		return filepath.Join(filepath.Dir(archive), strings.TrimPrefix(joined, ".."))
+	}
+	if joined == "." {
+		joined = ""
+	}
+	return memberPath(archive, joined)
+}
+
+// Dir returns the parent directory of p. The parent of the root of an
+// archive is the directory the archive is in
+func Dir(p string) string {
+	if !IsArchivePath(p) {
+		return filepath.Dir(p)
+	}
+	return Join(p, "..")
+}
+
+// OuterDir returns the closest real directory to p. That is p itself
+// unless it is inside an archive
+func OuterDir(p string) string {
+	archive, _, ok := SplitPath(p)
+	if !ok {
+		return p
+	}
+	return filepath.Dir(archive)
+}
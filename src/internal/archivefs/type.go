@@ -0,0 +1,54 @@
+package archivefs
+
+import (
+	"io"
+	"io/fs"
+	"time"
+)
+
+// memberInfo is the fs.FileInfo of a member of an archive
+type memberInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+	// Target of symlinks
+	link string
+	// Path of the member that a tar hardlink refers to. Its content is read from there
+	hardlinkTo string
+	// Position of the member in the archive. Archives can contain the same member more
+	// than once, and only the last copy is indexed
+	seq int
+	// Whether the content of the member is encrypted, and needs a password to be read
+	encrypted bool
+}
+
+func (i *memberInfo) Name() string       { return i.name }
+func (i *memberInfo) Size() int64        { return i.size }
+func (i *memberInfo) Mode() fs.FileMode  { return i.mode }
+func (i *memberInfo) ModTime() time.Time { return i.modTime }
+func (i *memberInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *memberInfo) Sys() any           { return nil }
+
+// archiveIndex lists all the members of an archive, by their slash separated path.
+// The root of the archive is the member with an empty path
+type archiveIndex struct {
+	// Size and modification time of the archive when it was indexed
+	size    int64
+	modTime time.Time
+	members map[string]*memberInfo
+	// Paths of the members directly inside each directory
+	children map[string][]string
+	lastUsed time.Time
+}
+
+// File is a file inside an archive, as read by ReadFiles
+type File struct {
+	// Path of the file, including the path of the archive
+	Path string
+	Info fs.FileInfo
+	// Target of symlinks
+	Link string
+	// Content of regular files. It is only valid until the callback returns
+	io.Reader
+}
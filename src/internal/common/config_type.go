@@ -78,6 +78,16 @@ type ConfigType struct {
 	SortOrderReversed      bool   `toml:"sort_order_reversed" comment:"\nDefault sort order (false: Ascending, true: Descending)."`
 	CaseSensitiveSort      bool   `toml:"case_sensitive_sort" comment:"\nCase sensitive sort by name (capital \"B\" comes before \"a\" if true)."`
 	ShellCloseOnSuccess    bool   `toml:"shell_close_on_success" comment:"\nWhether to close the shell on successful command execution."`
+	ArchiveCopy            bool   `toml:"archive_copy" comment:"\nWhether to preserve timestamps, permissions, ownership (when running as root) and extended attributes when copying."`
+	DereferenceSymlinks    bool   `toml:"dereference_symlinks" comment:"\nWhether to copy the files and directories that symlinks point to, instead of the symlinks themselves."`
+	VerifyCopy             string `toml:"verify_copy" comment:"\nWhen to re-read pasted files and compare their checksums with the source (\"\": Never, \"external\": Only when pasting to external disks, \"always\": Always)."`
+	BrowseArchives         bool   `toml:"browse_archives" comment:"\nWhether to open zip and tar archives as read-only directories, instead of with the default application."`
+	FinishedProcessTTL     int    `toml:"finished_process_ttl" comment:"\nMinutes after which finished processes are removed from the process bar (0: Never). They stay in the process history."`
+	MaxFinishedProcesses   int    `toml:"max_finished_processes" comment:"\nMaximum count of finished processes kept in the process bar, the oldest being removed first (0: No limit)."`
+	MaxProcessesPerDevice  int    `toml:"max_processes_per_device" comment:"\nMaximum count of paste, delete, compress and extract processes running at once on the same device. The other ones are queued (0: No limit)."`
+	NotificationThreshold  int    `toml:"notification_threshold" comment:"\nSeconds a process has to run for, to send a notification when it finishes (0: Never)."`
+	NotificationMethod     string `toml:"notification_method" comment:"\nHow the notification is sent (\"auto\": What the terminal supports, \"osc9\", \"osc777\", \"bell\": The terminal bell, \"none\": Only with notification_command)."`
+	NotificationCommand    string `toml:"notification_command" comment:"\nCommand also run to send the notification, like \"notify-send\", with the title and the message as its last two arguments (\"\": None)."`
 	Debug                  bool   `toml:"debug" comment:"\nWhether to enable debug mode."`
 	// IgnoreMissingFields controls whether warnings about missing TOML fields are suppressed.
 	IgnoreMissingFields bool `toml:"ignore_missing_fields" comment:"\nWhether to ignore warnings about missing fields in the config file."`
@@ -86,6 +96,7 @@ type ConfigType struct {
 	ShowSelectIcons       bool   `toml:"show_select_icons" comment:"\nShow checkbox icons in select mode (requires nerdfont)"`
 	TransparentBackground bool   `toml:"transparent_background" comment:"\nSet transparent background or not (this only work when your terminal background is transparent)"`
 	FilePreviewWidth      int    `toml:"file_preview_width" comment:"\nFile preview width allow '0' (this mean same as file panel),'x' x must be less than 10 and greater than 1 (This means that the width of the file preview will be one xth of the total width.)"`
+	FilePanelLayout       string `toml:"file_panel_layout" comment:"\nLayout of the file panels (\"panels\": Side by side, \"miller\": Miller columns, the focused panel between its parent directory and the file preview)."`
 	CodePreviewer         string `toml:"code_previewer" comment:"\nWhether to use the builtin syntax highlighting with chroma or use bat. Values: \"\" for builtin chroma, \"bat\" for bat"`
 	SidebarWidth          int    `toml:"sidebar_width" comment:"\nThe length of the sidebar. If you don't want to display the sidebar, you can input 0 directly. If you want to display the value, please place it in the range of 3-20."`
 
@@ -138,9 +149,16 @@ type HotkeysType struct {
 
 	CopyItems              []string `toml:"copy_items" comment:"file operate"`
 	PasteItems             []string `toml:"paste_items"`
+	PasteAsSymlink         []string `toml:"paste_as_symlink"`
+	PasteAsRelativeSymlink []string `toml:"paste_as_relative_symlink"`
+	PasteAsHardlink        []string `toml:"paste_as_hardlink"`
 	CutItems               []string `toml:"cut_items"`
 	DeleteItems            []string `toml:"delete_items"`
 	PermanentlyDeleteItems []string `toml:"permanently_delete_items"`
+	CopyToNextPanel        []string `toml:"copy_to_next_panel"`
+	MoveToNextPanel        []string `toml:"move_to_next_panel"`
+	Undo                   []string `toml:"undo"`
+	Redo                   []string `toml:"redo"`
 
 	ExtractFile  []string `toml:"extract_file" comment:"compress and extract"`
 	CompressFile []string `toml:"compress_file"`
@@ -148,13 +166,18 @@ type HotkeysType struct {
 	OpenFileWithEditor             []string `toml:"open_file_with_editor" comment:"editor"`
 	OpenCurrentDirectoryWithEditor []string `toml:"open_current_directory_with_editor"`
 
-	PinnedDirectory []string `toml:"pinned_directory" comment:"other"`
-	ToggleDotFile   []string `toml:"toggle_dot_file"`
-	ChangePanelMode []string `toml:"change_panel_mode"`
-	OpenHelpMenu    []string `toml:"open_help_menu"`
-	OpenCommandLine []string `toml:"open_command_line"`
-	OpenSPFPrompt   []string `toml:"open_spf_prompt"`
-	OpenZoxide      []string `toml:"open_zoxide"`
+	PinnedDirectory    []string `toml:"pinned_directory" comment:"other"`
+	ToggleDotFile      []string `toml:"toggle_dot_file"`
+	ChangePanelMode    []string `toml:"change_panel_mode"`
+	ToggleTreeView     []string `toml:"toggle_tree_view"`
+	ToggleFlatView     []string `toml:"toggle_flat_view"`
+	OpenContentSearch  []string `toml:"open_content_search"`
+	OpenHelpMenu       []string `toml:"open_help_menu"`
+	OpenCommandLine    []string `toml:"open_command_line"`
+	OpenSPFPrompt      []string `toml:"open_spf_prompt"`
+	OpenZoxide         []string `toml:"open_zoxide"`
+	OpenTrash          []string `toml:"open_trash"`
+	OpenProcessHistory []string `toml:"open_process_history"`
 
 	CopyPath []string `toml:"copy_path"`
 	CopyPWD  []string `toml:"copy_present_working_directory"`
@@ -163,6 +186,8 @@ type HotkeysType struct {
 
 	ConfirmTyping []string `toml:"confirm_typing" comment:"=================================================================================================\nTyping hotkeys (can conflict with all hotkeys)"`
 	CancelTyping  []string `toml:"cancel_typing"`
+	NextInput     []string `toml:"next_input"`
+	ToggleCase    []string `toml:"toggle_case"`
 
 	ParentDirectory []string `toml:"parent_directory" comment:"=================================================================================================\nNormal mode hotkeys (can conflict with other modes, cannot conflict with global hotkeys)"`
 	SearchBar       []string `toml:"search_bar"`
@@ -170,4 +195,18 @@ type HotkeysType struct {
 	FilePanelSelectModeItemsSelectDown []string `toml:"file_panel_select_mode_items_select_down" comment:"=================================================================================================\nSelect mode hotkeys (can conflict with other modes, cannot conflict with global hotkeys)"`
 	FilePanelSelectModeItemsSelectUp   []string `toml:"file_panel_select_mode_items_select_up"`
 	FilePanelSelectAllItem             []string `toml:"file_panel_select_all_items"`
+	BulkRename                         []string `toml:"bulk_rename"`
+	PatternRename                      []string `toml:"pattern_rename"`
+
+	CancelProcess      []string `toml:"cancel_process" comment:"=================================================================================================\nProcess bar hotkeys (can conflict with other modes, cannot conflict with global hotkeys)"`
+	TogglePauseProcess []string `toml:"toggle_pause_process"`
+	RetryProcess       []string `toml:"retry_process"`
+	MoveProcessUp      []string `toml:"move_process_up"`
+	MoveProcessDown    []string `toml:"move_process_down"`
+	PrioritizeProcess  []string `toml:"prioritize_process"`
+
+	EmptyTrash []string `toml:"empty_trash" comment:"=================================================================================================\nTrash browser hotkeys (can conflict with other modes, cannot conflict with global hotkeys)"`
+
+	ToggleContentSearchRegex []string `toml:"toggle_content_search_regex" comment:"=================================================================================================\nContent search hotkeys (can conflict with other modes, cannot conflict with global hotkeys)"`
+	SelectContentSearchFile  []string `toml:"select_content_search_file"`
 }
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 
 	"github.com/charmbracelet/x/ansi"
 	"github.com/pelletier/go-toml/v2"
@@ -65,6 +66,35 @@ func ValidateConfig(c *ConfigType) error {
 		return errors.New(LoadConfigError("default_sort_type"))
 	}
 
+	if c.FinishedProcessTTL < 0 {
+		return errors.New(LoadConfigError("finished_process_ttl"))
+	}
+
+	if c.MaxFinishedProcesses < 0 {
+		return errors.New(LoadConfigError("max_finished_processes"))
+	}
+
+	if c.MaxProcessesPerDevice < 0 {
+		return errors.New(LoadConfigError("max_processes_per_device"))
+	}
+
+	if c.NotificationThreshold < 0 {
+		return errors.New(LoadConfigError("notification_threshold"))
+	}
+
+	if !slices.Contains([]string{NotificationAuto, NotificationOSC9, NotificationOSC777, NotificationBell,
+		NotificationNone}, c.NotificationMethod) {
+		return errors.New(LoadConfigError("notification_method"))
+	}
+
+	if c.FilePanelLayout != FilePanelLayoutPanels && c.FilePanelLayout != FilePanelLayoutMiller {
+		return errors.New(LoadConfigError("file_panel_layout"))
+	}
+
+	if c.VerifyCopy != VerifyCopyNever && c.VerifyCopy != VerifyCopyExternal && c.VerifyCopy != VerifyCopyAlways {
+		return errors.New(LoadConfigError("verify_copy"))
+	}
+
 	if ansi.StringWidth(c.BorderTop) != 1 {
 		return errors.New(LoadConfigError("border_top"))
 	}
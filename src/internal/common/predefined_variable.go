@@ -15,10 +15,46 @@ const DateModifiedOption = "Date Modified"
 const SameRenameWarnTitle = "There is already a file or directory with that name"
 const SameRenameWarnContent = "This operation will override the existing file"
 
+const PasteConflictTitle = "There is already a file or directory with that name"
+const PasteConflictContent = "\"%s\" already exists. %d conflict(s) left"
+
+// Values of the verify_copy config
+const (
+	VerifyCopyNever    = ""
+	VerifyCopyExternal = "external"
+	VerifyCopyAlways   = "always"
+)
+
+// Values of the notification_method config
+const (
+	NotificationAuto   = "auto"
+	NotificationOSC9   = "osc9"
+	NotificationOSC777 = "osc777"
+	NotificationBell   = "bell"
+	NotificationNone   = "none"
+)
+
+// Values of the file_panel_layout config
+const (
+	FilePanelLayoutPanels = "panels"
+	FilePanelLayoutMiller = "miller"
+)
+
+const UndoFailedTitle = "Cannot undo the last operation"
+const RedoFailedTitle = "Cannot redo the operation"
+const BulkRenameFailedTitle = "Cannot rename the items"
+
+const ArchiveReadOnlyTitle = "Archives are read-only"
+const ArchiveReadOnlyContent = "Copy the items out of the archive to change them."
+const ArchiveMemberOpenTitle = "Cannot open files inside archives"
+const ArchiveMemberOpenContent = "Copy the file out of the archive to open it."
+
 const TrashWarnTitle = "Are you sure you want to move this to trash can"
 const TrashWarnContent = "This operation will move file or directory to trash can."
 const PermanentDeleteWarnTitle = "Are you sure you want to completely delete"
 const PermanentDeleteWarnContent = "This operation cannot be undone and your data will be completely lost."
+const DeleteTrashItemWarnTitle = "Are you sure you want to delete this item from the trash"
+const EmptyTrashWarnTitle = "Are you sure you want to empty the trash"
 
 const (
 	MinimumHeight = 24
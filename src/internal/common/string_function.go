@@ -73,6 +73,21 @@ func PrettierName(name string, width int, isDir bool, isSelected bool, bgColor l
 		FilePanelStyle.Render(TruncateText(name, width, "..."))
 }
 
+// PrettierSymlinkName renders the symlink like PrettierName, followed by the target it points to
+func PrettierSymlinkName(name string, target string, width int, isDir bool, isSelected bool,
+	bgColor lipgloss.Color) string {
+	style := GetElementIcon(name, isDir, Config.Nerdfont)
+	text := TruncateText(name+" -> "+target, width, "...")
+	textStyle := FilePanelStyle
+	if isSelected {
+		textStyle = FilePanelItemSelectedStyle
+	}
+	return StringColorRender(lipgloss.Color(style.Color), bgColor).
+		Background(bgColor).
+		Render(style.Icon+" ") +
+		textStyle.Render(text)
+}
+
 func PrettierDirectoryPreviewName(name string, isDir bool, bgColor lipgloss.Color) string {
 	style := GetElementIcon(name, isDir, Config.Nerdfont)
 	return StringColorRender(lipgloss.Color(style.Color), bgColor).
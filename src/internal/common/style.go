@@ -37,6 +37,7 @@ var (
 	FilePanelTopPathStyle          lipgloss.Style
 	FilePanelItemSelectedStyle     lipgloss.Style
 	FilePanelSelectBoxStyle        lipgloss.Style
+	FilePanelTreeGuideStyle        lipgloss.Style
 )
 
 var (
@@ -193,6 +194,7 @@ func LoadThemeConfig() { //nolint: funlen // Variable initialization
 	FilePanelItemSelectedStyle = lipgloss.NewStyle().Foreground(filePanelItemSelectedFGColor).
 		Background(filePanelItemSelectedBGColor)
 	FilePanelSelectBoxStyle = lipgloss.NewStyle().Background(FilePanelBGColor)
+	FilePanelTreeGuideStyle = lipgloss.NewStyle().Foreground(FilePanelBorderColor).Background(FilePanelBGColor)
 
 	// Sidebar Special Style
 	SidebarDividerStyle = lipgloss.NewStyle().Foreground(sidebarDividerColor).Background(SidebarBGColor)
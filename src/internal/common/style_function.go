@@ -273,6 +273,33 @@ func GenerateNewFileTextInput() textinput.Model {
 	return t
 }
 
+// The typed password is masked
+func GeneratePasswordTextInput() textinput.Model {
+	t := textinput.New()
+	t.Cursor.Style = ModalCursorStyle
+	t.Cursor.TextStyle = ModalStyle
+	t.TextStyle = ModalStyle
+	t.Cursor.Blink = true
+	t.EchoMode = textinput.EchoPassword
+	t.Focus()
+	t.CharLimit = 256
+	t.Width = ModalWidth - 10
+	return t
+}
+
+func GeneratePatternRenameTextInput(width int, placeholder string) textinput.Model {
+	t := textinput.New()
+	t.Cursor.Style = ModalCursorStyle
+	t.Cursor.TextStyle = ModalStyle
+	t.TextStyle = ModalStyle
+	t.Cursor.Blink = true
+	t.Placeholder = placeholder
+	t.PlaceholderStyle = ModalStyle
+	t.CharLimit = 256
+	t.Width = width
+	return t
+}
+
 func GenerateRenameTextInput(width int, cursorPos int, defaultValue string) textinput.Model {
 	ti := textinput.New()
 	ti.Cursor.Style = FilePanelCursorStyle
@@ -1,18 +1,25 @@
 package internal
 
 import (
+	"os"
 	"path/filepath"
+	"runtime"
 
 	zoxidelib "github.com/lazysegtree/go-zoxide"
 
+	variable "github.com/yorukot/superfile/src/config"
+	"github.com/yorukot/superfile/src/internal/journal"
 	"github.com/yorukot/superfile/src/internal/ui/metadata"
 	"github.com/yorukot/superfile/src/internal/ui/processbar"
 	"github.com/yorukot/superfile/src/internal/ui/sidebar"
 
 	"github.com/yorukot/superfile/src/internal/common"
+	contentsearchui "github.com/yorukot/superfile/src/internal/ui/contentsearch"
 	"github.com/yorukot/superfile/src/internal/ui/preview"
 	"github.com/yorukot/superfile/src/internal/ui/prompt"
+	trashui "github.com/yorukot/superfile/src/internal/ui/trash"
 	zoxideui "github.com/yorukot/superfile/src/internal/ui/zoxide"
+	"github.com/yorukot/superfile/src/internal/utils"
 )
 
 // Generate and return model containing default configurations for interface
@@ -21,26 +28,36 @@ import (
 // Something like `RendererConfig` struct for `Renderer` struct in ui/renderer package
 func defaultModelConfig(toggleDotFile, toggleFooter, firstUse bool,
 	firstFilePanelDirs []string, zClient *zoxidelib.Client) *model {
+	hasTrash := common.InitTrash()
+	// The trash browser reads the XDG trash, which is only used on Linux
+	trashSupported := hasTrash && runtime.GOOS == utils.OsLinux
 	return &model{
 		filePanelFocusIndex: 0,
 		focusPanel:          nonePanelFocus,
-		processBarModel:     processbar.New(),
-		sidebarModel:        sidebar.New(),
+		processBarModel:     processbar.DefaultModel(os.Stdout),
+		sidebarModel:        sidebar.New(trashSupported),
 		fileMetaData:        metadata.New(),
 		fileModel: fileModel{
-			filePanels:  filePanelSlice(firstFilePanelDirs),
-			filePreview: preview.New(),
-			width:       10,
-		},
-		helpMenu:       newHelpMenuModal(),
-		promptModal:    prompt.DefaultModel(prompt.PromptMinHeight, prompt.PromptMinWidth),
-		zoxideModal:    zoxideui.DefaultModel(zoxideui.ZoxideMinHeight, zoxideui.ZoxideMinWidth, zClient),
-		zClient:        zClient,
-		modelQuitState: notQuitting,
-		toggleDotFile:  toggleDotFile,
-		toggleFooter:   toggleFooter,
-		firstUse:       firstUse,
-		hasTrash:       common.InitTrash(),
+			filePanels:   filePanelSlice(firstFilePanelDirs),
+			filePreview:  preview.New(),
+			width:        10,
+			parentColumn: defaultFilePanel("", false),
+		},
+		helpMenu:           newHelpMenuModal(),
+		promptModal:        prompt.DefaultModel(prompt.PromptMinHeight, prompt.PromptMinWidth),
+		zoxideModal:        zoxideui.DefaultModel(zoxideui.ZoxideMinHeight, zoxideui.ZoxideMinWidth, zClient),
+		trashModal:         trashui.DefaultModel(trashSupported),
+		contentSearchModal: contentsearchui.DefaultModel(),
+		compressModal:      newCompressModal(variable.CompressChoice),
+		zClient:            zClient,
+		modelQuitState:     notQuitting,
+		toggleDotFile:      toggleDotFile,
+		toggleFooter:       toggleFooter,
+		firstUse:           firstUse,
+		hasTrash:           hasTrash,
+		journal:            journal.New(variable.JournalFile),
+		watcher:            newWatcher(),
+		flatBatches:        make(chan flatBatch),
 	}
 }
 
@@ -93,6 +110,16 @@ func getHelpMenuData() []helpMenuModalData { //nolint: funlen // This should be
 			description:    "Cancel typing",
 			hotkeyWorkType: globalType,
 		},
+		{
+			hotkey:         common.Hotkeys.NextInput,
+			description:    "Move to the next input",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.ToggleCase,
+			description:    "Change the case of the new names in pattern rename",
+			hotkeyWorkType: globalType,
+		},
 		{
 			hotkey:         common.Hotkeys.OpenHelpMenu,
 			description:    "Open help menu (hotkeylist)",
@@ -204,6 +231,16 @@ func getHelpMenuData() []helpMenuModalData { //nolint: funlen // This should be
 			description:    "Change between selection mode or normal mode",
 			hotkeyWorkType: globalType,
 		},
+		{
+			hotkey:         common.Hotkeys.ToggleTreeView,
+			description:    "Toggle the tree view of the file panel",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.ToggleFlatView,
+			description:    "Toggle the flat view of the file panel",
+			hotkeyWorkType: globalType,
+		},
 		{
 			hotkey:         common.Hotkeys.PinnedDirectory,
 			description:    "Pin or Unpin folder to sidebar (can be auto saved)",
@@ -237,6 +274,21 @@ func getHelpMenuData() []helpMenuModalData { //nolint: funlen // This should be
 			description:    "Paste clipboard items into the current file panel",
 			hotkeyWorkType: globalType,
 		},
+		{
+			hotkey:         common.Hotkeys.PasteAsSymlink,
+			description:    "Create symlinks to clipboard items in the current file panel",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.PasteAsRelativeSymlink,
+			description:    "Create relative symlinks to clipboard items in the current file panel",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.PasteAsHardlink,
+			description:    "Create hardlinks to clipboard items in the current file panel",
+			hotkeyWorkType: globalType,
+		},
 		{
 			hotkey:         common.Hotkeys.DeleteItems,
 			description:    "Delete selected items",
@@ -247,6 +299,36 @@ func getHelpMenuData() []helpMenuModalData { //nolint: funlen // This should be
 			description:    "Permanently delete selected items",
 			hotkeyWorkType: globalType,
 		},
+		{
+			hotkey:         common.Hotkeys.CopyToNextPanel,
+			description:    "Copy selected items to the next file panel",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.MoveToNextPanel,
+			description:    "Move selected items to the next file panel",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.Undo,
+			description:    "Undo the last file operation",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.Redo,
+			description:    "Redo the last undone file operation",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.BulkRename,
+			description:    "Rename the selected items in your editor",
+			hotkeyWorkType: selectType,
+		},
+		{
+			hotkey:         common.Hotkeys.PatternRename,
+			description:    "Rename the selected items by a pattern",
+			hotkeyWorkType: globalType,
+		},
 		{
 			hotkey:         common.Hotkeys.CopyPath,
 			description:    "Copy current file or directory path",
@@ -254,12 +336,12 @@ func getHelpMenuData() []helpMenuModalData { //nolint: funlen // This should be
 		},
 		{
 			hotkey:         common.Hotkeys.ExtractFile,
-			description:    "Extract compressed file",
+			description:    "Extract an archive, or the items selected inside it",
 			hotkeyWorkType: normalType,
 		},
 		{
 			hotkey:         common.Hotkeys.CompressFile,
-			description:    "Zip file or folder to .zip file",
+			description:    "Compress file or folder to an archive",
 			hotkeyWorkType: normalType,
 		},
 		{
@@ -272,6 +354,95 @@ func getHelpMenuData() []helpMenuModalData { //nolint: funlen // This should be
 			description:    "Open current directory with default editor",
 			hotkeyWorkType: normalType,
 		},
+		{
+			subTitle: "Process bar",
+		},
+		{
+			hotkey:         common.Hotkeys.CancelProcess,
+			description:    "Cancel the selected process",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.TogglePauseProcess,
+			description:    "Pause or resume the selected process",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.Confirm,
+			description:    "Show details of the selected process",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.RetryProcess,
+			description:    "Retry the unfinished items of the failed process in the details",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.MoveProcessUp,
+			description:    "Move the selected queued process up the queue",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.MoveProcessDown,
+			description:    "Move the selected queued process down the queue",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.PrioritizeProcess,
+			description:    "Start the selected queued process next",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.OpenProcessHistory,
+			description:    "Open the history of finished processes",
+			hotkeyWorkType: globalType,
+		},
+		{
+			subTitle: "Trash",
+		},
+		{
+			hotkey:         common.Hotkeys.OpenTrash,
+			description:    "Open the trash browser",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.Confirm,
+			description:    "Restore the selected trash item to its original location",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.PermanentlyDeleteItems,
+			description:    "Permanently delete the selected trash item",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.EmptyTrash,
+			description:    "Empty the trash",
+			hotkeyWorkType: globalType,
+		},
+		{
+			subTitle: "Content search",
+		},
+		{
+			hotkey:         common.Hotkeys.OpenContentSearch,
+			description:    "Search the content of the files under the directory",
+			hotkeyWorkType: globalType,
+		},
+		{
+			hotkey:         common.Hotkeys.ConfirmTyping,
+			description:    "Start the search, or open the selected match in the editor",
+			hotkeyWorkType: modalType,
+		},
+		{
+			hotkey:         common.Hotkeys.SelectContentSearchFile,
+			description:    "Select the file of the selected match in the file panel",
+			hotkeyWorkType: modalType,
+		},
+		{
+			hotkey:         common.Hotkeys.ToggleContentSearchRegex,
+			description:    "Switch between literal and regex search",
+			hotkeyWorkType: modalType,
+		},
 	}
 
 	return data
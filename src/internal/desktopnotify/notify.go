@@ -0,0 +1,147 @@
+package desktopnotify
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// Notifier tells the user that something happened, even when superfile is not in view
+type Notifier interface {
+	Notify(title string, body string) error
+}
+
+// Sequence is the way a Terminal notifies
+type Sequence int
+
+const (
+	// OSC 9, supported by iTerm2, WezTerm, Ghostty, kitty and Windows Terminal
+	OSC9 Sequence = iota
+	// OSC 777, supported by urxvt and foot
+	OSC777
+	// The terminal bell, supported everywhere. Only the fact that something happened is sent
+	Bell
+)
+
+// Terminal notifies with escape sequences written to the terminal, which shows them as
+// desktop notifications
+type Terminal struct {
+	w        io.Writer
+	sequence Sequence
+	// Wraps the sequences so that tmux passes them through to the outer terminal
+	tmux bool
+}
+
+func NewTerminal(w io.Writer, sequence Sequence) *Terminal {
+	return &Terminal{
+		w:        w,
+		sequence: sequence,
+		tmux:     os.Getenv("TMUX") != "",
+	}
+}
+
+func (t *Terminal) Notify(title string, body string) error {
+	title, body = sanitize(title), sanitize(body)
+	var seq string
+	switch t.sequence {
+	case OSC9:
+		seq = "\x1b]9;" + title + ": " + body + "\a"
+	case OSC777:
+		// Fields are separated by semicolons, and the body is the last one
+		seq = "\x1b]777;notify;" + strings.ReplaceAll(title, ";", ",") + ";" + body + "\a"
+	case Bell:
+		// tmux handles the bell itself, and flags the window it rang in
+		_, err := io.WriteString(t.w, "\a")
+		return err
+	}
+	if t.tmux {
+		seq = tmuxPassthrough(seq)
+	}
+	// Written at once, so that it is not mixed with the rendering of the UI
+	_, err := io.WriteString(t.w, seq)
+	return err
+}
+
+// tmuxPassthrough wraps the sequence in a DCS passthrough sequence of tmux, with the escape
+// characters doubled. tmux 3.3 and later only pass it through with `allow-passthrough on`
+func tmuxPassthrough(seq string) string {
+	return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+}
+
+// sanitize removes the control characters, which could end the sequence early
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// DetectSequence returns the sequence supported by the terminal superfile runs in, with the
+// bell as a fallback. Inside tmux, the outer terminal is unknown, so the bell is used too
+func DetectSequence() Sequence {
+	if os.Getenv("TMUX") != "" {
+		return Bell
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "ghostty":
+		return OSC9
+	}
+	if os.Getenv("WT_SESSION") != "" {
+		return OSC9
+	}
+	term := os.Getenv("TERM")
+	switch {
+	case term == "xterm-kitty", term == "xterm-ghostty":
+		return OSC9
+	case strings.HasPrefix(term, "rxvt"), strings.HasPrefix(term, "foot"):
+		return OSC777
+	}
+	return Bell
+}
+
+// Command notifies by running a command, like notify-send, with the title and the body
+// as its last two arguments
+type Command struct {
+	args []string
+}
+
+// NewCommand splits the command line on whitespace. It is not run through a shell
+func NewCommand(command string) *Command {
+	return &Command{
+		args: strings.Fields(command),
+	}
+}
+
+// Notify starts the command, without waiting for it to finish
+func (c *Command) Notify(title string, body string) error {
+	if len(c.args) == 0 {
+		return errors.New("empty notification command")
+	}
+	cmd := exec.Command(c.args[0], slices.Concat(c.args[1:], []string{title, body})...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Only reaps the process, as its result does not matter
+	go func() {
+		_ = cmd.Wait()
+	}()
+	return nil
+}
+
+// Multi notifies with all of its notifiers
+type Multi []Notifier
+
+func (m Multi) Notify(title string, body string) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(title, body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,90 @@
+package desktopnotify
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminal(t *testing.T) {
+	testdata := []struct {
+		name     string
+		sequence Sequence
+		tmux     bool
+		expected string
+	}{
+		{"OSC 9", OSC9, false, "\x1b]9;Copy finished: a.txt\a"},
+		{"OSC 777", OSC777, false, "\x1b]777;notify;Copy finished;a.txt\a"},
+		{"Bell", Bell, false, "\a"},
+		{"OSC 9 in tmux", OSC9, true, "\x1bPtmux;\x1b\x1b]9;Copy finished: a.txt\a\x1b\\"},
+		{"Bell in tmux", Bell, true, "\a"},
+	}
+	for _, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n := &Terminal{w: &buf, sequence: tt.sequence, tmux: tt.tmux}
+			require.NoError(t, n.Notify("Copy finished", "a.txt"))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	t.Run("Control characters are removed", func(t *testing.T) {
+		var buf bytes.Buffer
+		n := &Terminal{w: &buf, sequence: OSC777}
+		require.NoError(t, n.Notify("a;b", "new\nline\x1b\a.txt"))
+		assert.Equal(t, "\x1b]777;notify;a,b;newline.txt\a", buf.String())
+	})
+}
+
+func TestDetectSequence(t *testing.T) {
+	for _, env := range []string{"TMUX", "TERM_PROGRAM", "WT_SESSION", "TERM"} {
+		t.Setenv(env, "")
+	}
+	assert.Equal(t, Bell, DetectSequence())
+
+	t.Setenv("TERM", "foot")
+	assert.Equal(t, OSC777, DetectSequence())
+	t.Setenv("TERM_PROGRAM", "WezTerm")
+	assert.Equal(t, OSC9, DetectSequence())
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1234,0")
+	assert.Equal(t, Bell, DetectSequence(), "The outer terminal of tmux is unknown")
+}
+
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(string, string) error {
+	return errors.New("failed")
+}
+
+func TestMulti(t *testing.T) {
+	var buf bytes.Buffer
+	n := Multi{failingNotifier{}, &Terminal{w: &buf, sequence: Bell}}
+	require.Error(t, n.Notify("title", "body"))
+	assert.Equal(t, "\a", buf.String(), "Other notifiers are used after a failure")
+}
+
+func TestCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses a shell script")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "notify.sh")
+	out := filepath.Join(dir, "out")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > \""+out+"\"\n"), 0o755))
+
+	require.NoError(t, NewCommand(script+" --urgency low").Notify("Copy finished", "a.txt"))
+	assert.Eventually(t, func() bool {
+		data, err := os.ReadFile(out)
+		return err == nil && string(data) == "--urgency low Copy finished a.txt\n"
+	}, time.Second, 10*time.Millisecond)
+
+	require.Error(t, NewCommand("").Notify("title", "body"))
+	require.Error(t, NewCommand(filepath.Join(dir, "missing")).Notify("title", "body"))
+}
@@ -1,15 +1,24 @@
 package internal
 
 import (
+	"archive/tar"
 	"archive/zip"
+	"compress/gzip"
+	"errors"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+
+	"github.com/yorukot/superfile/src/internal/common"
 
 	"github.com/yorukot/superfile/src/internal/ui/processbar"
 	"github.com/yorukot/superfile/src/internal/utils"
@@ -89,14 +98,15 @@ func TestZipSources(t *testing.T) {
 			}
 
 			targetZip := filepath.Join(tempDir, "test.zip")
-			err = zipSources(sources, targetZip, &processBar)
+			err = compressSources(sources, targetZip,
+				compressOptions{format: archiveZip, level: defaultCompressLevel}, &processBar)
 
 			if tt.expectError {
-				require.Error(t, err, "zipSources should return error")
+				require.Error(t, err, "compressSources should return error")
 				return
 			}
 
-			require.NoError(t, err, "zipSources should not return error")
+			require.NoError(t, err, "compressSources should not return error")
 
 			zipReader, err := zip.OpenReader(targetZip)
 			require.NoError(t, err, "should be able to open ZIP file")
@@ -149,6 +159,129 @@ func TestZipSourcesInvalidTarget(t *testing.T) {
 	require.NoError(t, err, "should be able to create test file")
 
 	invalidTarget := "/invalid/path/test.zip"
-	err = zipSources([]string{testFile}, invalidTarget, &processBar)
-	require.Error(t, err, "zipSources should return error for invalid target")
+	err = compressSources([]string{testFile}, invalidTarget,
+		compressOptions{format: archiveZip, level: defaultCompressLevel}, &processBar)
+	require.Error(t, err, "compressSources should return error for invalid target")
+}
+
+func TestCompressSourcesTar(t *testing.T) {
+	processBar := processbar.New()
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+
+	tempDir := t.TempDir()
+	srcDir := filepath.Join(tempDir, "src")
+	utils.SetupDirectories(t, srcDir, filepath.Join(srcDir, "dir"))
+	utils.SetupFilesWithData(t, []byte("Content of file1"), filepath.Join(srcDir, "file1.txt"))
+	utils.SetupFilesWithData(t, []byte("Content of file2"), filepath.Join(srcDir, "dir", "file2.txt"))
+	require.NoError(t, os.Chmod(filepath.Join(srcDir, "file1.txt"), 0o751))
+	require.NoError(t, os.Symlink("file1.txt", filepath.Join(srcDir, "link")))
+
+	decompressors := map[archiveFormat]func(r io.Reader) (io.Reader, error){
+		archiveTar: func(r io.Reader) (io.Reader, error) { return r, nil },
+		archiveTarGz: func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		},
+		archiveTarXz: func(r io.Reader) (io.Reader, error) {
+			return xz.NewReader(r)
+		},
+		archiveTarZst: func(r io.Reader) (io.Reader, error) {
+			return zstd.NewReader(r)
+		},
+	}
+	for format, decompress := range decompressors {
+		t.Run(string(format), func(t *testing.T) {
+			target, err := getArchivePath(tempDir, "src", format)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(tempDir, "src"+format.ext()), target)
+			require.NoError(t, compressSources([]string{srcDir}, target,
+				compressOptions{format: format, level: maxCompressLevel}, &processBar))
+			assert.Equal(t, format, archiveFormatFromPath(target))
+
+			f, err := os.Open(target)
+			require.NoError(t, err)
+			defer f.Close()
+			r, err := decompress(f)
+			require.NoError(t, err)
+			tr := tar.NewReader(r)
+			found := make(map[string]*tar.Header)
+			contents := make(map[string]string)
+			for {
+				header, err := tr.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+				found[header.Name] = header
+				data, err := io.ReadAll(tr)
+				require.NoError(t, err)
+				contents[header.Name] = string(data)
+			}
+			require.Len(t, found, 5)
+			assert.Contains(t, found, "src/")
+			assert.Contains(t, found, "src/dir/")
+			assert.Equal(t, "Content of file2", contents["src/dir/file2.txt"])
+			require.Contains(t, found, "src/file1.txt")
+			assert.Equal(t, "Content of file1", contents["src/file1.txt"])
+			if runtime.GOOS != utils.OsWindows {
+				assert.Equal(t, int64(0o751), found["src/file1.txt"].Mode&0o777)
+			}
+			require.Contains(t, found, "src/link")
+			assert.Equal(t, byte(tar.TypeSymlink), found["src/link"].Typeflag)
+			assert.Equal(t, "file1.txt", found["src/link"].Linkname)
+		})
+	}
+
+	t.Run("Archive names are not reused", func(t *testing.T) {
+		utils.SetupFiles(t, filepath.Join(tempDir, "file.tar.gz"))
+		target, err := getArchivePath(tempDir, "file.txt", archiveTarGz)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(tempDir, "file(1).tar.gz"), target)
+	})
+}
+
+func TestCompressModal(t *testing.T) {
+	curTestDir := t.TempDir()
+	file1 := filepath.Join(curTestDir, "file1.txt")
+	utils.SetupFilesWithData(t, []byte("Content of file1"), file1)
+	choiceFile := filepath.Join(curTestDir, "choice.json")
+
+	m := defaultTestModel(curTestDir)
+	m.compressModal = newCompressModal(choiceFile)
+	assert.Equal(t, archiveZip, m.compressModal.getOptions().format)
+	assert.Equal(t, defaultCompressLevel, m.compressModal.level)
+
+	m.openCompressModal()
+	require.True(t, m.compressModal.open)
+	assert.Equal(t, []string{file1}, m.compressModal.items)
+	m.compressModalKey(common.Hotkeys.ListDown[0])
+	m.compressModalKey(common.Hotkeys.ListDown[0])
+	m.compressModalKey("3")
+	m.compressModalKey("0")
+	assert.Equal(t, compressOptions{format: archiveTarGz, level: 3}, m.compressModal.getOptions())
+
+	cmd := m.compressModalKey(common.Hotkeys.Confirm[0])
+	require.NotNil(t, cmd)
+	assert.False(t, m.compressModal.open)
+
+	// The choice is remembered
+	c := newCompressModal(choiceFile)
+	assert.Equal(t, compressOptions{format: archiveTarGz, level: 3}, c.getOptions())
+
+	t.Run("Encrypted zips ask for a password", func(t *testing.T) {
+		m.compressModal.cursor = 0
+		m.openCompressModal()
+		m.compressModalKey(common.Hotkeys.NextInput[0])
+		assert.True(t, m.compressModal.encrypt)
+		assert.Nil(t, m.compressModalKey(common.Hotkeys.Confirm[0]))
+		assert.False(t, m.compressModal.open)
+		assert.False(t, m.compressModal.encrypt)
+		require.True(t, m.passwordModal.open)
+		require.NotNil(t, m.passwordModal.compress)
+		assert.Equal(t, []string{file1}, m.passwordModal.compress.items)
+
+		m.passwordModal.textInput.SetValue("hunter2")
+		require.NotNil(t, m.passwordModalKey(common.Hotkeys.ConfirmTyping[0]))
+		assert.False(t, m.passwordModal.open)
+	})
 }
@@ -0,0 +1,255 @@
+package internal
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/archivefs"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/utils"
+	"github.com/yorukot/superfile/src/internal/zipcrypto"
+)
+
+// Members of the test archives. Symlinks have a target instead of content
+type extractTestMember struct {
+	name    string
+	content string
+	link    string
+}
+
+func writeExtractTestTarGz(t *testing.T, archive string, members []extractTestMember) {
+	t.Helper()
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	w := tar.NewWriter(gz)
+	for _, m := range members {
+		header := &tar.Header{Name: m.name, Mode: 0o644, Size: int64(len(m.content)), Typeflag: tar.TypeReg}
+		if m.link != "" {
+			header.Typeflag, header.Linkname, header.Size = tar.TypeSymlink, m.link, 0
+		}
+		require.NoError(t, w.WriteHeader(header))
+		_, err = w.Write([]byte(m.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, gz.Close())
+}
+
+func writeExtractTestZip(t *testing.T, archive string, members []extractTestMember) {
+	t.Helper()
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for _, m := range members {
+		fw, err := w.Create(m.name)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(m.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+}
+
+func assertFileContent(t *testing.T, file string, expected string) {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(data), file)
+}
+
+func TestExtractArchive(t *testing.T) {
+	processBar := processbar.New()
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+
+	t.Run("Members never escape the destination", func(t *testing.T) {
+		members := []extractTestMember{
+			{name: "top.txt", content: "top"},
+			{name: "../evil.txt", content: "evil"},
+			{name: "dir/../../evil2.txt", content: "evil"},
+			{name: "/abs.txt", content: "abs"},
+			{name: "link", link: "../outside"},
+			{name: "link/pwn.txt", content: "pwn"},
+		}
+		for _, archiveName := range []string{"slip.tar.gz", "slip.zip"} {
+			t.Run(archiveName, func(t *testing.T) {
+				curTestDir := t.TempDir()
+				outside := filepath.Join(curTestDir, "outside")
+				dest := filepath.Join(curTestDir, "dest")
+				utils.SetupDirectories(t, outside)
+				archive := filepath.Join(curTestDir, archiveName)
+				if archiveName == "slip.zip" {
+					writeExtractTestZip(t, archive, members[:4])
+				} else {
+					writeExtractTestTarGz(t, archive, members)
+				}
+
+				require.NoError(t, extractCompressFile(archive, extractOptions{dest: dest, policy: conflictSkip},
+					&processBar))
+				assertFileContent(t, filepath.Join(dest, "top.txt"), "top")
+				// Absolute names are extracted inside the destination
+				assertFileContent(t, filepath.Join(dest, "abs.txt"), "abs")
+				assert.NoFileExists(t, filepath.Join(curTestDir, "evil.txt"))
+				assert.NoFileExists(t, filepath.Join(curTestDir, "evil2.txt"))
+				assert.NoFileExists(t, filepath.Join(outside, "pwn.txt"))
+			})
+		}
+	})
+
+	t.Run("Symlinks in the destination are not written through", func(t *testing.T) {
+		curTestDir := t.TempDir()
+		outside := filepath.Join(curTestDir, "outside")
+		dest := filepath.Join(curTestDir, "dest")
+		utils.SetupDirectories(t, outside, dest)
+		require.NoError(t, os.Symlink(outside, filepath.Join(dest, "link")))
+
+		require.Error(t, checkInsideDir(dest, filepath.Join(dest, "link", "pwn.txt")))
+		require.Error(t, checkInsideDir(dest, filepath.Join(dest, "..", "pwn.txt")))
+		require.Error(t, checkInsideDir(dest, dest))
+		require.NoError(t, checkInsideDir(dest, filepath.Join(dest, "link")))
+		require.NoError(t, checkInsideDir(dest, filepath.Join(dest, "file.txt")))
+
+		archive := filepath.Join(curTestDir, "test.tar.gz")
+		writeExtractTestTarGz(t, archive, []extractTestMember{{name: "link/pwn.txt", content: "pwn"}})
+		for _, policy := range []pasteConflictPolicy{conflictSkip, conflictOverwrite, conflictKeepBoth} {
+			require.NoError(t, extractCompressFile(archive, extractOptions{dest: dest, policy: policy},
+				&processBar))
+			assert.NoFileExists(t, filepath.Join(outside, "pwn.txt"), policy.String())
+		}
+	})
+
+	t.Run("Existing files follow the policy", func(t *testing.T) {
+		testdata := []struct {
+			policy   pasteConflictPolicy
+			expected map[string]string
+		}{
+			{policy: conflictSkip, expected: map[string]string{"a.txt": "old", "dir/b.txt": "old"}},
+			{policy: conflictOverwrite, expected: map[string]string{"a.txt": "new", "dir/b.txt": "new"}},
+			{policy: conflictKeepBoth, expected: map[string]string{
+				"a.txt": "old", "a(1).txt": "new", "dir/b.txt": "old", "dir(1)/b.txt": "new",
+			}},
+		}
+		for _, tt := range testdata {
+			t.Run(tt.policy.String(), func(t *testing.T) {
+				curTestDir := t.TempDir()
+				dest := filepath.Join(curTestDir, "dest")
+				utils.SetupDirectories(t, filepath.Join(dest, "dir"))
+				utils.SetupFilesWithData(t, []byte("old"), filepath.Join(dest, "a.txt"),
+					filepath.Join(dest, "dir", "b.txt"))
+				archive := filepath.Join(curTestDir, "test.zip")
+				writeExtractTestZip(t, archive, []extractTestMember{
+					{name: "a.txt", content: "new"}, {name: "dir/b.txt", content: "new"},
+				})
+
+				require.NoError(t, extractCompressFile(archive, extractOptions{dest: dest, policy: tt.policy},
+					&processBar))
+				for file, content := range tt.expected {
+					assertFileContent(t, filepath.Join(dest, file), content)
+				}
+			})
+		}
+	})
+
+	t.Run("Existing files follow the policy for formats extracted by xtractr", func(t *testing.T) {
+		testdata := []struct {
+			policy   pasteConflictPolicy
+			expected map[string]string
+		}{
+			{policy: conflictSkip, expected: map[string]string{"data.txt": "old"}},
+			{policy: conflictOverwrite, expected: map[string]string{"data.txt": "new"}},
+			{policy: conflictKeepBoth, expected: map[string]string{"data.txt": "old", "data(1).txt": "new"}},
+		}
+		for _, tt := range testdata {
+			t.Run(tt.policy.String(), func(t *testing.T) {
+				curTestDir := t.TempDir()
+				dest := filepath.Join(curTestDir, "dest")
+				utils.SetupDirectories(t, dest)
+				utils.SetupFilesWithData(t, []byte("old"), filepath.Join(dest, "data.txt"))
+				// A plain gzip file, which is not an archive browsed by archivefs
+				archive := filepath.Join(curTestDir, "data.txt.gz")
+				f, err := os.Create(archive)
+				require.NoError(t, err)
+				gz := gzip.NewWriter(f)
+				_, err = gz.Write([]byte("new"))
+				require.NoError(t, err)
+				require.NoError(t, gz.Close())
+				require.NoError(t, f.Close())
+
+				require.NoError(t, extractCompressFile(archive, extractOptions{dest: dest, policy: tt.policy},
+					&processBar))
+				for file, content := range tt.expected {
+					assertFileContent(t, filepath.Join(dest, file), content)
+				}
+				entries, err := os.ReadDir(dest)
+				require.NoError(t, err)
+				assert.Len(t, entries, len(tt.expected), "The temporary directory is removed")
+			})
+		}
+	})
+
+	t.Run("Only the chosen members are extracted", func(t *testing.T) {
+		curTestDir := t.TempDir()
+		dest := filepath.Join(curTestDir, "new", "dest")
+		archive := filepath.Join(curTestDir, "test.tar.gz")
+		writeExtractTestTarGz(t, archive, []extractTestMember{
+			{name: "top.txt", content: "top"},
+			{name: "dir/a.txt", content: "a"},
+			{name: "dir/sub/b.txt", content: "b"},
+			{name: "other/c.txt", content: "c"},
+		})
+		root := archivefs.Root(archive)
+
+		require.NoError(t, extractCompressFile(archive, extractOptions{
+			members: []string{root + "dir/sub", root + "top.txt"}, dest: dest, policy: conflictSkip,
+		}, &processBar))
+		assertFileContent(t, filepath.Join(dest, "top.txt"), "top")
+		assertFileContent(t, filepath.Join(dest, "sub", "b.txt"), "b")
+		assert.NoFileExists(t, filepath.Join(dest, "a.txt"))
+		assert.NoDirExists(t, filepath.Join(dest, "other"))
+	})
+
+	t.Run("Encrypted zips need the password", func(t *testing.T) {
+		curTestDir := t.TempDir()
+		srcDir := filepath.Join(curTestDir, "src")
+		dest := filepath.Join(curTestDir, "dest")
+		utils.SetupDirectories(t, filepath.Join(srcDir, "dir"))
+		utils.SetupFilesWithData(t, []byte("secret"), filepath.Join(srcDir, "dir", "secret.txt"))
+		archive := filepath.Join(curTestDir, "secret.zip")
+		require.NoError(t, compressSources([]string{srcDir}, archive, compressOptions{
+			format: archiveZip, level: defaultCompressLevel, password: "hunter2",
+		}, &processBar))
+
+		opts := extractOptions{dest: dest, policy: conflictSkip}
+		require.ErrorIs(t, extractCompressFile(archive, opts, &processBar), zipcrypto.ErrPasswordRequired)
+		opts.password = "wrong"
+		require.ErrorIs(t, extractCompressFile(archive, opts, &processBar), zipcrypto.ErrWrongPassword)
+		// Nothing is left behind by the failed attempts
+		assert.NoDirExists(t, dest)
+
+		opts.password = "hunter2"
+		require.NoError(t, extractCompressFile(archive, opts, &processBar))
+		assertFileContent(t, filepath.Join(dest, "src", "dir", "secret.txt"), "secret")
+	})
+}
+
+func TestGetXtractrPasswordError(t *testing.T) {
+	corrupt := errors.New("sevenzip: checksum error")
+	require.ErrorIs(t, getXtractrPasswordError("a.7z", "", errors.New("used password 1 of 1: aes7z: no password set")),
+		zipcrypto.ErrPasswordRequired)
+	require.ErrorIs(t, getXtractrPasswordError("a.rar", "wrong", errors.New("rardecode: incorrect password")),
+		zipcrypto.ErrWrongPassword)
+	require.ErrorIs(t, getXtractrPasswordError("a.7z", "wrong", corrupt), corrupt,
+		"Checksum errors can come from a corrupted archive")
+	assert.False(t, isArchivePasswordError(getXtractrPasswordError("a.rar", "",
+		errors.New("rardecode: bad file checksum"))))
+}
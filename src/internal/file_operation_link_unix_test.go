@@ -0,0 +1,128 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/journal"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func TestExecuteLinkOperation(t *testing.T) {
+	processBar := processbar.New()
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+
+	curTestDir := t.TempDir()
+	srcDir := filepath.Join(curTestDir, "src")
+	dstDir := filepath.Join(curTestDir, "dst")
+	subDir := filepath.Join(srcDir, "sub")
+	utils.SetupDirectories(t, srcDir, dstDir, subDir)
+	file1 := filepath.Join(srcDir, "file1.txt")
+	utils.SetupFilesWithData(t, []byte("data"), file1)
+
+	t.Run("Symlinks", func(t *testing.T) {
+		linkDir := filepath.Join(curTestDir, "symlinks")
+		utils.SetupDirectories(t, linkDir)
+		state := executeLinkOperation(&processBar, linkDir, []string{file1, subDir}, symlinkAbsolute, nil, nil)
+		assert.Equal(t, processbar.Successful, state)
+		target, err := os.Readlink(filepath.Join(linkDir, "file1.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, file1, target)
+		info, err := os.Stat(filepath.Join(linkDir, "sub"))
+		require.NoError(t, err)
+		assert.True(t, info.IsDir(), "Directories can be symlinked")
+
+		state = executeLinkOperation(&processBar, linkDir, []string{file1}, symlinkRelative, nil, nil)
+		assert.Equal(t, processbar.Successful, state)
+		target, err = os.Readlink(filepath.Join(linkDir, "file1(1).txt"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join("..", "src", "file1.txt"), target)
+		data, err := os.ReadFile(filepath.Join(linkDir, "file1(1).txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "data", string(data))
+	})
+
+	t.Run("Hardlinks", func(t *testing.T) {
+		state := executeLinkOperation(&processBar, dstDir, []string{file1}, hardlink, nil, nil)
+		assert.Equal(t, processbar.Successful, state)
+		srcInfo, err := os.Stat(file1)
+		require.NoError(t, err)
+		linkInfo, err := os.Lstat(filepath.Join(dstDir, "file1.txt"))
+		require.NoError(t, err)
+		assert.True(t, os.SameFile(srcInfo, linkInfo))
+
+		err = validateLinkOperation(dstDir, []string{subDir}, hardlink)
+		require.ErrorContains(t, err, "directories cannot be hardlinked")
+		require.NoError(t, validateLinkOperation(dstDir, []string{subDir}, symlinkAbsolute))
+	})
+
+	t.Run("Conflicts are resolved as for pasting", func(t *testing.T) {
+		conflictDir := filepath.Join(curTestDir, "conflicts")
+		existing := filepath.Join(conflictDir, "file1.txt", "inner.txt")
+		utils.SetupDirectories(t, filepath.Dir(existing))
+		utils.SetupFiles(t, existing)
+
+		state := executeLinkOperation(&processBar, conflictDir, []string{file1}, symlinkAbsolute,
+			map[string]pasteConflictPolicy{file1: conflictSkip}, nil)
+		assert.Equal(t, processbar.Successful, state)
+		assert.FileExists(t, existing)
+
+		state = executeLinkOperation(&processBar, conflictDir, []string{file1}, symlinkAbsolute,
+			map[string]pasteConflictPolicy{file1: conflictOverwrite}, nil)
+		assert.Equal(t, processbar.Failed, state)
+		assert.FileExists(t, existing, "Directories are not replaced by links")
+
+		existingFile := filepath.Join(conflictDir, "data.txt")
+		utils.SetupFiles(t, existingFile)
+		dataFile := filepath.Join(srcDir, "data.txt")
+		utils.SetupFilesWithData(t, []byte("data"), dataFile)
+		state = executeLinkOperation(&processBar, conflictDir, []string{dataFile}, symlinkAbsolute,
+			map[string]pasteConflictPolicy{dataFile: conflictOverwrite}, nil)
+		assert.Equal(t, processbar.Successful, state)
+		target, err := os.Readlink(existingFile)
+		require.NoError(t, err)
+		assert.Equal(t, dataFile, target, "The link replaces the file")
+	})
+
+	t.Run("Links are undone and redone", func(t *testing.T) {
+		linkDir := filepath.Join(curTestDir, "journal")
+		utils.SetupDirectories(t, linkDir)
+		j := journal.New("")
+		link := filepath.Join(linkDir, "file1.txt")
+		executeLinkOperation(&processBar, linkDir, []string{file1}, symlinkRelative, nil, j)
+
+		require.NoError(t, switchJournalEntry(j, &processBar, true))
+		_, err := os.Lstat(link)
+		require.ErrorIs(t, err, os.ErrNotExist)
+		assert.FileExists(t, file1)
+
+		require.NoError(t, switchJournalEntry(j, &processBar, false))
+		target, err := os.Readlink(link)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join("..", "src", "file1.txt"), target)
+	})
+}
+
+func TestSymlinkElements(t *testing.T) {
+	curTestDir := t.TempDir()
+	file1 := filepath.Join(curTestDir, "file1.txt")
+	utils.SetupFiles(t, file1)
+	require.NoError(t, os.Symlink("file1.txt", filepath.Join(curTestDir, "link")))
+
+	elements := returnDirElement(curTestDir, false, sortOptionsModelData{options: []string{string(sortingName)}})
+	require.Len(t, elements, 2)
+	assert.Empty(t, elements[0].symlinkTarget)
+	assert.Equal(t, "link", elements[1].name)
+	assert.Equal(t, "file1.txt", elements[1].symlinkTarget)
+	assert.Contains(t, common.PrettierSymlinkName("link", "file1.txt", 40, false, false, common.FilePanelBGColor),
+		"link -> file1.txt")
+}
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func TestArchiveCopy(t *testing.T) {
+	processBar := processbar.New()
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+
+	oldArchiveCopy := common.Config.ArchiveCopy
+	t.Cleanup(func() {
+		common.Config.ArchiveCopy = oldArchiveCopy
+	})
+
+	oldTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	setupSrc := func(t *testing.T) string {
+		srcDir := filepath.Join(t.TempDir(), "src")
+		subDir := filepath.Join(srcDir, "sub")
+		file := filepath.Join(subDir, "file.txt")
+		utils.SetupDirectories(t, subDir)
+		utils.SetupFilesWithData(t, []byte("data"), file)
+		require.NoError(t, os.Chmod(file, 0o604))
+		for _, path := range []string{file, subDir, srcDir} {
+			require.NoError(t, os.Chtimes(path, oldTime, oldTime))
+		}
+		return srcDir
+	}
+
+	modTime := func(t *testing.T, path string) time.Time {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		return info.ModTime()
+	}
+
+	testdata := []struct {
+		name        string
+		archiveCopy bool
+		cut         bool
+	}{
+		{name: "Archive copy", archiveCopy: true, cut: false},
+		{name: "Archive move across devices", archiveCopy: true, cut: true},
+		{name: "Plain copy", archiveCopy: false, cut: false},
+	}
+
+	for _, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			common.Config.ArchiveCopy = tt.archiveCopy
+			srcDir := setupSrc(t)
+			dstDir := filepath.Join(t.TempDir(), "dst")
+			p, err := processBar.SendAddProcessMsg("copy", 1, true)
+			require.NoError(t, err)
+
+			// Force the copy path, as isSamePartition would allow a rename for a move
+			require.NoError(t, pasteTree(srcDir, dstDir, false, conflictKeepBoth,
+				newPasteContext(&p, &processBar, tt.cut)))
+
+			dstFile := filepath.Join(dstDir, "sub", "file.txt")
+			require.FileExists(t, dstFile)
+			for _, path := range []string{dstFile, filepath.Join(dstDir, "sub"), dstDir} {
+				if tt.archiveCopy {
+					assert.True(t, oldTime.Equal(modTime(t, path)), "Modification time of %s should be preserved", path)
+				} else {
+					assert.False(t, oldTime.Equal(modTime(t, path)), "Plain copy should not preserve times of %s", path)
+				}
+			}
+			info, err := os.Stat(dstFile)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o604), info.Mode().Perm())
+			if tt.cut {
+				assert.NoFileExists(t, filepath.Join(srcDir, "sub", "file.txt"))
+			}
+		})
+	}
+}
+
+func TestCopyDirPreservesMetadata(t *testing.T) {
+	oldArchiveCopy := common.Config.ArchiveCopy
+	common.Config.ArchiveCopy = true
+	t.Cleanup(func() {
+		common.Config.ArchiveCopy = oldArchiveCopy
+	})
+
+	curTestDir := t.TempDir()
+	srcDir := filepath.Join(curTestDir, "src")
+	dstDir := filepath.Join(curTestDir, "dst")
+	file := filepath.Join(srcDir, "file.txt")
+	utils.SetupDirectories(t, srcDir)
+	utils.SetupFiles(t, file)
+	oldTime := time.Date(2021, 5, 6, 7, 8, 9, 0, time.UTC)
+	require.NoError(t, os.Chtimes(file, oldTime, oldTime))
+	require.NoError(t, os.Chtimes(srcDir, oldTime, oldTime))
+
+	srcInfo, err := os.Stat(srcDir)
+	require.NoError(t, err)
+	require.NoError(t, copyDir(srcDir, dstDir, srcInfo))
+
+	for _, path := range []string{dstDir, filepath.Join(dstDir, "file.txt")} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.True(t, oldTime.Equal(info.ModTime()), "Modification time of %s should be preserved", path)
+	}
+}
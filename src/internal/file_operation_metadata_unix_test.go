@@ -0,0 +1,29 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func TestCopyXattrs(t *testing.T) {
+	curTestDir := t.TempDir()
+	src := filepath.Join(curTestDir, "src.txt")
+	dst := filepath.Join(curTestDir, "dst.txt")
+	utils.SetupFiles(t, src, dst)
+
+	if err := unix.Lsetxattr(src, "user.superfile", []byte("value"), 0); err != nil {
+		t.Skipf("Extended attributes are not supported here : %v", err)
+	}
+	require.NoError(t, copyXattrs(src, dst))
+	value, err := getXattr(dst, "user.superfile")
+	require.NoError(t, err)
+	assert.Equal(t, "value", string(value))
+}
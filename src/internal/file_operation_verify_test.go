@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func TestShouldVerifyCopy(t *testing.T) {
+	testdata := []struct {
+		name     string
+		config   string
+		dst      string
+		expected bool
+	}{
+		{"Never", common.VerifyCopyNever, "/media/usb", false},
+		{"Always", common.VerifyCopyAlways, "/home/user", true},
+		{"External disk", common.VerifyCopyExternal, "/media/usb", true},
+		{"Internal disk", common.VerifyCopyExternal, "/home/user", false},
+	}
+	orig := common.Config.VerifyCopy
+	t.Cleanup(func() { common.Config.VerifyCopy = orig })
+	for _, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			common.Config.VerifyCopy = tt.config
+			if tt.config == common.VerifyCopyExternal && tt.expected && !isExternalDiskPath(tt.dst) {
+				t.Skip("External disks are not detected on this platform")
+			}
+			assert.Equal(t, tt.expected, shouldVerifyCopy(tt.dst))
+		})
+	}
+}
+
+func TestVerifyPaste(t *testing.T) {
+	processBar := processbar.New()
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+
+	setup := func(t *testing.T) (string, string) {
+		curTestDir := t.TempDir()
+		srcDir := filepath.Join(curTestDir, "src")
+		dstDir := filepath.Join(curTestDir, "dst")
+		utils.SetupDirectories(t, srcDir, filepath.Join(srcDir, "dir"))
+		utils.SetupFilesWithData(t, []byte("first file"), filepath.Join(srcDir, "file1.txt"))
+		utils.SetupFilesWithData(t, []byte("second file"), filepath.Join(srcDir, "dir", "file2.txt"))
+		return srcDir, dstDir
+	}
+	// Moves across disks are the ones that copy, and remove the source
+	pasteAcrossDisks := func(t *testing.T, srcDir, dstDir string) *pasteContext {
+		p, err := processBar.SendAddProcessMsg("move", 2, true)
+		require.NoError(t, err)
+		ctx := newPasteContext(&p, &processBar, true)
+		ctx.verify = true
+		require.NoError(t, pasteTree(srcDir, dstDir, false, conflictKeepBoth, ctx))
+		ctx.cutDirs = append(ctx.cutDirs, srcDir)
+		require.Len(t, ctx.copied, 2)
+		// Nothing is removed before the verification
+		assert.FileExists(t, filepath.Join(srcDir, "file1.txt"))
+		assert.FileExists(t, filepath.Join(srcDir, "dir", "file2.txt"))
+		return ctx
+	}
+
+	t.Run("Sources are removed after verification", func(t *testing.T) {
+		srcDir, dstDir := setup(t)
+		ctx := pasteAcrossDisks(t, srcDir, dstDir)
+
+		require.NoError(t, verifyPaste(ctx))
+		assert.True(t, ctx.p.Verifying)
+		assert.Equal(t, 2, ctx.p.Done)
+		assert.Equal(t, ctx.p.TotalBytes, ctx.p.DoneBytes)
+		assert.Empty(t, ctx.p.Errors)
+		assert.NoDirExists(t, srcDir)
+		assert.FileExists(t, filepath.Join(dstDir, "dir", "file2.txt"))
+	})
+
+	t.Run("Mismatching files are reported and kept", func(t *testing.T) {
+		srcDir, dstDir := setup(t)
+		ctx := pasteAcrossDisks(t, srcDir, dstDir)
+		corrupted := filepath.Join(dstDir, "dir", "file2.txt")
+		require.NoError(t, os.WriteFile(corrupted, []byte("second fild"), 0o644))
+
+		err := verifyPaste(ctx)
+		require.ErrorIs(t, err, errChecksumMismatch)
+		require.Len(t, ctx.p.Errors, 1)
+		assert.Contains(t, ctx.p.Errors[0], corrupted)
+		assert.NoFileExists(t, filepath.Join(srcDir, "file1.txt"))
+		assert.FileExists(t, filepath.Join(srcDir, "dir", "file2.txt"))
+		// Only the items with mismatching files are pasted again when retried
+		assert.Equal(t, []string{filepath.Join(srcDir, "file1.txt")},
+			ctx.verifiedItems([]string{filepath.Join(srcDir, "file1.txt"), filepath.Join(srcDir, "dir")}))
+	})
+
+	t.Run("Paste with verification", func(t *testing.T) {
+		orig := common.Config.VerifyCopy
+		common.Config.VerifyCopy = common.VerifyCopyAlways
+		t.Cleanup(func() { common.Config.VerifyCopy = orig })
+		srcDir, dstDir := setup(t)
+		utils.SetupDirectories(t, dstDir)
+
+		state := executePasteOperation(&processBar, dstDir, []string{srcDir}, false, nil, "", nil)
+		assert.Equal(t, processbar.Successful, state)
+		assert.FileExists(t, filepath.Join(dstDir, "src", "dir", "file2.txt"))
+		assert.FileExists(t, filepath.Join(srcDir, "dir", "file2.txt"))
+	})
+}
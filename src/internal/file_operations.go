@@ -7,7 +7,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/yorukot/superfile/src/internal/ui/processbar"
 	"github.com/yorukot/superfile/src/internal/utils"
@@ -21,26 +23,55 @@ import (
 
 // isSamePartition checks if two paths are on the same filesystem partition
 func isSamePartition(path1, path2 string) (bool, error) {
-	// Get the absolute path to handle relative paths
-	absPath1, err := filepath.Abs(path1)
+	partition1, err := getPartition(path1)
 	if err != nil {
 		return false, fmt.Errorf("failed to get absolute path of the first path: %w", err)
 	}
 
-	absPath2, err := filepath.Abs(path2)
+	partition2, err := getPartition(path2)
 	if err != nil {
 		return false, fmt.Errorf("failed to get absolute path of the second path: %w", err)
 	}
+	return partition1 == partition2, nil
+}
+
+// getPartition returns the partition the path is on, as compared by isSamePartition
+func getPartition(path string) (string, error) {
+	// Get the absolute path to handle relative paths
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
 
 	if runtime.GOOS == utils.OsWindows {
 		// On Windows, we can check if both paths are on the same drive (same letter)
-		drive1 := getDriveLetter(absPath1)
-		drive2 := getDriveLetter(absPath2)
-		return drive1 == drive2, nil
+		return getDriveLetter(absPath), nil
 	}
 
 	// For Unix-like systems, we use the same path to check the root partition
-	return filepath.VolumeName(absPath1) == filepath.VolumeName(absPath2), nil
+	return filepath.VolumeName(absPath), nil
+}
+
+// getProcessDevice returns the device that the processes writing to path are queued for.
+// A path that does not exist yet is on the device of its nearest existing parent. The
+// partition is used on the platforms that do not report devices
+func getProcessDevice(path string) string {
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if info, err := os.Stat(dir); err == nil {
+			if id, _, ok := getFileID(info); ok {
+				return "dev:" + strconv.FormatUint(id.dev, 10)
+			}
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	partition, err := getPartition(path)
+	if err != nil {
+		slog.Error("Cannot get the partition of the process destination", "path", path, "error", err)
+	}
+	return partition
 }
 
 // getDriveLetter extracts the drive letter from a Windows path
@@ -82,7 +113,7 @@ func moveElement(src, dst string) error {
 
 // copyElement handles copying of both files and directories
 func copyElement(src, dst string) error {
-	srcInfo, err := os.Stat(src)
+	srcInfo, err := os.Lstat(src)
 	if err != nil {
 		return fmt.Errorf("failed to stat source: %w", err)
 	}
@@ -90,11 +121,13 @@ func copyElement(src, dst string) error {
 	if srcInfo.IsDir() {
 		return copyDir(src, dst, srcInfo)
 	}
-	return copyFile(src, dst, srcInfo)
+	_, err = copyEntry(src, dst, srcInfo, nil, nil, nil)
+	return err
 }
 
 // copyDir recursively copies a directory
 func copyDir(src, dst string, srcInfo os.FileInfo) error {
+	atime := getSrcAccessTime(src, srcInfo)
 	err := os.MkdirAll(dst, srcInfo.Mode())
 	if err != nil {
 		return fmt.Errorf("failed to create destination directory: %w", err)
@@ -105,6 +138,7 @@ func copyDir(src, dst string, srcInfo os.FileInfo) error {
 		return fmt.Errorf("failed to read source directory: %w", err)
 	}
 
+	hardlinks := make(map[fileID]string)
 	for _, entry := range entries {
 		srcPath := filepath.Join(src, entry.Name())
 		dstPath := filepath.Join(dst, entry.Name())
@@ -117,17 +151,22 @@ func copyDir(src, dst string, srcInfo os.FileInfo) error {
 		if entryInfo.IsDir() {
 			err = copyDir(srcPath, dstPath, entryInfo)
 		} else {
-			err = copyFile(srcPath, dstPath, entryInfo)
+			_, err = copyEntry(srcPath, dstPath, entryInfo, hardlinks, nil, nil)
 		}
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+	return preserveMetadata(src, dst, srcInfo, atime)
 }
 
-// copyFile copies a single file
-func copyFile(src, dst string, srcInfo os.FileInfo) error {
+// copyFile copies a single file. If p is not nil, the copy checks in with it
+// between chunks so that it can be paused or cancelled, and the written bytes are
+// added to its progress. A partly written destination is removed if the copy does not finish.
+// Holes in sparse files are kept. Metadata is preserved too, if archive copy is enabled
+func copyFile(src, dst string, srcInfo os.FileInfo, p *processbar.Process,
+	processBarModel *processbar.Model) error {
+	atime := getSrcAccessTime(src, srcInfo)
 	srcFile, err := os.Open(src)
 	if err != nil {
 		return fmt.Errorf("failed to open source file: %w", err)
@@ -140,10 +179,61 @@ func copyFile(src, dst string, srcInfo os.FileInfo) error {
 	}
 	defer dstFile.Close()
 
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
+	var w io.Writer = dstFile
+	sparse := isSparse(srcInfo)
+	if sparse {
+		w = &sparseWriter{f: dstFile}
+	}
+	if p != nil {
+		w = &progressWriter{w: w, p: p, processBarModel: processBarModel}
+	}
+	_, err = io.Copy(w, &checkpointReader{r: srcFile, p: p})
+	if err == nil && sparse {
+		// Holes at the end are not written, the size has to be set explicitly
+		err = dstFile.Truncate(srcInfo.Size())
+	}
+	if err != nil {
+		dstFile.Close()
+		if removeErr := os.Remove(dst); removeErr != nil {
+			slog.Error("Failed to remove partly written file", "path", dst, "error", removeErr)
+		}
 		return fmt.Errorf("failed to copy file contents: %w", err)
 	}
-	return nil
+	if err := dstFile.Close(); err != nil {
+		return fmt.Errorf("failed to close destination file: %w", err)
+	}
+	return preserveMetadata(src, dst, srcInfo, atime)
+}
+
+// checkpointReader calls p.Checkpoint() before every read, so that long copies
+// can be paused or cancelled in between chunks
+type checkpointReader struct {
+	r io.Reader
+	p *processbar.Process
+}
+
+func (c *checkpointReader) Read(b []byte) (int, error) {
+	if err := c.p.Checkpoint(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(b)
+}
+
+// progressWriter adds the written bytes to the process, and sends throttled updates
+// to the process bar
+type progressWriter struct {
+	w               io.Writer
+	p               *processbar.Process
+	processBarModel *processbar.Model
+}
+
+func (pw *progressWriter) Write(b []byte) (int, error) {
+	n, err := pw.w.Write(b)
+	pw.p.AddDoneBytes(int64(n))
+	if pw.processBarModel != nil {
+		pw.processBarModel.TrySendingThrottledUpdateProcessMsg(pw.p)
+	}
+	return n, err
 }
 
 func moveToTrash(src string) error {
@@ -166,25 +256,99 @@ func moveToTrash(src string) error {
 	return err
 }
 
-// pasteDir handles directory copying with progress tracking
-func pasteDir(src, dst string, p *processbar.Process, cut bool, processBarModel *processbar.Model) error {
-	dst, err := renameIfDuplicate(dst)
+// getTrashPath returns the path that moveToTrash() would move src to.
+// Returns empty string if its not known, like on Windows
+func getTrashPath(src string) string {
+	switch runtime.GOOS {
+	case utils.OsDarwin:
+		return filepath.Join(variable.DarwinTrashDirectory, filepath.Base(src))
+	case utils.OsWindows:
+		return ""
+	default:
+		// Same naming scheme as github.com/rkoesters/xdg/trash
+		name := filepath.Base(src)
+		for i := 2; ; i++ {
+			trashPath := filepath.Join(variable.LinuxTrashDirectoryFiles, name)
+			if _, err := os.Stat(trashPath); os.IsNotExist(err) {
+				return trashPath
+			}
+			name = filepath.Base(src) + "." + strconv.Itoa(i)
+		}
+	}
+}
+
+// restoreFromTrash moves an item, that moveToTrash() moved to trashPath, back to dst
+func restoreFromTrash(trashPath string, dst string) error {
+	err := moveElement(trashPath, dst)
 	if err != nil {
 		return err
 	}
+	if runtime.GOOS == utils.OsDarwin {
+		return nil
+	}
+	// Remove the trashinfo too, otherwise the trash would list an item that isn't there
+	infoPath := filepath.Join(variable.LinuxTrashDirectoryInfo, filepath.Base(trashPath)+".trashinfo")
+	if err = os.Remove(infoPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove trash info: %w", err)
+	}
+	return nil
+}
+
+// pasteDir handles directory copying with progress tracking. policy decides what
+// happens to the items that already exist at the destination.
+// Returns the path the item was pasted to, or empty string if it was skipped
+func pasteDir(src, dst string, policy pasteConflictPolicy, ctx *pasteContext) (string, error) {
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return "", err
+	}
+	dst, proceed, err := resolvePasteConflict(srcInfo, dst, policy)
+	if err != nil {
+		return "", err
+	}
+	if !proceed {
+		skipPasteItem(src, ctx.p, ctx.processBarModel)
+		return "", nil
+	}
+	// Destination still existing after resolving the conflict means we are merging two directories
+	_, err = os.Lstat(dst)
+	merging := err == nil
 
 	// Check if we can do a fast move within the same partition
 	sameDev, err := isSamePartition(src, dst)
-	if err == nil && sameDev && cut {
+	if err == nil && sameDev && ctx.cut && !merging {
 		// For cut operations on same partition, try fast rename first
 		err = os.Rename(src, dst)
 		if err == nil {
-			return nil
+			return dst, nil
 		}
 		// If rename fails, fall back to manual copy
 	}
 
-	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+	if err = pasteTree(src, dst, sameDev, policy, ctx); err != nil {
+		return "", err
+	}
+
+	// Every pasted file was already removed from the source during a cut operation.
+	// Only the directories, and the files skipped due to conflicts, are left behind
+	if ctx.cut && ctx.verify {
+		ctx.cutDirs = append(ctx.cutDirs, src)
+	}
+	if ctx.cut {
+		err = removeEmptyDirs(src)
+		if err != nil {
+			return "", fmt.Errorf("failed to remove source after move: %w", err)
+		}
+	}
+
+	return dst, nil
+}
+
+// pasteTree pastes everything under src to dst. Directory metadata is applied once
+// their contents are pasted
+func pasteTree(src, dst string, sameDev bool, policy pasteConflictPolicy, ctx *pasteContext) error {
+	var dirs []copiedDir
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -194,49 +358,81 @@ func pasteDir(src, dst string, p *processbar.Process, cut bool, processBarModel
 			return err
 		}
 		newPath := filepath.Join(dst, relPath)
-		return actualPasteOperation(info, path, newPath, cut, sameDev, p, processBarModel)
+		if ctx.dereference && info.Mode()&os.ModeSymlink != 0 {
+			return pasteDereferenced(path, newPath, info, sameDev, policy, ctx)
+		}
+		atime := time.Time{}
+		if info.IsDir() {
+			atime = getSrcAccessTime(path, info)
+		}
+		err = actualPasteOperation(info, path, newPath, sameDev, policy, ctx)
+		if err == nil && info.IsDir() {
+			dirs = append(dirs, copiedDir{src: path, dst: newPath, info: info, atime: atime})
+		}
+		return err
 	})
-
 	if err != nil {
 		return err
 	}
+	return preserveDirsMetadata(dirs)
+}
 
-	// If this was a cut operation and we had to do a manual copy, remove the source
-	if cut && !sameDev {
-		err = os.RemoveAll(src)
-		if err != nil {
-			return fmt.Errorf("failed to remove source after move: %w", err)
-		}
+// pasteDereferenced pastes what the symlink at path points to. Broken symlinks, and
+// the ones pointing to a directory that is already being pasted, are kept as symlinks
+func pasteDereferenced(path, newPath string, info os.FileInfo, sameDev bool, policy pasteConflictPolicy,
+	ctx *pasteContext) error {
+	targetInfo, err := os.Stat(path)
+	if err != nil {
+		slog.Warn("Keeping broken symlink as is", "path", path, "error", err)
+		return actualPasteOperation(info, path, newPath, sameDev, policy, ctx)
 	}
-
-	return nil
+	if !targetInfo.IsDir() {
+		return actualPasteOperation(targetInfo, path, newPath, sameDev, policy, ctx)
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+	if ctx.derefDirs[realPath] || isAncestor(realPath, filepath.Dir(path)) {
+		ctx.p.AddWarning("Kept " + path + " as a symlink, as dereferencing it would loop")
+		return actualPasteOperation(info, path, newPath, sameDev, policy, ctx)
+	}
+	ctx.derefDirs[realPath] = true
+	defer delete(ctx.derefDirs, realPath)
+	return pasteTree(realPath, newPath, sameDev, policy, ctx)
 }
 
-func actualPasteOperation(info os.FileInfo, path string, newPath string, cut bool, sameDev bool,
-	p *processbar.Process, processBarModel *processbar.Model) error {
-	var err error
+func actualPasteOperation(info os.FileInfo, path string, newPath string, sameDev bool,
+	policy pasteConflictPolicy, ctx *pasteContext) error {
+	p := ctx.p
+	newPath, proceed, err := resolvePasteConflict(info, newPath, policy)
 	if info.IsDir() {
-		// TODO - this is likely not needed because we did
-		// dst, err := renameIfDuplicate(dst) above
-		newPath, err = renameIfDuplicate(newPath)
 		if err != nil {
 			return err
 		}
-		err = os.MkdirAll(newPath, info.Mode())
-		return err
+		if !proceed {
+			skipPasteItem(path, p, ctx.processBarModel)
+			return filepath.SkipDir
+		}
+		return os.MkdirAll(newPath, info.Mode())
 	}
 
 	// File
-	p.Name = icon.GetCopyOrCutIcon(cut) + icon.Space + filepath.Base(path)
-	if cut && sameDev {
-		err = os.Rename(path, newPath)
-	} else {
-		err = copyFile(path, newPath, info)
+	p.Name = icon.GetCopyOrCutIcon(ctx.cut) + icon.Space + filepath.Base(path)
+	if err == nil && !proceed {
+		skipPasteItem(path, p, ctx.processBarModel)
+		return nil
+	}
+	if err == nil {
+		err = p.Checkpoint()
+	}
+	if err == nil {
+		err = pasteFile(info, path, newPath, sameDev, ctx)
 	}
 
 	if err != nil {
-		p.State = processbar.Failed
-		pSendErr := processBarModel.SendUpdateProcessMsg(*p, true)
+		p.State = getProcessStateFromError(err)
+		pSendErr := ctx.processBarModel.SendUpdateProcessMsg(*p, true)
 		if pSendErr != nil {
 			slog.Error("Error sending process update", "error", pSendErr)
 		}
@@ -244,7 +440,131 @@ func actualPasteOperation(info os.FileInfo, path string, newPath string, cut boo
 	}
 
 	p.Done++
+	ctx.processBarModel.TrySendingUpdateProcessMsg(*p)
+	return nil
+}
+
+// pasteFile pastes anything other than a directory. Skipped special files are
+// not removed from the source during a cut operation, and neither are the files
+// waiting to be verified
+func pasteFile(info os.FileInfo, path string, newPath string, sameDev bool, ctx *pasteContext) error {
+	if ctx.cut && sameDev {
+		err := os.Rename(path, newPath)
+		if err == nil {
+			ctx.p.AddDoneBytes(info.Size())
+		}
+		return err
+	}
+	pasted, err := copyEntry(path, newPath, info, ctx.hardlinks, ctx.p, ctx.processBarModel)
+	if err != nil || !pasted {
+		return err
+	}
+	if ctx.verify && info.Mode().IsRegular() {
+		// The source of a moved file is only removed once its copy is verified
+		ctx.copied = append(ctx.copied, copiedFile{src: path, dst: newPath, size: info.Size()})
+		return nil
+	}
+	if ctx.cut {
+		err = os.Remove(path)
+	}
+	return err
+}
+
+// resolvePasteConflict applies the policy if dst already exists. It returns the path to paste to,
+// and whether the item should be pasted at all. Existing directories are kept as is when a
+// directory is pasted onto them, so that their contents get merged according to the same policy.
+func resolvePasteConflict(srcInfo os.FileInfo, dst string, policy pasteConflictPolicy) (string, bool, error) {
+	dstInfo, err := os.Lstat(dst)
+	if os.IsNotExist(err) {
+		return dst, true, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	switch policy {
+	case conflictSkip:
+		return dst, false, nil
+	case conflictKeepBoth:
+		dst, err = renameIfDuplicate(dst)
+		return dst, err == nil, err
+	case conflictOverwrite, conflictOverwriteIfNewer, conflictCompareSizes:
+	}
+
+	if srcInfo.IsDir() && dstInfo.IsDir() {
+		return dst, true, nil
+	}
+	if !shouldOverwrite(policy, srcInfo, dstInfo) {
+		return dst, false, nil
+	}
+	// A file is truncated while being copied over, but anything else has to be removed first
+	if srcInfo.IsDir() || dstInfo.IsDir() {
+		if err = os.RemoveAll(dst); err != nil {
+			return "", false, fmt.Errorf("failed to remove existing destination: %w", err)
+		}
+	}
+	return dst, true, nil
+}
+
+func shouldOverwrite(policy pasteConflictPolicy, srcInfo os.FileInfo, dstInfo os.FileInfo) bool {
+	switch policy {
+	case conflictOverwrite:
+		return true
+	case conflictOverwriteIfNewer:
+		return srcInfo.ModTime().After(dstInfo.ModTime())
+	case conflictCompareSizes:
+		return srcInfo.Size() != dstInfo.Size()
+	case conflictSkip, conflictKeepBoth:
+		return false
+	default:
+		return false
+	}
+}
+
+// skipPasteItem marks all files inside path as done, without pasting them
+func skipPasteItem(path string, p *processbar.Process, processBarModel *processbar.Model) {
+	cnt, size, err := getFilesCntAndSize(path)
+	if err != nil {
+		slog.Error("Error in getFilesCntAndSize", "error", err)
+	}
+	slog.Debug("Skipping paste of conflicting item", "path", path, "files", cnt, "bytes", size)
+	p.Done += cnt
+	p.AddDoneBytes(size)
 	processBarModel.TrySendingUpdateProcessMsg(*p)
+}
+
+// removeEmptyDirs removes root and all of its subdirectories that are empty
+func removeEmptyDirs(root string) error {
+	info, err := os.Lstat(root)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return nil
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if err = removeEmptyDirs(filepath.Join(root, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	entries, err = os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return os.Remove(root)
+	}
 	return nil
 }
 
@@ -0,0 +1,222 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yorukot/superfile/src/config/icon"
+	"github.com/yorukot/superfile/src/internal/archivefs"
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+)
+
+// archiveDir is a directory copied out of an archive. Its permissions and time
+// are applied once its contents are pasted
+type archiveDir struct {
+	dst  string
+	info os.FileInfo
+}
+
+// pasteArchiveItem copies src, a file or directory inside an archive, out of the archive to dst.
+// Only the members under src are extracted. policy decides what happens to the items that
+// already exist at the destination. procIcon is shown before the name of the file being
+// extracted. Returns the path the item was pasted to, or empty string if it was skipped
+func pasteArchiveItem(src, dst string, policy pasteConflictPolicy, procIcon string,
+	ctx *pasteContext) (string, error) {
+	srcInfo, err := archivefs.Stat(src)
+	if err != nil {
+		return "", err
+	}
+	dst, proceed, err := resolvePasteConflict(srcInfo, dst, policy)
+	if err != nil {
+		return "", err
+	}
+	if !proceed {
+		skipPasteItem(src, ctx.p, ctx.processBarModel)
+		return "", nil
+	}
+	return dst, extractArchiveTree(src, dst, policy, procIcon, ctx)
+}
+
+// extractArchiveTree extracts src, and everything under it, to dst. The conflict of dst
+// itself must already be resolved. Nothing is written outside of dst
+func extractArchiveTree(src, dst string, policy pasteConflictPolicy, procIcon string, ctx *pasteContext) error {
+	// Tar archives need not list the directories before their contents, so all of them are
+	// created first. dstDirs has where each directory is pasted, by its path relative to src
+	dstDirs := make(map[string]string)
+	var dirs []archiveDir
+	err := archivefs.Walk(src, func(p string, info os.FileInfo) error {
+		if !info.IsDir() {
+			return nil
+		}
+		dirDst, proceed, err := getArchiveMemberDst(src, p, info, dst, dstDirs, policy)
+		if err != nil || !proceed {
+			return err
+		}
+		if !checkExtractDst(dst, dirDst, p, ctx.p) {
+			return nil
+		}
+		if err = os.MkdirAll(dirDst, 0o755); err != nil {
+			return err
+		}
+		dstDirs[getArchiveRelPath(src, p)] = dirDst
+		dirs = append(dirs, archiveDir{dst: dirDst, info: info})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = archivefs.ReadFiles(src, ctx.password, func(f archivefs.File) error {
+		fileDst, proceed, err := getArchiveMemberDst(src, f.Path, f.Info, dst, dstDirs, policy)
+		if err != nil {
+			return err
+		}
+		if !proceed || !checkExtractDst(dst, fileDst, f.Path, ctx.p) {
+			ctx.p.Done++
+			ctx.p.AddDoneBytes(f.Info.Size())
+			ctx.processBarModel.TrySendingUpdateProcessMsg(*ctx.p)
+			return nil
+		}
+		ctx.p.Name = procIcon + icon.Space + f.Info.Name()
+		if err = ctx.p.Checkpoint(); err != nil {
+			return err
+		}
+		if err = extractArchiveFile(f, fileDst, ctx); err != nil {
+			return err
+		}
+		ctx.p.Done++
+		ctx.processBarModel.TrySendingUpdateProcessMsg(*ctx.p)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return setArchiveDirsMetadata(dirs)
+}
+
+// checkExtractDst is the zip slip protection of extraction. A member is only written to
+// target if it is dst itself, or inside dst once the symlinks of its parent directories are
+// resolved. Members that would escape are skipped with a warning on the process
+func checkExtractDst(dst, target, member string, p *processbar.Process) bool {
+	if target == dst {
+		return true
+	}
+	err := checkInsideDir(dst, target)
+	if err == nil {
+		return true
+	}
+	slog.Warn("Skipping archive member that escapes the destination", "member", member, "error", err)
+	p.AddWarning("Skipped " + member + ", as it would be written outside of " + dst)
+	return false
+}
+
+// checkInsideDir returns an error unless target is inside dir, after resolving the symlinks
+// in both dir and the parent of target. target itself is not resolved, as existing files
+// are replaced rather than written through
+func checkInsideDir(dir, target string) error {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	realParent, err := filepath.EvalSymlinks(filepath.Dir(target))
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(realDir, filepath.Join(realParent, filepath.Base(target)))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s is outside of %s", target, dir)
+	}
+	return nil
+}
+
+// getArchiveMemberDst returns where the member at p, under src, is pasted, and whether it
+// should be pasted at all. Members of the directories that were skipped are skipped too
+func getArchiveMemberDst(src, p string, info os.FileInfo, dst string, dstDirs map[string]string,
+	policy pasteConflictPolicy) (string, bool, error) {
+	relPath := getArchiveRelPath(src, p)
+	// The conflict of src itself is already resolved
+	if relPath == "" {
+		return dst, true, nil
+	}
+	parent := path.Dir(relPath)
+	if parent == "." {
+		parent = ""
+	}
+	parentDst, ok := dstDirs[parent]
+	if !ok {
+		return "", false, nil
+	}
+	return resolvePasteConflict(info, filepath.Join(parentDst, path.Base(relPath)), policy)
+}
+
+// Returns the slash separated path of p relative to src, where p is src or a member under it
+func getArchiveRelPath(src, p string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(p, src), "/")
+}
+
+// extractArchiveFile writes a file read from an archive to dst. Symlinks are recreated,
+// but other special files are skipped with a warning on the process
+func extractArchiveFile(f archivefs.File, dst string, ctx *pasteContext) error {
+	mode := f.Info.Mode()
+	if mode&os.ModeSymlink != 0 {
+		if err := removeExistingFile(dst); err != nil {
+			return err
+		}
+		return os.Symlink(f.Link, dst)
+	}
+	if !mode.IsRegular() {
+		slog.Warn("Skipping special file in archive", "path", f.Path, "mode", mode)
+		ctx.p.AddWarning("Skipped " + f.Path + ", as special files are not copied out of archives")
+		return nil
+	}
+
+	// An existing symlink must be replaced, instead of writing to where it points
+	if err := removeExistingFile(dst); err != nil {
+		return err
+	}
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer dstFile.Close()
+	w := &progressWriter{w: dstFile, p: ctx.p, processBarModel: ctx.processBarModel}
+	_, err = io.Copy(w, &checkpointReader{r: f, p: ctx.p})
+	if err != nil {
+		dstFile.Close()
+		if removeErr := os.Remove(dst); removeErr != nil {
+			slog.Error("Failed to remove partly written file", "path", dst, "error", removeErr)
+		}
+		return fmt.Errorf("failed to copy file contents: %w", err)
+	}
+	if err = dstFile.Close(); err != nil {
+		return fmt.Errorf("failed to close destination file: %w", err)
+	}
+	if common.Config.ArchiveCopy {
+		return os.Chtimes(dst, time.Time{}, f.Info.ModTime())
+	}
+	return nil
+}
+
+// setArchiveDirsMetadata applies the permissions of the directories copied out of an archive,
+// and their times if archive copy is enabled. Subdirectories are done before their parents
+func setArchiveDirsMetadata(dirs []archiveDir) error {
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].dst, dirs[i].info.Mode().Perm()); err != nil {
+			return err
+		}
+		if !common.Config.ArchiveCopy {
+			continue
+		}
+		if err := os.Chtimes(dirs[i].dst, time.Time{}, dirs[i].info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
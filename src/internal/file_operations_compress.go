@@ -1,7 +1,10 @@
 package internal
 
 import (
+	"archive/tar"
 	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
@@ -11,11 +14,74 @@ import (
 	"strings"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+
 	"github.com/yorukot/superfile/src/config/icon"
 	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/zipcrypto"
+)
+
+type archiveFormat string
+
+const (
+	archiveZip    archiveFormat = "zip"
+	archiveTar    archiveFormat = "tar"
+	archiveTarGz  archiveFormat = "tar.gz"
+	archiveTarXz  archiveFormat = "tar.xz"
+	archiveTarZst archiveFormat = "tar.zst"
+)
+
+// Formats offered by the compress modal, in order
+var archiveFormats = []archiveFormat{ //nolint: gochecknoglobals // This is effectively const.
+	archiveZip, archiveTar, archiveTarGz, archiveTarXz, archiveTarZst,
+}
+
+const (
+	minCompressLevel     = 1
+	maxCompressLevel     = 9
+	defaultCompressLevel = 6
 )
 
-func zipSources(sources []string, target string, processBar *processbar.Model) error {
+// Dictionary sizes of xz presets 1 to 9
+var xzDictCaps = []int{ //nolint: gochecknoglobals // This is effectively const.
+	1 << 20, 2 << 20, 4 << 20, 4 << 20, 8 << 20, 8 << 20, 16 << 20, 32 << 20, 64 << 20,
+}
+
+func (f archiveFormat) ext() string {
+	return "." + string(f)
+}
+
+// Plain tar archives are not compressed
+func (f archiveFormat) hasLevel() bool {
+	return f != archiveTar
+}
+
+// archiveFormatFromPath returns the format of an archive by its extension
+func archiveFormatFromPath(path string) archiveFormat {
+	for _, f := range archiveFormats {
+		if strings.HasSuffix(path, f.ext()) {
+			return f
+		}
+	}
+	return archiveZip
+}
+
+type compressOptions struct {
+	format archiveFormat
+	// From minCompressLevel to maxCompressLevel
+	level int
+	// Files of zip archives are encrypted with AES-256 if set. Other formats cannot be encrypted
+	password string
+}
+
+// archiveWriter adds the walked files to an archive of some format
+type archiveWriter interface {
+	addFile(path string, relPath string, info os.FileInfo, p *processbar.Process) error
+	Close() error
+}
+
+func compressSources(sources []string, target string, opts compressOptions, processBar *processbar.Model) error {
 	var err error
 
 	totalFiles := 0
@@ -29,10 +95,14 @@ func zipSources(sources []string, target string, processBar *processbar.Model) e
 		}
 		totalFiles += count
 	}
-	p, err := processBar.SendAddProcessMsg("zip file", totalFiles, true)
+	p, err := processBar.SendAddProcessMsg(string(opts.format)+" file", totalFiles, true)
 	if err != nil {
 		return fmt.Errorf("cannot spawn process : %w", err)
 	}
+	p.Operation = operationCompress
+	p.Sources, p.Dest = sources, target
+	release := processBar.WaitForTurn(&p, getProcessDevice(target))
+	defer release()
 	_, err = os.Stat(target)
 	if err == nil {
 		p.Name = icon.CompressFile + icon.Space + "File already exist"
@@ -47,29 +117,56 @@ func zipSources(sources []string, target string, processBar *processbar.Model) e
 
 	f, err := os.Create(target)
 	if err != nil {
-		return err
+		return finishFailedCompression(p, processBar, err)
 	}
 	defer f.Close()
-	writer := zip.NewWriter(f)
-	defer writer.Close()
+	writer, err := newArchiveWriter(f, opts)
+	if err != nil {
+		f.Close()
+		os.Remove(target)
+		return finishFailedCompression(p, processBar, err)
+	}
 
-	zipSourcesCore(sources, processBar, &p, writer)
+	err = compressSourcesCore(sources, processBar, &p, writer)
+	// The archive is incomplete until everything is flushed
+	if closeErr := writer.Close(); closeErr != nil && err == nil {
+		err = closeErr
+		p.SetFailed(err)
+	}
 
-	if p.State != processbar.Failed {
+	if p.State == processbar.InOperation {
 		// TODO: User p.SetSuccessful(), p.SetFailed()
 		p.State = processbar.Successful
 		p.Done = totalFiles
 	}
+	if p.State == processbar.Cancelled {
+		// Dont leave an incomplete archive behind
+		f.Close()
+		if removeErr := os.Remove(target); removeErr != nil {
+			slog.Error("Error removing cancelled archive", "target", target, "error", removeErr)
+		}
+	}
 	p.DoneTime = time.Now()
 	pSendErr := processBar.SendUpdateProcessMsg(p, true)
 	if pSendErr != nil {
 		slog.Error("Error sending process update", "error", pSendErr)
 	}
-	return nil
+	return err
 }
 
-func zipSourcesCore(sources []string, processBar *processbar.Model,
-	p *processbar.Process, writer *zip.Writer) {
+// Mark the process as failed, when the archive could not be created at all
+func finishFailedCompression(p processbar.Process, processBar *processbar.Model, err error) error {
+	p.SetFailed(err)
+	p.DoneTime = time.Now()
+	pSendErr := processBar.SendUpdateProcessMsg(p, true)
+	if pSendErr != nil {
+		slog.Error("Error sending process update", "error", pSendErr)
+	}
+	return err
+}
+
+func compressSourcesCore(sources []string, processBar *processbar.Model,
+	p *processbar.Process, writer archiveWriter) error {
 	for _, src := range sources {
 		srcParentDir := filepath.Dir(src)
 		err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
@@ -77,12 +174,15 @@ func zipSourcesCore(sources []string, processBar *processbar.Model,
 			if err != nil {
 				return err
 			}
+			if err = p.Checkpoint(); err != nil {
+				return err
+			}
 			relPath, err := filepath.Rel(srcParentDir, path)
 			if err != nil {
 				return err
 			}
 
-			err = writeZipFile(path, relPath, info, writer)
+			err = writer.addFile(path, relPath, info, p)
 			if err != nil {
 				return err
 			}
@@ -92,14 +192,69 @@ func zipSourcesCore(sources []string, processBar *processbar.Model,
 			return nil
 		})
 		if err != nil {
-			slog.Error("Error while zip file", "error", err)
-			p.State = processbar.Failed
-			break
+			slog.Error("Error while compressing files", "error", err)
+			p.SetFailed(err)
+			return err
+		}
+	}
+	return nil
+}
+
+func newArchiveWriter(w io.Writer, opts compressOptions) (archiveWriter, error) {
+	level := max(min(opts.level, maxCompressLevel), minCompressLevel)
+	switch opts.format {
+	case archiveZip:
+		writer := zip.NewWriter(w)
+		writer.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(out, level)
+		})
+		return &zipArchiveWriter{w: writer, password: opts.password, level: level}, nil
+	case archiveTar:
+		return newTarArchiveWriter(w, nil), nil
+	case archiveTarGz:
+		gz, err := gzip.NewWriterLevel(w, level)
+		if err != nil {
+			return nil, err
+		}
+		return newTarArchiveWriter(gz, gz), nil
+	case archiveTarXz:
+		xzWriter, err := xz.WriterConfig{DictCap: xzDictCaps[level-1]}.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		return newTarArchiveWriter(xzWriter, xzWriter), nil
+	case archiveTarZst:
+		zstdWriter, err := zstd.NewWriter(w, zstd.WithEncoderLevel(getZstdLevel(level)))
+		if err != nil {
+			return nil, err
 		}
+		return newTarArchiveWriter(zstdWriter, zstdWriter), nil
+	}
+	return nil, fmt.Errorf("unknown archive format %q", opts.format)
+}
+
+// zstd has only four levels of compression
+func getZstdLevel(level int) zstd.EncoderLevel {
+	switch {
+	case level <= 2:
+		return zstd.SpeedFastest
+	case level <= 5:
+		return zstd.SpeedDefault
+	case level <= 8:
+		return zstd.SpeedBetterCompression
+	default:
+		return zstd.SpeedBestCompression
 	}
 }
 
-func writeZipFile(path string, relPath string, info os.FileInfo, writer *zip.Writer) error {
+type zipArchiveWriter struct {
+	w *zip.Writer
+	// Password the files are encrypted with, if any
+	password string
+	level    int
+}
+
+func (z *zipArchiveWriter) addFile(path string, relPath string, info os.FileInfo, p *processbar.Process) error {
 	header, err := zip.FileInfoHeader(info)
 	if err != nil {
 		return err
@@ -109,11 +264,70 @@ func writeZipFile(path string, relPath string, info os.FileInfo, writer *zip.Wri
 	if info.IsDir() {
 		header.Name += "/"
 	}
-	headerWriter, err := writer.CreateHeader(header)
+	if info.IsDir() {
+		_, err = z.w.CreateHeader(header)
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if z.password == "" {
+		headerWriter, err := z.w.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(headerWriter, &checkpointReader{r: file, p: p})
+		return err
+	}
+	// Only the contents are encrypted. Names and directories are not
+	encWriter, err := zipcrypto.Create(z.w, header, z.password, z.level)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(encWriter, &checkpointReader{r: file, p: p}); err != nil {
+		return err
+	}
+	return encWriter.Close()
+}
+
+func (z *zipArchiveWriter) Close() error {
+	return z.w.Close()
+}
+
+// Tar archives keep the permissions, ownership and symlinks, unlike zip
+type tarArchiveWriter struct {
+	w *tar.Writer
+	// Compressor the tar stream is written to, if any
+	compressor io.Closer
+}
+
+func newTarArchiveWriter(w io.Writer, compressor io.Closer) *tarArchiveWriter {
+	return &tarArchiveWriter{w: tar.NewWriter(w), compressor: compressor}
+}
+
+func (t *tarArchiveWriter) addFile(path string, relPath string, info os.FileInfo, p *processbar.Process) error {
+	var link string
+	if info.Mode()&os.ModeSymlink != 0 {
+		var err error
+		if link, err = os.Readlink(path); err != nil {
+			return err
+		}
+	}
+	header, err := tar.FileInfoHeader(info, link)
 	if err != nil {
 		return err
 	}
+	header.Name = filepath.ToSlash(relPath)
 	if info.IsDir() {
+		header.Name += "/"
+	}
+	if err = t.w.WriteHeader(header); err != nil {
+		return err
+	}
+	// Only regular files have content. Others are restored from their header
+	if !info.Mode().IsRegular() {
 		return nil
 	}
 	file, err := os.Open(path)
@@ -121,15 +335,29 @@ func writeZipFile(path string, relPath string, info os.FileInfo, writer *zip.Wri
 		return err
 	}
 	defer file.Close()
-	_, err = io.Copy(headerWriter, file)
-	if err != nil {
-		return err
+	_, err = io.Copy(t.w, &checkpointReader{r: file, p: p})
+	return err
+}
+
+func (t *tarArchiveWriter) Close() error {
+	err := t.w.Close()
+	if t.compressor != nil {
+		err = errors.Join(err, t.compressor.Close())
 	}
-	return nil
+	return err
 }
 
-func getZipArchiveName(base string) (string, error) {
-	zipName := strings.TrimSuffix(base, filepath.Ext(base)) + ".zip"
-	zipName, err := renameIfDuplicate(zipName)
-	return zipName, err
+// getArchivePath returns a free path in dir for an archive named after base
+func getArchivePath(dir string, base string, format archiveFormat) (string, error) {
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	path := filepath.Join(dir, name+format.ext())
+	for i := 1; i < 10_000; i++ {
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			return path, nil
+		} else if err != nil {
+			return "", err
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s(%d)%s", name, i, format.ext()))
+	}
+	return "", fmt.Errorf("could not find free name for %s after many attempts", name+format.ext())
 }
@@ -1,44 +1,303 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"golift.io/xtractr"
 
 	"github.com/yorukot/superfile/src/config/icon"
+	"github.com/yorukot/superfile/src/internal/archivefs"
 	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/zipcrypto"
 )
 
-func extractCompressFile(src, dest string, processBar *processbar.Model) error {
-	p, err := processBar.SendAddProcessMsg(icon.ExtractFile+icon.Space+"unzip file", 1, true)
+// Messages of the errors of the libraries used by xtractr, when the password of an archive
+// is missing or incorrect
+const (
+	xtractrNoPasswordMsg    = "aes7z: no password set"
+	xtractrWrongPasswordMsg = "rardecode: incorrect password"
+)
+
+// extractOptions decides what is extracted from an archive, and where to
+type extractOptions struct {
+	// Paths of the members to extract, inside the archive. The whole archive is extracted if empty
+	members []string
+	// Directory the members are extracted into. It is created if missing
+	dest string
+	// What happens to the items that already exist at the destination
+	policy pasteConflictPolicy
+	// Password of encrypted archives. Supported for zip, rar and 7z
+	password string
+}
+
+// extractCompressFile extracts the archive at src. Zip and tar archives are extracted one
+// member at a time, with the progress reported for each file. Other formats are extracted
+// as a whole by xtractr, into a temporary directory that is then moved into the destination.
+// Returns zipcrypto.ErrPasswordRequired or zipcrypto.ErrWrongPassword if the archive is
+// encrypted, and the password is missing or wrong
+func extractCompressFile(src string, opts extractOptions, processBar *processbar.Model) error {
+	if archivefs.IsArchive(src) {
+		// Checked before anything is created, so that nothing is left behind when the
+		// extraction is retried with a password
+		if err := checkExtractPassword(src, opts); err != nil {
+			return err
+		}
+	}
+	_, statErr := os.Stat(opts.dest)
+	createdDest := os.IsNotExist(statErr)
+	if err := os.MkdirAll(opts.dest, 0o755); err != nil {
+		return fmt.Errorf("cannot create the destination: %w", err)
+	}
+	// Only a destination created by the extraction is removed when it is cancelled
+	cleanupDir := ""
+	if createdDest {
+		cleanupDir = opts.dest
+	}
+	if archivefs.IsArchive(src) {
+		return extractArchive(src, opts, cleanupDir, processBar)
+	}
+	if len(opts.members) != 0 {
+		return fmt.Errorf("cannot extract single members of %s", src)
+	}
+	return extractWithXtractr(src, opts, cleanupDir, processBar)
+}
+
+func checkExtractPassword(archive string, opts extractOptions) error {
+	sources := opts.members
+	if len(sources) == 0 {
+		sources = []string{archivefs.Root(archive)}
+	}
+	for _, src := range sources {
+		if err := archivefs.CheckPassword(src, opts.password); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractArchive(archive string, opts extractOptions, cleanupDir string, processBar *processbar.Model) error {
+	sources := opts.members
+	if len(sources) == 0 {
+		sources = []string{archivefs.Root(archive)}
+	}
+	fileCnt, totalBytes := 0, int64(0)
+	for _, src := range sources {
+		err := archivefs.Walk(src, func(_ string, info fs.FileInfo) error {
+			if !info.IsDir() {
+				fileCnt++
+				totalBytes += info.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	p, err := processBar.SendAddProcessMsg(icon.ExtractFile+icon.Space+filepath.Base(archive), fileCnt, true)
 	if err != nil {
 		return fmt.Errorf("cannot spawn process : %w", err)
 	}
+	p.TotalBytes = totalBytes
+	p.Operation = operationExtract
+	p.Sources, p.Dest = sources, opts.dest
+	release := processBar.WaitForTurn(&p, getProcessDevice(opts.dest))
+	defer release()
+
+	ctx := newPasteContext(&p, processBar, false)
+	ctx.password = opts.password
+	for _, src := range sources {
+		if err = p.Checkpoint(); err != nil {
+			break
+		}
+		_, member, _ := archivefs.SplitPath(src)
+		if member == "" {
+			// The contents of the archive go right into the destination
+			err = extractArchiveTree(src, opts.dest, opts.policy, icon.ExtractFile, ctx)
+		} else {
+			_, err = pasteArchiveItem(src, filepath.Join(opts.dest, filepath.Base(member)), opts.policy,
+				icon.ExtractFile, ctx)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	p.Name = icon.ExtractFile + icon.Space + filepath.Base(archive)
+	if err != nil {
+		p.SetFailed(err)
+		slog.Error("Error extracting", "path", archive, "error", err, "state", p.State)
+		if p.State == processbar.Cancelled {
+			return finishCancelledExtraction(p, cleanupDir, processBar, err)
+		}
+	} else {
+		p.State = processbar.Successful
+		p.Done = p.Total
+		p.DoneBytes = p.TotalBytes
+	}
+	p.DoneTime = time.Now()
+	if pSendErr := processBar.SendUpdateProcessMsg(p, true); pSendErr != nil {
+		slog.Error("Error sending process update", "error", pSendErr)
+	}
+	return err
+}
 
+func extractWithXtractr(src string, opts extractOptions, cleanupDir string, processBar *processbar.Model) error {
+	p, err := processBar.SendAddProcessMsg(icon.ExtractFile+icon.Space+filepath.Base(src), 1, true)
+	if err != nil {
+		return fmt.Errorf("cannot spawn process : %w", err)
+	}
+	p.Operation = operationExtract
+	p.Sources, p.Dest = []string{src}, opts.dest
+	release := processBar.WaitForTurn(&p, getProcessDevice(opts.dest))
+	defer release()
+
+	// xtractr extracts the whole archive in a single call, so we can only see
+	// the cancellation before starting, and after its done.
+	if err = p.Checkpoint(); err != nil {
+		return finishCancelledExtraction(p, cleanupDir, processBar, err)
+	}
+
+	// xtractr overwrites existing files, so the policy is applied when moving its output
+	// into the destination. The temporary directory is on the same device, so moving is cheap
+	tmpDir, err := os.MkdirTemp(opts.dest, ".spf-extract-")
+	if err != nil {
+		return fmt.Errorf("cannot create a temporary directory: %w", err)
+	}
+	defer func() {
+		if removeErr := os.RemoveAll(tmpDir); removeErr != nil {
+			slog.Error("Error removing temporary extraction directory", "dir", tmpDir, "error", removeErr)
+		}
+	}()
 	x := &xtractr.XFile{
 		FilePath:  src,
-		OutputDir: dest,
+		OutputDir: tmpDir,
 		FileMode:  0644,
 		DirMode:   0755,
+		Password:  opts.password,
 	}
 
 	_, _, _, err = xtractr.ExtractFile(x)
+	if err != nil {
+		err = getXtractrPasswordError(src, opts.password, err)
+	}
+	if err == nil && p.IsCancelRequested() {
+		return finishCancelledExtraction(p, cleanupDir, processBar, p.Checkpoint())
+	}
+	if err == nil {
+		err = moveExtractedItems(tmpDir, opts.dest, opts.policy, &p, processBar)
+		p.Name = icon.ExtractFile + icon.Space + filepath.Base(src)
+	}
 
 	if err != nil {
-		p.State = processbar.Failed
+		p.SetFailed(err)
 		slog.Error("Error extracting", "path", src, "error", err)
+		// The extraction is retried from scratch once the password is given
+		if isArchivePasswordError(err) && cleanupDir != "" {
+			if removeErr := os.RemoveAll(cleanupDir); removeErr != nil {
+				slog.Error("Error removing failed extraction", "dest", cleanupDir, "error", removeErr)
+			}
+		}
 	} else {
 		p.State = processbar.Successful
-		p.Done = 1
+		p.Done = p.Total
+	}
+
+	p.DoneTime = time.Now()
+	pSendErr := processBar.SendUpdateProcessMsg(p, true)
+	if pSendErr != nil {
+		slog.Error("Error sending process update", "error", pSendErr)
+	}
+
+	return err
+}
+
+// moveExtractedItems moves everything extracted to tmpDir into dest. policy decides what
+// happens to the items that already exist there, the same way as when pasting
+func moveExtractedItems(tmpDir string, dest string, policy pasteConflictPolicy, p *processbar.Process,
+	processBar *processbar.Model) error {
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		return err
+	}
+	p.Total = 0
+	err = filepath.WalkDir(tmpDir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			p.Total++
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	ctx := newPasteContext(p, processBar, true)
+	for _, entry := range entries {
+		src := filepath.Join(tmpDir, entry.Name())
+		dst := filepath.Join(dest, entry.Name())
+		if entry.IsDir() {
+			_, err = pasteDir(src, dst, policy, ctx)
+		} else {
+			var info os.FileInfo
+			if info, err = entry.Info(); err == nil {
+				err = actualPasteOperation(info, src, dst, true, policy, ctx)
+			}
+		}
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
+// Remove whatever was extracted to cleanupDir, and mark the process as cancelled. Nothing is
+// removed if cleanupDir is empty, as the destination existed before the extraction
+func finishCancelledExtraction(p processbar.Process, cleanupDir string, processBar *processbar.Model, err error) error {
+	if cleanupDir != "" {
+		if removeErr := os.RemoveAll(cleanupDir); removeErr != nil {
+			slog.Error("Error removing cancelled extraction", "dest", cleanupDir, "error", removeErr)
+		}
+	}
+	p.State = processbar.Cancelled
 	p.DoneTime = time.Now()
 	pSendErr := processBar.SendUpdateProcessMsg(p, true)
 	if pSendErr != nil {
 		slog.Error("Error sending process update", "error", pSendErr)
 	}
+	return err
+}
 
+// getXtractrPasswordError returns the error of zipcrypto for a failed extraction of a rar or 7z
+// archive that needs a password, or another one. The libraries xtractr uses do not export their
+// errors, so only their messages for a missing or incorrect password are matched. Other errors,
+// like the checksum errors that a wrong password can also cause, are returned as is
+func getXtractrPasswordError(src string, password string, err error) error {
+	ext := strings.ToLower(filepath.Ext(src))
+	if ext != ".rar" && ext != ".7z" {
+		return err
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, xtractrNoPasswordMsg):
+		slog.Debug("Extraction failed without a password", "path", src, "error", err)
+		return zipcrypto.ErrPasswordRequired
+	case strings.Contains(msg, xtractrWrongPasswordMsg):
+		slog.Debug("Extraction failed because of the password", "path", src, "error", err)
+		if password == "" {
+			return zipcrypto.ErrPasswordRequired
+		}
+		return zipcrypto.ErrWrongPassword
+	}
 	return err
 }
+
+// Reports whether the extraction can be retried with a password
+func isArchivePasswordError(err error) bool {
+	return errors.Is(err, zipcrypto.ErrPasswordRequired) || errors.Is(err, zipcrypto.ErrWrongPassword)
+}
@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yorukot/superfile/src/config/icon"
+	"github.com/yorukot/superfile/src/internal/archivefs"
+	"github.com/yorukot/superfile/src/internal/journal"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+)
+
+// validateLinkOperation checks that links to the items can be created in panelLocation.
+// Hardlinks can only be created to files on the same device
+func validateLinkOperation(panelLocation string, items []string, kind linkKind) error {
+	if archivefs.IsArchivePath(panelLocation) {
+		return errors.New("cannot create links inside an archive, archives are read-only")
+	}
+	for _, item := range items {
+		if archivefs.IsArchivePath(item) {
+			return fmt.Errorf("cannot link to %s, as it is inside an archive", item)
+		}
+		if kind != hardlink {
+			continue
+		}
+		info, err := os.Lstat(item)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return fmt.Errorf("cannot hardlink %s, directories cannot be hardlinked", item)
+		}
+		// A symlink is hardlinked itself, not its target, so it is on the device of its directory
+		device := item
+		if info.Mode()&os.ModeSymlink != 0 {
+			device = filepath.Dir(item)
+		}
+		if getProcessDevice(device) != getProcessDevice(panelLocation) {
+			return fmt.Errorf("cannot hardlink %s, as it is on another device than %s", item, panelLocation)
+		}
+	}
+	return nil
+}
+
+// executeLinkOperation creates links to the items in panelLocation. policies decides how to
+// handle the items whose name already exists there, the same way as for executePasteOperation.
+// Links that did not replace anything are recorded in the journal
+func executeLinkOperation(processBarModel *processbar.Model, panelLocation string, items []string,
+	kind linkKind, policies map[string]pasteConflictPolicy, j *journal.Journal,
+) processbar.ProcessState {
+	slog.Debug("executeLinkOperation", "items", items, "kind", kind, "panel location", panelLocation)
+
+	p, err := processBarModel.SendAddProcessMsg(icon.Link+icon.Space+filepath.Base(items[0]), len(items), true)
+	if err != nil {
+		slog.Error("Cannot spawn a new process", "error", err)
+		return processbar.Failed
+	}
+	p.Operation = kind.operation()
+	p.Sources, p.Dest = items, panelLocation
+
+	var linkedItems []journal.Item
+	for _, item := range items {
+		if err = p.Checkpoint(); err != nil {
+			p.SetFailed(err)
+			break
+		}
+		policy, hasConflict := policies[item]
+		if !hasConflict {
+			policy = conflictKeepBoth
+		}
+		var link, target string
+		link, target, err = createLink(item, filepath.Join(panelLocation, filepath.Base(item)), kind, policy)
+		p.Name = icon.Link + icon.Space + filepath.Base(item)
+		if err != nil {
+			p.SetFailed(fmt.Errorf("%s: %w", item, err))
+			slog.Error("Error creating link", "error", err, "current item", item, "state", p.State)
+			break
+		}
+		p.Done++
+		processBarModel.TrySendingUpdateProcessMsg(p)
+		if link != "" && policy == conflictKeepBoth {
+			linkedItems = append(linkedItems, journal.NewItem(target, link))
+		}
+	}
+	opType := journal.SymlinkOperation
+	if kind == hardlink {
+		opType = journal.HardlinkOperation
+	}
+	j.Record(opType, linkedItems, nil)
+
+	if p.State == processbar.InOperation {
+		p.State = processbar.Successful
+	}
+	p.DoneTime = time.Now()
+	if err = processBarModel.SendUpdateProcessMsg(p, true); err != nil {
+		slog.Error("Could not send final update for process Bar", "error", err)
+	}
+	return p.State
+}
+
+// createLink creates a link to src at dst, once the conflict with an existing dst is resolved
+// according to policy. Returns the path of the link and its target, or an empty path if the
+// link was skipped. An existing directory is never replaced, as its content would be lost
+func createLink(src, dst string, kind linkKind, policy pasteConflictPolicy) (string, string, error) {
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return "", "", err
+	}
+	if policy != conflictSkip && policy != conflictKeepBoth {
+		if dstInfo, statErr := os.Lstat(dst); statErr == nil && dstInfo.IsDir() {
+			return "", "", fmt.Errorf("cannot replace the directory %s with a link", dst)
+		}
+	}
+	dst, proceed, err := resolvePasteConflict(srcInfo, dst, policy)
+	if err != nil || !proceed {
+		return "", "", err
+	}
+	// A file is truncated when pasted over, but a link has to replace it
+	if _, err = os.Lstat(dst); err == nil {
+		if err = os.Remove(dst); err != nil {
+			return "", "", fmt.Errorf("failed to remove existing destination: %w", err)
+		}
+	}
+
+	target := src
+	switch kind {
+	case symlinkAbsolute:
+		err = os.Symlink(target, dst)
+	case symlinkRelative:
+		target, err = filepath.Rel(filepath.Dir(dst), src)
+		if err == nil {
+			err = os.Symlink(target, dst)
+		}
+	case hardlink:
+		err = os.Link(src, dst)
+	case noLink:
+		err = errors.New("no kind of link to create")
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return dst, target, nil
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/yorukot/superfile/src/internal/common"
+)
+
+// copiedDir is a directory whose metadata is applied after its contents are copied,
+// as writing the contents updates its modification time
+type copiedDir struct {
+	src   string
+	dst   string
+	info  os.FileInfo
+	atime time.Time
+}
+
+// getSrcAccessTime must be called before reading src, as reading could update its access time.
+// Returns zero time if archive copy is disabled
+func getSrcAccessTime(src string, srcInfo os.FileInfo) time.Time {
+	if !common.Config.ArchiveCopy {
+		return time.Time{}
+	}
+	return getAccessTime(src, srcInfo)
+}
+
+// preserveMetadata applies the permissions, ownership, extended attributes and times of
+// src to the already copied dst, if archive copy is enabled
+func preserveMetadata(src string, dst string, srcInfo os.FileInfo, atime time.Time) error {
+	if !common.Config.ArchiveCopy {
+		return nil
+	}
+	// Changing ownership could clear the setuid and setgid bits, so it has to be done before chmod
+	if err := copyOwnership(src, dst); err != nil {
+		return fmt.Errorf("failed to preserve ownership of %s: %w", dst, err)
+	}
+	if err := copyXattrs(src, dst); err != nil {
+		return fmt.Errorf("failed to preserve extended attributes of %s: %w", dst, err)
+	}
+	// Symlinks don't have their own permissions and times, these calls would follow them
+	if srcInfo.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+	// Mode is passed at creation too, but the umask is applied to it there
+	if err := os.Chmod(dst, srcInfo.Mode()); err != nil {
+		return fmt.Errorf("failed to preserve permissions of %s: %w", dst, err)
+	}
+	if err := os.Chtimes(dst, atime, srcInfo.ModTime()); err != nil {
+		return fmt.Errorf("failed to preserve times of %s: %w", dst, err)
+	}
+	return nil
+}
+
+// preserveDirsMetadata applies the metadata of directories in reverse order of their copy,
+// so that subdirectories are done before their parents
+func preserveDirsMetadata(dirs []copiedDir) error {
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := preserveMetadata(dirs[i].src, dirs[i].dst, dirs[i].info, dirs[i].atime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
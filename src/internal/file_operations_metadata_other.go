@@ -0,0 +1,21 @@
+//go:build !linux && !darwin
+
+package internal
+
+import (
+	"os"
+	"time"
+)
+
+// Access time is not exposed in a portable way, modification time is the closest
+func getAccessTime(_ string, srcInfo os.FileInfo) time.Time {
+	return srcInfo.ModTime()
+}
+
+func copyOwnership(_ string, _ string) error {
+	return nil
+}
+
+func copyXattrs(_ string, _ string) error {
+	return nil
+}
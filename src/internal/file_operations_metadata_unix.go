@@ -0,0 +1,96 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func getAccessTime(src string, srcInfo os.FileInfo) time.Time {
+	var st unix.Stat_t
+	if err := unix.Lstat(src, &st); err != nil {
+		slog.Error("Failed to get access time, using modification time", "path", src, "error", err)
+		return srcInfo.ModTime()
+	}
+	return time.Unix(st.Atim.Unix())
+}
+
+// Ownership can only be given away by root, for others the copy belongs to them
+func copyOwnership(src string, dst string) error {
+	if os.Geteuid() != 0 {
+		return nil
+	}
+	var st unix.Stat_t
+	if err := unix.Lstat(src, &st); err != nil {
+		return err
+	}
+	return os.Lchown(dst, int(st.Uid), int(st.Gid))
+}
+
+func copyXattrs(src string, dst string) error {
+	names, err := listXattrs(src)
+	if err != nil {
+		if isXattrUnsupported(err) {
+			return nil
+		}
+		return err
+	}
+	for _, name := range names {
+		value, err := getXattr(src, name)
+		if err == nil {
+			err = unix.Lsetxattr(dst, name, value, 0)
+		}
+		// The destination filesystem may not support them, or only root may set some namespaces.
+		// That should not fail the whole copy
+		if isXattrUnsupported(err) || errors.Is(err, unix.EPERM) {
+			slog.Debug("Skipping extended attribute", "path", dst, "name", name, "error", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func listXattrs(path string) ([]string, error) {
+	size, err := unix.Llistxattr(path, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Llistxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range strings.SplitSeq(string(buf[:size]), "\x00") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func getXattr(path string, name string) ([]byte, error) {
+	size, err := unix.Lgetxattr(path, name, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Lgetxattr(path, name, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:size], nil
+}
+
+func isXattrUnsupported(err error) bool {
+	return errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EOPNOTSUPP)
+}
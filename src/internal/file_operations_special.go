@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+)
+
+// Zero blocks of this size in sparse files are skipped, leaving holes in the copy
+const sparseBlockSize = 4096
+
+// Identifies a file across its hardlinks
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// pasteContext is shared by all the items of a single paste operation
+type pasteContext struct {
+	p               *processbar.Process
+	processBarModel *processbar.Model
+	cut             bool
+	// Copy what symlinks point to, instead of the symlinks. Moves always keep symlinks as is
+	dereference bool
+	// Where the first link of each file with multiple hardlinks was pasted, so that
+	// its other links are linked to it instead of being copied again
+	hardlinks map[fileID]string
+	// Real paths of the dereferenced directories being pasted, to detect symlink loops
+	derefDirs map[string]bool
+	// Verify the copied files once everything is pasted
+	verify bool
+	// The files to verify, and the source directories of a cut operation that can only be
+	// removed after that
+	copied  []copiedFile
+	cutDirs []string
+	// Sources of the copied files that failed verification, or were not verified at all
+	unverified []string
+	// Password of the encrypted files copied out of an archive
+	password string
+}
+
+func newPasteContext(p *processbar.Process, processBarModel *processbar.Model, cut bool) *pasteContext {
+	return &pasteContext{
+		p:               p,
+		processBarModel: processBarModel,
+		cut:             cut,
+		dereference:     common.Config.DereferenceSymlinks && !cut,
+		hardlinks:       make(map[fileID]string),
+		derefDirs:       make(map[string]bool),
+	}
+}
+
+// copyEntry copies anything other than a directory. Symlinks are recreated as symlinks, special
+// files are recreated if possible, and files already copied via another hardlink are linked to
+// that copy if hardlinks is not nil. Returns false if the entry was skipped
+func copyEntry(src, dst string, info os.FileInfo, hardlinks map[fileID]string,
+	p *processbar.Process, processBarModel *processbar.Model) (bool, error) {
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		return true, copySymlink(src, dst, info)
+	case !info.Mode().IsRegular():
+		return copySpecialFile(src, dst, info, p)
+	}
+
+	// A file could be down to a single link here, if its other links were already moved
+	id, linkCnt, hasID := getFileID(info)
+	if hasID && hardlinks != nil {
+		if firstDst, ok := hardlinks[id]; ok {
+			if err := linkToCopy(firstDst, dst); err == nil {
+				if p != nil {
+					p.AddDoneBytes(info.Size())
+				}
+				return true, nil
+			}
+			// Linking across filesystems is not possible, a copy is the closest we can do
+			slog.Debug("Could not link to the copy of another hardlink", "src", src, "dst", dst)
+		}
+	}
+	if err := copyFile(src, dst, info, p, processBarModel); err != nil {
+		return false, err
+	}
+	if hasID && hardlinks != nil && linkCnt > 1 {
+		hardlinks[id] = dst
+	}
+	return true, nil
+}
+
+func linkToCopy(firstDst string, dst string) error {
+	if err := removeExistingFile(dst); err != nil {
+		return err
+	}
+	return os.Link(firstDst, dst)
+}
+
+// copySymlink recreates the symlink at dst, pointing to the same target
+func copySymlink(src, dst string, info os.FileInfo) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return fmt.Errorf("failed to read symlink: %w", err)
+	}
+	if err = removeExistingFile(dst); err != nil {
+		return err
+	}
+	if err = os.Symlink(target, dst); err != nil {
+		return fmt.Errorf("failed to create symlink: %w", err)
+	}
+	return preserveMetadata(src, dst, info, time.Time{})
+}
+
+// copySpecialFile recreates FIFOs and device nodes. Sockets, and the special files that
+// cannot be recreated here, are skipped with a warning on the process
+func copySpecialFile(src, dst string, info os.FileInfo, p *processbar.Process) (bool, error) {
+	if err := removeExistingFile(dst); err != nil {
+		return false, err
+	}
+	err := createSpecialFile(src, dst, info)
+	if errors.Is(err, errSpecialFileNotSupported) || errors.Is(err, os.ErrPermission) {
+		slog.Warn("Skipping special file", "path", src, "mode", info.Mode(), "error", err)
+		p.AddWarning(fmt.Sprintf("Skipped %s : %v", src, err))
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create special file: %w", err)
+	}
+	return true, preserveMetadata(src, dst, info, getSrcAccessTime(src, info))
+}
+
+// Files can be written over, but symlinks and special files have to be created anew
+func removeExistingFile(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	return os.Remove(path)
+}
+
+// sparseWriter leaves holes in place of blocks that are all zeros, instead of writing them.
+// The file must be truncated to its final size after the writes, in case it ends with a hole
+type sparseWriter struct {
+	f *os.File
+}
+
+func (s *sparseWriter) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		block := b[:min(len(b), sparseBlockSize)]
+		var err error
+		if isZeroBlock(block) {
+			_, err = s.f.Seek(int64(len(block)), io.SeekCurrent)
+		} else {
+			_, err = s.f.Write(block)
+		}
+		if err != nil {
+			return written, err
+		}
+		written += len(block)
+		b = b[len(block):]
+	}
+	return written, nil
+}
+
+func isZeroBlock(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,22 @@
+//go:build !linux && !darwin
+
+package internal
+
+import (
+	"errors"
+	"os"
+)
+
+var errSpecialFileNotSupported = errors.New("special files cannot be copied on this platform")
+
+func getFileID(_ os.FileInfo) (fileID, uint64, bool) {
+	return fileID{}, 0, false
+}
+
+func isSparse(_ os.FileInfo) bool {
+	return false
+}
+
+func createSpecialFile(_ string, _ string, _ os.FileInfo) error {
+	return errSpecialFileNotSupported
+}
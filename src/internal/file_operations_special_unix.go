@@ -0,0 +1,55 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"errors"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+var errSpecialFileNotSupported = errors.New("sockets cannot be copied")
+
+// getFileID returns the id of the file, and its count of hardlinks
+func getFileID(info os.FileInfo) (fileID, uint64, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, 0, false
+	}
+	//nolint:gosec,unconvert // Types of these fields differ across platforms
+	return fileID{dev: uint64(st.Dev), ino: uint64(st.Ino)}, uint64(st.Nlink), true
+}
+
+// isSparse reports whether fewer blocks are allocated for the file than its size needs
+func isSparse(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	// Blocks are always counted in 512 byte units
+	return st.Blocks*512 < st.Size
+}
+
+func createSpecialFile(src string, dst string, info os.FileInfo) error {
+	perm := uint32(info.Mode().Perm())
+	switch {
+	case info.Mode()&os.ModeNamedPipe != 0:
+		return unix.Mkfifo(dst, perm)
+	case info.Mode()&os.ModeDevice != 0:
+		var st unix.Stat_t
+		if err := unix.Lstat(src, &st); err != nil {
+			return err
+		}
+		mode := perm | unix.S_IFBLK
+		if info.Mode()&os.ModeCharDevice != 0 {
+			mode = perm | unix.S_IFCHR
+		}
+		// Only root can create device nodes. EPERM matches os.ErrPermission
+		//nolint:gosec,unconvert // Rdev type differs across platforms
+		return unix.Mknod(dst, mode, int(st.Rdev))
+	default:
+		return errSpecialFileNotSupported
+	}
+}
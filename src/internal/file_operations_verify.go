@@ -0,0 +1,149 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/yorukot/superfile/src/config/icon"
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+)
+
+var errChecksumMismatch = errors.New("checksum of the copy does not match the source")
+
+// A file pasted by copying its contents, to be verified once the paste is done
+type copiedFile struct {
+	src  string
+	dst  string
+	size int64
+}
+
+// shouldVerifyCopy tells whether files pasted to dst should be verified, as per the config
+func shouldVerifyCopy(dst string) bool {
+	switch common.Config.VerifyCopy {
+	case common.VerifyCopyAlways:
+		return true
+	case common.VerifyCopyExternal:
+		return isExternalDiskPath(dst)
+	default:
+		return false
+	}
+}
+
+// verifyPaste is the verifying phase of a paste. It compares the checksum of each copied file
+// with its source. During a cut operation, the sources are removed only after their copy
+// is verified. Every mismatching file is added to the process errors, and
+// errChecksumMismatch is returned if there was any
+func verifyPaste(ctx *pasteContext) error {
+	p := ctx.p
+	var totalBytes int64
+	for _, f := range ctx.copied {
+		// Both the source and the copy are read
+		totalBytes += 2 * f.size
+	}
+	p.StartVerifying(len(ctx.copied), totalBytes)
+	ctx.processBarModel.TrySendingUpdateProcessMsg(*p)
+
+	mismatches := 0
+	for i, f := range ctx.copied {
+		p.Name = icon.Search + icon.Space + filepath.Base(f.src)
+		err := verifyCopiedFile(f, p, ctx.processBarModel)
+		switch {
+		case errors.Is(err, errChecksumMismatch):
+			slog.Error("Copied file does not match its source", "src", f.src, "dst", f.dst)
+			p.AddError(f.dst + ": " + err.Error())
+			ctx.addUnverified(f)
+			mismatches++
+		case err != nil:
+			p.AddError(f.dst + ": " + err.Error())
+			ctx.addUnverified(ctx.copied[i:]...)
+			return err
+		case ctx.cut:
+			if err = os.Remove(f.src); err != nil {
+				p.AddError(f.src + ": " + err.Error())
+				ctx.addUnverified(ctx.copied[i:]...)
+				return err
+			}
+		}
+		p.Done++
+		ctx.processBarModel.TrySendingUpdateProcessMsg(*p)
+	}
+
+	// The directories could not be removed while they still had files to verify
+	for _, dir := range ctx.cutDirs {
+		if err := removeEmptyDirs(dir); err != nil {
+			return fmt.Errorf("failed to remove source after move: %w", err)
+		}
+	}
+	if mismatches > 0 {
+		return fmt.Errorf("%d file(s) failed verification: %w", mismatches, errChecksumMismatch)
+	}
+	return nil
+}
+
+// Records files whose copy is not verified, or whose source could not be removed after it
+func (ctx *pasteContext) addUnverified(files ...copiedFile) {
+	for _, f := range files {
+		ctx.unverified = append(ctx.unverified, f.src)
+	}
+}
+
+// verifiedItems returns the pasted items that have no unverified file
+func (ctx *pasteContext) verifiedItems(items []string) []string {
+	var verified []string
+	for _, item := range items {
+		unverified := slices.ContainsFunc(ctx.unverified, func(src string) bool {
+			return src == item || strings.HasPrefix(src, item+string(filepath.Separator))
+		})
+		if !unverified {
+			verified = append(verified, item)
+		}
+	}
+	return verified
+}
+
+func verifyCopiedFile(f copiedFile, p *processbar.Process, processBarModel *processbar.Model) error {
+	srcSum, err := hashFile(f.src, false, p, processBarModel)
+	if err != nil {
+		return err
+	}
+	dstSum, err := hashFile(f.dst, true, p, processBarModel)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(srcSum, dstSum) {
+		return errChecksumMismatch
+	}
+	return nil
+}
+
+// hashFile returns the SHA-256 checksum of the file. If fromDisk is true, the cached
+// pages of the file are dropped first where possible, so that what was actually written
+// to the disk is read, rather than what is still in memory
+func hashFile(path string, fromDisk bool, p *processbar.Process, processBarModel *processbar.Model) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file for verification: %w", err)
+	}
+	defer f.Close()
+	if fromDisk {
+		if err = dropFileCache(f); err != nil {
+			slog.Debug("Could not drop the cached pages of the file", "path", path, "error", err)
+		}
+	}
+
+	h := sha256.New()
+	w := &progressWriter{w: h, p: p, processBarModel: processBarModel}
+	if _, err = io.Copy(w, &checkpointReader{r: f, p: p}); err != nil {
+		return nil, fmt.Errorf("failed to read file for verification: %w", err)
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,17 @@
+//go:build linux
+
+package internal
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// dropFileCache flushes the file to the disk and evicts it from the page cache
+func dropFileCache(f *os.File) error {
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	return unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_DONTNEED)
+}
@@ -3,8 +3,11 @@ package internal
 import (
 	"fmt"
 	"log/slog"
-	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/yorukot/superfile/src/internal/archivefs"
 	"github.com/yorukot/superfile/src/internal/utils"
 )
 
@@ -15,6 +18,47 @@ func (panel *filePanel) getSelectedItem() element {
 	return panel.element[panel.cursor]
 }
 
+// getElementSource returns what the elements of the panel are currently read from
+func (panel *filePanel) getElementSource(displayDotFile bool) elementSource {
+	return elementSource{
+		location:     panel.location,
+		search:       panel.searchBar.Value(),
+		sortSelected: panel.sortOptions.data.selected,
+		sortReversed: panel.sortOptions.data.reversed,
+		dotFiles:     displayDotFile,
+		tree:         panel.panelMode == treeMode,
+		flat:         panel.panelMode == flatMode,
+		flatListed:   panel.flatListing.len(),
+	}
+}
+
+// readElements reads the elements of the panel from its directory, filtered by the search bar.
+// The tree view also reads the expanded directories, unless it is filtered. The flat view
+// filters the files listed so far instead
+func (panel *filePanel) readElements(displayDotFile bool) {
+	switch {
+	case panel.panelMode == flatMode:
+		panel.element = panel.flatListing.filter(panel.searchBar.Value())
+	case panel.searchBar.Value() != "":
+		panel.element = returnDirElementBySearchString(panel.location, displayDotFile,
+			panel.searchBar.Value(), panel.sortOptions.data)
+	case panel.panelMode == treeMode:
+		panel.element = returnTreeElements(panel.location, displayDotFile, panel.sortOptions.data,
+			panel.expandedDirs)
+	default:
+		panel.element = returnDirElement(panel.location, displayDotFile, panel.sortOptions.data)
+	}
+	panel.lastTimeGetElement = time.Now()
+	panel.lastElementSource = panel.getElementSource(displayDotFile)
+}
+
+// usesSelection reports whether operations apply to the selected items, instead of the item
+// under the cursor. The tree and flat views use the cursor until something is selected
+func (panel *filePanel) usesSelection() bool {
+	return panel.panelMode == selectMode ||
+		((panel.panelMode == treeMode || panel.panelMode == flatMode) && len(panel.selected) > 0)
+}
+
 func (panel *filePanel) resetSelected() {
 	panel.selected = panel.selected[:0]
 }
@@ -35,6 +79,9 @@ func (panel *filePanel) changeFilePanelMode() {
 		panel.panelMode = browserMode
 	case browserMode:
 		panel.panelMode = selectMode
+	case treeMode, flatMode:
+		// The selection made in the tree or flat view is kept
+		panel.panelMode = selectMode
 	default:
 		slog.Error("Unexpected panelMode", "panelMode", panel.panelMode)
 	}
@@ -44,7 +91,7 @@ func (panel *filePanel) changeFilePanelMode() {
 func (panel *filePanel) updateCurrentFilePanelDir(path string) error {
 	slog.Debug("updateCurrentFilePanelDir", "panel.location", panel.location, "path", path)
 	// In case non Absolute path is passed, make sure to resolve it.
-	path = utils.ResolveAbsPath(panel.location, path)
+	path = resolvePanelPath(panel.location, path)
 
 	// Ignore if its the same directory. It prevents resetting of searchBar
 	if path == panel.location {
@@ -58,7 +105,7 @@ func (panel *filePanel) updateCurrentFilePanelDir(path string) error {
 		directoryRender: panel.render,
 	}
 
-	if info, err := os.Stat(path); err != nil {
+	if info, err := archivefs.Stat(path); err != nil {
 		return fmt.Errorf("%s : no such file or directory, stats err : %w", path, err)
 	} else if !info.IsDir() {
 		return fmt.Errorf("%s is not a directory", path)
@@ -89,6 +136,18 @@ func (panel *filePanel) updateCurrentFilePanelDir(path string) error {
 	return nil
 }
 
+// resolvePanelPath resolves path relative to the panel location, which can be inside an archive
+func resolvePanelPath(location string, path string) string {
+	switch {
+	case archivefs.IsArchivePath(path):
+		return archivefs.Clean(path)
+	case archivefs.IsArchivePath(location) && !filepath.IsAbs(path) && !strings.HasPrefix(path, "~"):
+		return archivefs.Join(location, path)
+	default:
+		return utils.ResolveAbsPath(location, path)
+	}
+}
+
 func (panel *filePanel) parentDirectory() error {
 	return panel.updateCurrentFilePanelDir("..")
 }
@@ -0,0 +1,272 @@
+package internal
+
+import (
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/reinhrst/fzf-lib"
+
+	"github.com/yorukot/superfile/src/internal/archivefs"
+	"github.com/yorukot/superfile/src/internal/gitignore"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+// The files found by a listing are sent by batches, so that large trees are shown while they
+// are still being listed, without an update per file
+const (
+	flatBatchSize     = 1000
+	flatBatchInterval = 100 * time.Millisecond
+	// Listing stops after that many files, so that a flat view of / does not grow without bound
+	flatMaxFiles = 100000
+)
+
+// flatListing holds the files found so far under a directory, for the flat view. It is only
+// modified by the model, the files being listed by another goroutine
+type flatListing struct {
+	location string
+	dotFiles bool
+	files    []element
+	done     bool
+	// Whether the listing stopped at maxFiles
+	truncated bool
+	maxFiles  int
+	cancel    chan struct{}
+
+	// Matches of the last search among the first filteredCnt files, the best first. The
+	// files listed after that are matched alone, and merged in
+	search      string
+	matches     []fzf.MatchResult
+	filteredCnt int
+}
+
+type flatBatch struct {
+	listing   *flatListing
+	files     []element
+	done      bool
+	truncated bool
+}
+
+func newFlatListing(location string, dotFiles bool) *flatListing {
+	return &flatListing{
+		location: location,
+		dotFiles: dotFiles,
+		maxFiles: flatMaxFiles,
+		cancel:   make(chan struct{}),
+	}
+}
+
+func (listing *flatListing) len() int {
+	if listing == nil {
+		return 0
+	}
+	return len(listing.files)
+}
+
+func (listing *flatListing) stop() {
+	if !listing.done {
+		listing.done = true
+		close(listing.cancel)
+	}
+}
+
+// filter returns the files whose relative path fuzzy matches search, the best matches first.
+// Without search, all files are returned in the order they were listed. While the search
+// stays the same, only the files listed since the previous call are matched
+func (listing *flatListing) filter(search string) []element {
+	if listing == nil {
+		return nil
+	}
+	if search == "" {
+		return slices.Clone(listing.files)
+	}
+	if search != listing.search {
+		listing.search = search
+		listing.matches = nil
+		listing.filteredCnt = 0
+	}
+	if newFiles := listing.files[listing.filteredCnt:]; len(newFiles) > 0 {
+		names := make([]string, len(newFiles))
+		for i, item := range newFiles {
+			names[i] = item.name
+		}
+		newMatches := utils.FzfSearch(search, names)
+		for i := range newMatches {
+			newMatches[i].HayIndex += int32(listing.filteredCnt) //nolint:gosec // Bounded by flatMaxFiles
+		}
+		listing.matches = mergeFzfMatches(listing.matches, newMatches)
+		listing.filteredCnt = len(listing.files)
+	}
+	res := make([]element, 0, len(listing.matches))
+	for _, match := range listing.matches {
+		res = append(res, listing.files[match.HayIndex])
+	}
+	return res
+}
+
+// mergeFzfMatches merges two lists of matches sorted by score, the best first. On the same
+// score, the matches of a come first
+func mergeFzfMatches(a, b []fzf.MatchResult) []fzf.MatchResult {
+	res := make([]fzf.MatchResult, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if b[0].Score > a[0].Score {
+			res = append(res, b[0])
+			b = b[1:]
+		} else {
+			res = append(res, a[0])
+			a = a[1:]
+		}
+	}
+	res = append(res, a...)
+	return append(res, b...)
+}
+
+// list sends the files under the directory to batches, until it is done, cancelled, or
+// maxFiles are listed. The directories ignored by .gitignore files are skipped, as well as the
+// dot files if they are hidden. Symlinks to directories are listed as files, so that the
+// listing cannot loop
+func (listing *flatListing) list(batches chan<- flatBatch) {
+	var batch []element
+	lastSent := time.Now()
+	listedCnt := 0
+	truncated := false
+	send := func(done bool) bool {
+		select {
+		case batches <- flatBatch{listing: listing, files: batch, done: done, truncated: truncated}:
+			batch = nil
+			lastSent = time.Now()
+			return true
+		case <-listing.cancel:
+			return false
+		}
+	}
+
+	// The .gitignore files of archives cannot be read
+	useGitignore := !archivefs.IsArchivePath(listing.location)
+	var listDir func(dir string, rules gitignore.Rules) bool
+	listDir = func(dir string, rules gitignore.Rules) bool {
+		entries, err := archivefs.ReadDir(dir)
+		if err != nil {
+			slog.Debug("Cannot read directory of the flat view", "dir", dir, "error", err)
+			return true
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			itemLocation := archivefs.Join(dir, name)
+			if (name == ".git" && entry.IsDir()) || (!listing.dotFiles && strings.HasPrefix(name, ".")) ||
+				rules.Ignored(itemLocation, entry.IsDir()) {
+				continue
+			}
+			if entry.IsDir() {
+				subRules := rules
+				if useGitignore {
+					subRules = rules.With(itemLocation)
+				}
+				if !listDir(itemLocation, subRules) {
+					return false
+				}
+				continue
+			}
+			relPath, err := filepath.Rel(listing.location, itemLocation)
+			if err != nil {
+				relPath = name
+			}
+			batch = append(batch, element{
+				name:          relPath,
+				location:      itemLocation,
+				symlinkTarget: getSymlinkTarget(entry, itemLocation),
+			})
+			listedCnt++
+			if listedCnt >= listing.maxFiles {
+				truncated = true
+				return false
+			}
+			if (len(batch) >= flatBatchSize || time.Since(lastSent) >= flatBatchInterval) && !send(false) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var rules gitignore.Rules
+	if useGitignore {
+		rules = gitignore.RulesFor(listing.location)
+	}
+	if listDir(listing.location, rules) || truncated {
+		send(true)
+	}
+}
+
+// Starts the listings of the panels in the flat view, again if their directory or the
+// display of dot files changed, and stops the listings that are not needed anymore
+func (m *model) listFlatPanels() {
+	for i := range m.fileModel.filePanels {
+		panel := &m.fileModel.filePanels[i]
+		listing := panel.flatListing
+		if panel.panelMode == flatMode && listing != nil && listing.location == panel.location &&
+			listing.dotFiles == m.toggleDotFile {
+			continue
+		}
+		if listing != nil {
+			listing.stop()
+			panel.flatListing = nil
+		}
+		if panel.panelMode == flatMode {
+			panel.flatListing = newFlatListing(panel.location, m.toggleDotFile)
+			go panel.flatListing.list(m.flatBatches)
+		}
+	}
+}
+
+func (m *model) getFlatListingCmd() tea.Cmd {
+	if m.flatBatches == nil {
+		return nil
+	}
+	batches := m.flatBatches
+	reqID := m.ioReqCnt
+	m.ioReqCnt++
+	return func() tea.Msg {
+		return NewFlatListingMsg(<-batches, reqID)
+	}
+}
+
+// Switches the focused panel between the flat view and the browser mode, with the cursor
+// kept on the same item if it is shown
+func (m *model) toggleFlatView() {
+	panel := m.getFocusedFilePanel()
+	location := panel.getSelectedItem().location
+	switch panel.panelMode {
+	case flatMode:
+		panel.resetSelected()
+		panel.panelMode = browserMode
+	case browserMode, selectMode, treeMode:
+		panel.panelMode = flatMode
+	default:
+		slog.Error("Unexpected panelMode", "panelMode", panel.panelMode)
+		return
+	}
+	m.listFlatPanels()
+	panel.readElements(m.toggleDotFile)
+	panel.moveCursorToLocation(location, m.mainPanelHeight)
+}
+
+// Leaves the flat view for the directory of the item under the cursor, with the cursor on it
+func (m *model) flatEnter() {
+	panel := m.getFocusedFilePanel()
+	if len(panel.element) == 0 {
+		return
+	}
+	location := panel.getSelectedItem().location
+	panel.resetSelected()
+	panel.panelMode = browserMode
+	panel.searchBar.SetValue("")
+	if err := m.updateCurrentFilePanelDir(archivefs.Dir(location)); err != nil {
+		slog.Error("Error while changing to directory", "error", err, "target", location)
+	}
+	m.listFlatPanels()
+	panel.readElements(m.toggleDotFile)
+	panel.moveCursorToLocation(location, m.mainPanelHeight)
+}
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+// Applies the batches of the listing of the panel, until it is done
+func waitFlatListing(t *testing.T, m *model, panel *filePanel) {
+	t.Helper()
+	require.NotNil(t, panel.flatListing)
+	for !panel.flatListing.done {
+		msg := ExecuteTeaCmdWithTimeout(m.getFlatListingCmd(), DefaultTestTimeout)
+		require.NotNil(t, msg, "The listing did not finish in time")
+		TeaUpdate(m, msg)
+	}
+}
+
+func TestFlatView(t *testing.T) {
+	curTestDir := t.TempDir()
+	dirA := filepath.Join(curTestDir, "a")
+	dirB := filepath.Join(dirA, "b")
+	dirBuild := filepath.Join(curTestDir, "build")
+	utils.SetupDirectories(t, dirA, dirB, dirBuild)
+	fileA1 := filepath.Join(dirA, "a1.txt")
+	fileC := filepath.Join(dirB, "c.txt")
+	fileZ := filepath.Join(curTestDir, "z.txt")
+	utils.SetupFiles(t, fileA1, fileC, fileZ, filepath.Join(curTestDir, ".hidden"),
+		filepath.Join(curTestDir, "debug.log"), filepath.Join(dirBuild, "out.o"))
+	require.NoError(t, os.WriteFile(filepath.Join(curTestDir, ".gitignore"), []byte("*.log\nbuild/\n"), 0o644))
+
+	m := defaultTestModel(curTestDir)
+	panel := m.getFocusedFilePanel()
+	sendKey := func(hotkeys []string) {
+		TeaUpdate(m, utils.TeaRuneKeyMsg(hotkeys[0]))
+	}
+
+	sendKey(common.Hotkeys.ToggleFlatView)
+	require.Equal(t, flatMode, panel.panelMode)
+	waitFlatListing(t, m, panel)
+	relA1 := filepath.Join("a", "a1.txt")
+	relC := filepath.Join("a", "b", "c.txt")
+	assert.Equal(t, []string{relA1, relC, "z.txt"}, elementNames(panel),
+		"Ignored and hidden files are not listed")
+
+	t.Run("Files are fuzzy filtered by their relative path", func(t *testing.T) {
+		panel.searchBar.SetValue("abc")
+		TeaUpdate(m, nil)
+		assert.Equal(t, []string{relC}, elementNames(panel))
+
+		panel.searchBar.SetValue("")
+		TeaUpdate(m, nil)
+		assert.Len(t, panel.element, 3)
+	})
+
+	t.Run("Operations apply to the files directly", func(t *testing.T) {
+		panel.moveCursorToLocation(fileC, m.mainPanelHeight)
+		sendKey(common.Hotkeys.CopyItems)
+		assert.Equal(t, []string{fileC}, m.copyItems.items)
+
+		m.panelItemRename()
+		assert.Equal(t, "c.txt", panel.rename.Value())
+		m.cancelRename()
+	})
+
+	t.Run("Dot files are listed when shown", func(t *testing.T) {
+		sendKey(common.Hotkeys.ToggleDotFile)
+		waitFlatListing(t, m, panel)
+		assert.Equal(t, []string{".gitignore", ".hidden", relA1, relC, "z.txt"}, elementNames(panel))
+		sendKey(common.Hotkeys.ToggleDotFile)
+		waitFlatListing(t, m, panel)
+	})
+
+	t.Run("Confirm jumps to the file", func(t *testing.T) {
+		panel.moveCursorToLocation(fileC, m.mainPanelHeight)
+		sendKey(common.Hotkeys.Confirm)
+		assert.Equal(t, browserMode, panel.panelMode)
+		assert.Nil(t, panel.flatListing)
+		assert.Equal(t, dirB, panel.location)
+		assert.Equal(t, fileC, panel.getSelectedItem().location)
+	})
+}
+
+func TestFlatListing(t *testing.T) {
+	curTestDir := t.TempDir()
+	var files []string
+	for _, name := range []string{"abc.txt", "a_b_c.txt", "xyz.txt", "cab.txt", "abcd.go"} {
+		files = append(files, filepath.Join(curTestDir, name))
+	}
+	utils.SetupFiles(t, files...)
+
+	t.Run("New files are matched incrementally", func(t *testing.T) {
+		listing := newFlatListing(curTestDir, false)
+		for _, file := range files {
+			listing.files = append(listing.files, element{name: filepath.Base(file), location: file})
+		}
+		expected := listing.filter("abc")
+		require.NotEmpty(t, expected)
+
+		incremental := newFlatListing(curTestDir, false)
+		for i, item := range listing.files {
+			incremental.files = append(incremental.files, item)
+			incremental.filter("abc")
+			assert.Equal(t, i+1, incremental.filteredCnt)
+		}
+		assert.ElementsMatch(t, expected, incremental.filter("abc"))
+		assert.Equal(t, expected[0], incremental.filter("abc")[0], "The best match stays first")
+	})
+
+	t.Run("Listing stops at the limit", func(t *testing.T) {
+		listing := newFlatListing(curTestDir, false)
+		listing.maxFiles = 3
+		batches := make(chan flatBatch, 10)
+		listing.list(batches)
+		var listed []element
+		for batch := range batches {
+			listed = append(listed, batch.files...)
+			if batch.done {
+				assert.True(t, batch.truncated)
+				break
+			}
+		}
+		assert.Len(t, listed, 3)
+	})
+}
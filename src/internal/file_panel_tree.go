@@ -0,0 +1,146 @@
+package internal
+
+import (
+	"log/slog"
+
+	"github.com/yorukot/superfile/src/internal/archivefs"
+)
+
+// Indentation guides of the tree view, drawn before the nested elements
+const (
+	treeGuideBranch   = "├─ "
+	treeGuideLast     = "└─ "
+	treeGuideVertical = "│  "
+	treeGuideSpace    = "   "
+)
+
+// returnTreeElements returns the elements of location, each expanded directory being followed
+// by its own elements, one level deeper. Symlinks to directories are not expanded, so that
+// the tree cannot loop
+func returnTreeElements(location string, displayDotFile bool, sortOptions sortOptionsModelData,
+	expandedDirs map[string]struct{},
+) []element {
+	var res []element
+	var addElements func(dir string, depth int, guide string)
+	addElements = func(dir string, depth int, guide string) {
+		elements := returnDirElement(dir, displayDotFile, sortOptions)
+		for i, item := range elements {
+			isLast := i == len(elements)-1
+			item.depth = depth
+			childGuide := guide
+			if depth > 0 {
+				if isLast {
+					item.treeGuide = guide + treeGuideLast
+					childGuide += treeGuideSpace
+				} else {
+					item.treeGuide = guide + treeGuideBranch
+					childGuide += treeGuideVertical
+				}
+			}
+			res = append(res, item)
+			if _, ok := expandedDirs[item.location]; ok && item.directory {
+				addElements(item.location, depth+1, childGuide)
+			}
+		}
+	}
+	addElements(location, 0, "")
+	return res
+}
+
+// shownDirs returns the directories whose entries are shown in the panel
+func (panel *filePanel) shownDirs() []string {
+	dirs := []string{panel.location}
+	if panel.panelMode != treeMode || panel.searchBar.Value() != "" {
+		return dirs
+	}
+	for _, item := range panel.element {
+		if _, ok := panel.expandedDirs[item.location]; ok && item.directory {
+			dirs = append(dirs, item.location)
+		}
+	}
+	return dirs
+}
+
+func (panel *filePanel) isExpanded(item element) bool {
+	_, ok := panel.expandedDirs[item.location]
+	return ok && item.directory && panel.searchBar.Value() == ""
+}
+
+// Moves the cursor to the element at index, and scrolls to keep it in view
+func (panel *filePanel) moveCursorTo(index int, mainPanelHeight int) {
+	panel.cursor = index
+	panel.render = min(panel.render, index)
+	panel.handleResize(mainPanelHeight)
+}
+
+// Moves the cursor to the element of location, or to the first one if it is not shown
+func (panel *filePanel) moveCursorToLocation(location string, mainPanelHeight int) {
+	for i, item := range panel.element {
+		if item.location == location {
+			panel.moveCursorTo(i, mainPanelHeight)
+			return
+		}
+	}
+	panel.moveCursorTo(0, mainPanelHeight)
+}
+
+// Switches the focused panel between the tree view and the browser mode, with the cursor
+// kept on the same item
+func (m *model) toggleTreeView() {
+	panel := m.getFocusedFilePanel()
+	location := panel.getSelectedItem().location
+	switch panel.panelMode {
+	case treeMode:
+		panel.resetSelected()
+		panel.panelMode = browserMode
+	case browserMode, selectMode, flatMode:
+		panel.panelMode = treeMode
+	default:
+		slog.Error("Unexpected panelMode", "panelMode", panel.panelMode)
+		return
+	}
+	panel.readElements(m.toggleDotFile)
+	panel.moveCursorToLocation(location, m.mainPanelHeight)
+}
+
+// Expands or collapses the directory under the cursor. Other items are opened as usual
+func (m *model) treeEnter() {
+	panel := m.getFocusedFilePanel()
+	item := panel.getSelectedItem()
+	// The search results are not shown as a tree
+	if !item.directory || panel.searchBar.Value() != "" {
+		m.enterPanel()
+		return
+	}
+	if panel.expandedDirs == nil {
+		panel.expandedDirs = make(map[string]struct{})
+	}
+	if panel.isExpanded(item) {
+		delete(panel.expandedDirs, item.location)
+	} else {
+		panel.expandedDirs[item.location] = struct{}{}
+	}
+	panel.readElements(m.toggleDotFile)
+}
+
+// Collapses the directory under the cursor, or else moves the cursor to the directory that
+// contains the item. From the top level, goes to the parent directory
+func (m *model) treeParent() {
+	panel := m.getFocusedFilePanel()
+	item := panel.getSelectedItem()
+	if panel.isExpanded(item) {
+		delete(panel.expandedDirs, item.location)
+		panel.readElements(m.toggleDotFile)
+		return
+	}
+	if item.depth > 0 {
+		parent := archivefs.Dir(item.location)
+		for i := panel.cursor - 1; i >= 0; i-- {
+			if panel.element[i].location == parent {
+				panel.moveCursorTo(i, m.mainPanelHeight)
+				return
+			}
+		}
+	}
+	m.parentDirectory()
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func elementNames(panel *filePanel) []string {
+	names := make([]string, len(panel.element))
+	for i, item := range panel.element {
+		names[i] = item.treeGuide + item.name
+	}
+	return names
+}
+
+func TestTreeView(t *testing.T) {
+	curTestDir := t.TempDir()
+	dirA := filepath.Join(curTestDir, "a")
+	dirB := filepath.Join(dirA, "b")
+	utils.SetupDirectories(t, dirA, dirB)
+	fileA1 := filepath.Join(dirA, "a1.txt")
+	fileC := filepath.Join(dirB, "c.txt")
+	fileZ := filepath.Join(curTestDir, "z.txt")
+	utils.SetupFiles(t, fileA1, fileC, fileZ)
+
+	m := defaultTestModel(curTestDir)
+	panel := m.getFocusedFilePanel()
+	sendKey := func(hotkeys []string) {
+		TeaUpdate(m, utils.TeaRuneKeyMsg(hotkeys[0]))
+	}
+
+	sendKey(common.Hotkeys.ToggleTreeView)
+	require.Equal(t, treeMode, panel.panelMode)
+	assert.Equal(t, []string{"a", "z.txt"}, elementNames(panel))
+
+	t.Run("Directories are expanded and collapsed inline", func(t *testing.T) {
+		sendKey(common.Hotkeys.Confirm)
+		assert.Equal(t, []string{"a", "├─ b", "└─ a1.txt", "z.txt"}, elementNames(panel))
+		assert.Equal(t, []string{curTestDir, dirA}, panel.shownDirs())
+
+		sendKey(common.Hotkeys.ListDown)
+		sendKey(common.Hotkeys.Confirm)
+		assert.Equal(t, []string{"a", "├─ b", "│  └─ c.txt", "└─ a1.txt", "z.txt"}, elementNames(panel))
+
+		sendKey(common.Hotkeys.ListDown)
+		assert.Equal(t, fileC, panel.getSelectedItem().location)
+		sendKey(common.Hotkeys.ParentDirectory)
+		assert.Equal(t, dirB, panel.getSelectedItem().location, "The cursor moves to the parent")
+		sendKey(common.Hotkeys.ParentDirectory)
+		assert.Equal(t, []string{"a", "├─ b", "└─ a1.txt", "z.txt"}, elementNames(panel))
+	})
+
+	t.Run("Operations apply to the cursor until items are selected", func(t *testing.T) {
+		sendKey(common.Hotkeys.ListDown)
+		sendKey(common.Hotkeys.CopyItems)
+		assert.Equal(t, []string{fileA1}, m.copyItems.items)
+
+		sendKey(common.Hotkeys.FilePanelSelectModeItemsSelectDown)
+		sendKey(common.Hotkeys.FilePanelSelectModeItemsSelectDown)
+		assert.Equal(t, []string{fileA1, fileZ}, panel.selected)
+		sendKey(common.Hotkeys.CutItems)
+		assert.Equal(t, []string{fileA1, fileZ}, m.copyItems.items)
+		assert.True(t, m.copyItems.cut)
+	})
+
+	t.Run("Nested items are renamed in their directory", func(t *testing.T) {
+		panel.moveCursorTo(findItemIndexInPanelByLocation(panel, fileA1), m.mainPanelHeight)
+		m.panelItemRename()
+		panel.rename.SetValue("renamed.txt")
+		m.confirmRename()
+		verifyPathExists(t, filepath.Join(dirA, "renamed.txt"), "The item is renamed next to it")
+	})
+
+	t.Run("Expanded directories are kept when switching back", func(t *testing.T) {
+		panel.moveCursorTo(findItemIndexInPanelByLocation(panel, fileZ), m.mainPanelHeight)
+		sendKey(common.Hotkeys.ToggleTreeView)
+		assert.Equal(t, browserMode, panel.panelMode)
+		assert.Empty(t, panel.selected)
+		assert.Equal(t, []string{"a", "z.txt"}, elementNames(panel))
+		assert.Equal(t, fileZ, panel.getSelectedItem().location, "The cursor stays on the item")
+
+		sendKey(common.Hotkeys.ToggleTreeView)
+		assert.Equal(t, []string{"a", "├─ b", "└─ renamed.txt", "z.txt"}, elementNames(panel))
+		assert.Equal(t, fileZ, panel.getSelectedItem().location)
+	})
+}
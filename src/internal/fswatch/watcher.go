@@ -0,0 +1,134 @@
+package fswatch
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// The platform specific way of watching directories. onChange has to be called with the
+// watched directory whenever its entries change
+type backend interface {
+	add(dir string) error
+	remove(dir string)
+	watching(dir string) bool
+	close() error
+}
+
+// Watcher reports the directories whose entries changed. The changes in a directory are
+// reported at most once per debounce interval, so that a burst of events, like the ones
+// of a copy, only causes one refresh
+type Watcher struct {
+	backend  backend
+	debounce time.Duration
+	changes  chan string
+	done     chan struct{}
+
+	mu sync.Mutex
+	// The directories that the watcher was asked to watch, including the ones that could
+	// not be watched
+	dirs    map[string]struct{}
+	pending map[string]struct{}
+	closed  bool
+}
+
+// New returns an error if directories cannot be watched on this platform. Otherwise,
+// Close must be called once the watcher is not needed anymore
+func New(debounce time.Duration) (*Watcher, error) {
+	w := &Watcher{
+		debounce: debounce,
+		changes:  make(chan string),
+		done:     make(chan struct{}),
+		dirs:     make(map[string]struct{}),
+		pending:  make(map[string]struct{}),
+	}
+	b, err := newBackend(w.changed)
+	if err != nil {
+		return nil, err
+	}
+	w.backend = b
+	return w, nil
+}
+
+// Sync watches exactly dirs. The directories that cannot be watched, like the ones on
+// network mounts, are not retried until they are removed from dirs
+func (w *Watcher) Sync(dirs []string) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+
+	wanted := make(map[string]struct{}, len(dirs))
+	for _, dir := range dirs {
+		wanted[dir] = struct{}{}
+	}
+	for dir := range w.dirs {
+		if _, ok := wanted[dir]; !ok {
+			w.backend.remove(dir)
+			delete(w.dirs, dir)
+		}
+	}
+	for dir := range wanted {
+		if _, ok := w.dirs[dir]; ok {
+			continue
+		}
+		w.dirs[dir] = struct{}{}
+		if err := w.backend.add(dir); err != nil {
+			slog.Debug("Cannot watch directory, it will be polled instead", "dir", dir, "error", err)
+		}
+	}
+}
+
+// IsWatched reports whether the changes in dir are reported. It stops being watched
+// when it is deleted or unmounted
+func (w *Watcher) IsWatched(dir string) bool {
+	if w == nil {
+		return false
+	}
+	return w.backend.watching(dir)
+}
+
+// Changes returns the channel on which the changed directories are sent
+func (w *Watcher) Changes() <-chan string {
+	if w == nil {
+		return nil
+	}
+	return w.changes
+}
+
+// Close stops watching all directories, and stops sending changes
+func (w *Watcher) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	return w.backend.close()
+}
+
+func (w *Watcher) changed(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.pending[dir]; ok || w.closed {
+		return
+	}
+	w.pending[dir] = struct{}{}
+	time.AfterFunc(w.debounce, func() {
+		w.mu.Lock()
+		delete(w.pending, dir)
+		w.mu.Unlock()
+		select {
+		case w.changes <- dir:
+		case <-w.done:
+		}
+	})
+}
@@ -0,0 +1,160 @@
+//go:build linux
+
+package fswatch
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// The events that change the entries of a directory, or their metadata. IN_MODIFY is left out,
+// as it comes for every write to a file, which would re-read the directory all along a
+// download. The size of a written file is updated once it is closed instead
+const watchMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO |
+	unix.IN_ATTRIB | unix.IN_CLOSE_WRITE | unix.IN_MOVE_SELF | unix.IN_ONLYDIR
+
+type inotify struct {
+	// Fd() of the file would make it blocking, so the descriptor is kept too
+	fd       int
+	file     *os.File
+	onChange func(dir string)
+
+	mu sync.Mutex
+	// Two paths of the same directory, like through a symlink, share the watch descriptor
+	dirs map[int][]string
+	wds  map[string]int
+}
+
+func newBackend(onChange func(dir string)) (backend, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize inotify: %w", err)
+	}
+	b := &inotify{
+		// As it is non-blocking, the reads wait in the runtime poller, and are stopped by Close
+		fd:       fd,
+		file:     os.NewFile(uintptr(fd), "inotify"),
+		onChange: onChange,
+		dirs:     make(map[int][]string),
+		wds:      make(map[string]int),
+	}
+	go b.readEvents()
+	return b, nil
+}
+
+func (b *inotify) add(dir string) error {
+	if err := checkFilesystem(dir); err != nil {
+		return err
+	}
+	wd, err := unix.InotifyAddWatch(b.fd, dir, watchMask)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.wds[dir] = wd
+	b.dirs[wd] = append(b.dirs[wd], dir)
+	return nil
+}
+
+func (b *inotify) remove(dir string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wd, ok := b.wds[dir]
+	if !ok {
+		return
+	}
+	delete(b.wds, dir)
+	b.dirs[wd] = slices.DeleteFunc(b.dirs[wd], func(d string) bool { return d == dir })
+	if len(b.dirs[wd]) > 0 {
+		return
+	}
+	delete(b.dirs, wd)
+	if _, err := unix.InotifyRmWatch(b.fd, uint32(wd)); err != nil {
+		slog.Debug("Cannot remove inotify watch", "dir", dir, "error", err)
+	}
+}
+
+func (b *inotify) watching(dir string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.wds[dir]
+	return ok
+}
+
+func (b *inotify) close() error {
+	return b.file.Close()
+}
+
+func (b *inotify) readEvents() {
+	// Enough for a lot of events, as each is at most a header and a file name
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		n, err := b.file.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				slog.Error("Cannot read inotify events", "error", err)
+			}
+			return
+		}
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			wd := int(int32(binary.NativeEndian.Uint32(buf[offset:])))
+			mask := binary.NativeEndian.Uint32(buf[offset+4:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[offset+12:]))
+			offset += unix.SizeofInotifyEvent + nameLen
+			for _, dir := range b.changedDirs(wd, mask) {
+				b.onChange(dir)
+			}
+		}
+	}
+}
+
+// changedDirs returns the directories affected by the event. The ones that cannot be watched
+// anymore, as they were deleted, moved or unmounted, stop being watched
+func (b *inotify) changedDirs(wd int, mask uint32) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		// Events were lost, so every directory could have changed
+		var dirs []string
+		for _, d := range b.dirs {
+			dirs = append(dirs, d...)
+		}
+		return dirs
+	}
+	dirs := b.dirs[wd]
+	if mask&(unix.IN_IGNORED|unix.IN_MOVE_SELF) != 0 {
+		if mask&unix.IN_IGNORED == 0 {
+			_, _ = unix.InotifyRmWatch(b.fd, uint32(wd))
+		}
+		delete(b.dirs, wd)
+		for _, dir := range dirs {
+			delete(b.wds, dir)
+		}
+	}
+	return dirs
+}
+
+// checkFilesystem returns an error for the filesystems where inotify only reports the
+// changes made by this machine, like network mounts
+func checkFilesystem(dir string) error {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(dir, &stat); err != nil {
+		return err
+	}
+	switch uint32(stat.Type) {
+	case unix.NFS_SUPER_MAGIC, unix.SMB_SUPER_MAGIC, unix.SMB2_SUPER_MAGIC, unix.CIFS_SUPER_MAGIC,
+		unix.FUSE_SUPER_MAGIC, unix.V9FS_MAGIC, unix.AFS_SUPER_MAGIC, unix.CEPH_SUPER_MAGIC,
+		unix.CODA_SUPER_MAGIC, unix.NCP_SUPER_MAGIC:
+		return fmt.Errorf("filesystem of type %#x is not watched, as changes from other machines are missed",
+			uint32(stat.Type))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+//go:build linux
+
+package fswatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDebounce = 50 * time.Millisecond
+
+func expectChange(t *testing.T, w *Watcher, dir string) {
+	t.Helper()
+	select {
+	case changed := <-w.Changes():
+		assert.Equal(t, dir, changed)
+	case <-time.After(time.Second):
+		t.Fatalf("No change reported for %s", dir)
+	}
+}
+
+func expectNoChange(t *testing.T, w *Watcher) {
+	t.Helper()
+	select {
+	case changed := <-w.Changes():
+		t.Fatalf("Unexpected change reported for %s", changed)
+	case <-time.After(4 * testDebounce):
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	w, err := New(testDebounce)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	dir1 := t.TempDir()
+	dir2 := t.TempDir()
+	w.Sync([]string{dir1, dir2, filepath.Join(dir1, "missing")})
+	assert.True(t, w.IsWatched(dir1))
+	assert.True(t, w.IsWatched(dir2))
+	assert.False(t, w.IsWatched(filepath.Join(dir1, "missing")), "Missing directories are polled")
+
+	t.Run("Bursts of events are reported once", func(t *testing.T) {
+		for i := range 20 {
+			require.NoError(t, os.WriteFile(filepath.Join(dir1, "file"+string(rune('a'+i))), []byte("data"), 0o644))
+		}
+		expectChange(t, w, dir1)
+		expectNoChange(t, w)
+	})
+
+	t.Run("Only the changed directory is reported", func(t *testing.T) {
+		require.NoError(t, os.Mkdir(filepath.Join(dir2, "sub"), 0o755))
+		expectChange(t, w, dir2)
+		expectNoChange(t, w)
+	})
+
+	t.Run("Writes to a file are reported once it is closed", func(t *testing.T) {
+		f, err := os.Create(filepath.Join(dir2, "download"))
+		require.NoError(t, err)
+		expectChange(t, w, dir2)
+		for range 5 {
+			_, err = f.WriteString("data")
+			require.NoError(t, err)
+		}
+		expectNoChange(t, w)
+		require.NoError(t, f.Close())
+		expectChange(t, w, dir2)
+	})
+
+	t.Run("Directories removed from the watch list are not reported", func(t *testing.T) {
+		w.Sync([]string{dir2})
+		assert.False(t, w.IsWatched(dir1))
+		require.NoError(t, os.Remove(filepath.Join(dir1, "filea")))
+		expectNoChange(t, w)
+	})
+
+	t.Run("Deleted directories stop being watched", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(dir2))
+		expectChange(t, w, dir2)
+		assert.Eventually(t, func() bool { return !w.IsWatched(dir2) }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Nothing is reported once closed", func(t *testing.T) {
+		w.Sync([]string{dir1})
+		require.NoError(t, w.Close())
+		require.NoError(t, os.WriteFile(filepath.Join(dir1, "new"), []byte("data"), 0o644))
+		expectNoChange(t, w)
+	})
+}
+
+func TestNilWatcher(t *testing.T) {
+	var w *Watcher
+	w.Sync([]string{t.TempDir()})
+	assert.False(t, w.IsWatched("/"))
+	assert.Nil(t, w.Changes())
+	assert.NoError(t, w.Close())
+}
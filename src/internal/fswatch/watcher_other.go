@@ -0,0 +1,10 @@
+//go:build !linux
+
+package fswatch
+
+import "errors"
+
+// Only inotify is supported as of now, the directories are polled on the other platforms
+func newBackend(_ func(dir string)) (backend, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -15,6 +15,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/yorukot/superfile/src/internal/archivefs"
 	"github.com/yorukot/superfile/src/internal/ui/processbar"
 	"github.com/yorukot/superfile/src/internal/utils"
 
@@ -65,7 +66,7 @@ func returnFocusType(focusPanel focusPanelType) bool {
 // and also consider testing this caseSensitive with both true and false in
 // our unit_test TestReturnDirElement
 func returnDirElement(location string, displayDotFile bool, sortOptions sortOptionsModelData) []element {
-	dirEntries, err := os.ReadDir(location)
+	dirEntries, err := archivefs.ReadDir(location)
 	if err != nil {
 		slog.Error("Error while returning folder elements", "error", err)
 		return nil
@@ -87,7 +88,7 @@ func returnDirElement(location string, displayDotFile bool, sortOptions sortOpti
 func returnDirElementBySearchString(location string, displayDotFile bool, searchString string,
 	sortOptions sortOptionsModelData,
 ) []element {
-	items, err := os.ReadDir(location)
+	items, err := archivefs.ReadDir(location)
 	if err != nil {
 		slog.Error("Error while return folder element function", "error", err)
 		return nil
@@ -131,15 +132,30 @@ func sortFileElement(sortOptions sortOptionsModelData, dirEntries []os.DirEntry,
 	// Preallocate for efficiency
 	directoryElement := make([]element, 0, len(dirEntries))
 	for _, item := range dirEntries {
+		itemLocation := archivefs.Join(location, item.Name())
 		directoryElement = append(directoryElement, element{
-			name:      item.Name(),
-			directory: item.IsDir(),
-			location:  filepath.Join(location, item.Name()),
+			name:          item.Name(),
+			directory:     item.IsDir(),
+			location:      itemLocation,
+			symlinkTarget: getSymlinkTarget(item, itemLocation),
 		})
 	}
 	return directoryElement
 }
 
+// getSymlinkTarget returns what the entry points to, or an empty string if it is not a symlink
+func getSymlinkTarget(entry os.DirEntry, location string) string {
+	if entry.Type()&os.ModeSymlink == 0 || archivefs.IsArchivePath(location) {
+		return ""
+	}
+	target, err := os.Readlink(location)
+	if err != nil {
+		slog.Debug("Cannot read symlink target", "path", location, "error", err)
+		return ""
+	}
+	return target
+}
+
 func getOrderingFunc(location string, dirEntries []os.DirEntry, reversed bool, sortOption string) sliceOrderFunc {
 	var order func(i, j int) bool
 	switch sortOption {
@@ -184,13 +200,13 @@ func getSizeOrderingFunc(dirEntries []os.DirEntry, reversed bool, location strin
 		// This needs to be improved, and we should sort by actual size only
 		// Repeated recursive read would be slow, so we could cache
 		if dirEntries[i].IsDir() && dirEntries[j].IsDir() {
-			filesI, err := os.ReadDir(filepath.Join(location, dirEntries[i].Name()))
+			filesI, err := archivefs.ReadDir(archivefs.Join(location, dirEntries[i].Name()))
 			// No need of early return, we only call len() on filesI, so nil would
 			// just result in 0
 			if err != nil {
 				slog.Error("Error when reading directory during sort", "error", err)
 			}
-			filesJ, err := os.ReadDir(filepath.Join(location, dirEntries[j].Name()))
+			filesJ, err := archivefs.ReadDir(archivefs.Join(location, dirEntries[j].Name()))
 			if err != nil {
 				slog.Error("Error when reading directory during sort", "error", err)
 			}
@@ -324,6 +340,33 @@ func countFiles(dirPath string) (int, error) {
 	return count, err
 }
 
+// Returns the count of files in path and their total size
+func getFilesCntAndSize(path string) (int, int64, error) {
+	count := 0
+	var size int64
+	countFile := func(info os.FileInfo) {
+		if !info.IsDir() {
+			count++
+			size += info.Size()
+		}
+	}
+	if archivefs.IsArchivePath(path) {
+		err := archivefs.Walk(path, func(_ string, info os.FileInfo) error {
+			countFile(info)
+			return nil
+		})
+		return count, size, err
+	}
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		countFile(info)
+		return nil
+	})
+	return count, size, err
+}
+
 func processCmdToTeaCmd(cmd processbar.Cmd) tea.Cmd {
 	if cmd == nil {
 		// To prevent us from running cmd() on nil cmd
@@ -340,6 +383,15 @@ func processCmdToTeaCmd(cmd processbar.Cmd) tea.Cmd {
 	}
 }
 
+// Cancellation by the user is not a failure of the process
+func getProcessStateFromError(err error) processbar.ProcessState {
+	var cancelledErr *processbar.ProcessCancelledError
+	if errors.As(err, &cancelledErr) {
+		return processbar.Cancelled
+	}
+	return processbar.Failed
+}
+
 func getCopyOrCutOperationName(cut bool) string {
 	if cut {
 		return "cut"
@@ -0,0 +1,237 @@
+// Package gitignore matches paths against the patterns of .gitignore files, as git does.
+// The global excludes file and .git/info/exclude are not read
+package gitignore
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FileName is the name of the files holding the patterns
+const FileName = ".gitignore"
+
+type pattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Matcher holds the patterns of a .gitignore file, which are relative to its directory
+type Matcher struct {
+	base     string
+	patterns []pattern
+}
+
+// Parse reads the patterns of a .gitignore file located in base. Invalid patterns are skipped
+func Parse(base string, content []byte) *Matcher {
+	m := &Matcher{base: base}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if p, ok := parsePattern(scanner.Text()); ok {
+			m.patterns = append(m.patterns, p)
+		}
+	}
+	return m
+}
+
+// Load reads the .gitignore file of dir. It returns nil without error if there is none
+func Load(dir string) (*Matcher, error) {
+	content, err := os.ReadFile(filepath.Join(dir, FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Parse(dir, content), nil
+}
+
+// Match reports whether path is ignored by the last pattern matching it, and whether any
+// pattern matched at all. Paths outside the directory of the file never match
+func (m *Matcher) Match(path string, isDir bool) (bool, bool) {
+	rel, err := filepath.Rel(m.base, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false, false
+	}
+	rel = filepath.ToSlash(rel)
+	for i := len(m.patterns) - 1; i >= 0; i-- {
+		p := m.patterns[i]
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(rel) {
+			return !p.negate, true
+		}
+	}
+	return false, false
+}
+
+// Rules are the .gitignore files that apply to a directory, from the outermost one. The
+// patterns of the deeper files take precedence
+type Rules []*Matcher
+
+// RulesFor returns the rules of the .gitignore files of dir and of its parents, up to the
+// root of the git repository containing it. Outside of a repository, only the file of dir
+// itself is read
+func RulesFor(dir string) Rules {
+	dirs := []string{dir}
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			dirs = dirs[:1]
+			break
+		}
+		d = parent
+		dirs = append(dirs, d)
+	}
+
+	var rules Rules
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rules = rules.With(dirs[i])
+	}
+	return rules
+}
+
+// With returns the rules followed by the ones of the .gitignore file of dir, if it can be read.
+// The receiver is not modified
+func (r Rules) With(dir string) Rules {
+	m, err := Load(dir)
+	if err != nil || m == nil {
+		return r
+	}
+	return append(r[:len(r):len(r)], m)
+}
+
+// Ignored reports whether path is ignored by the rules
+func (r Rules) Ignored(path string, isDir bool) bool {
+	for i := len(r) - 1; i >= 0; i-- {
+		if ignored, matched := r[i].Match(path, isDir); matched {
+			return ignored
+		}
+	}
+	return false
+}
+
+func parsePattern(line string) (pattern, bool) {
+	line = trimTrailingSpaces(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern{}, false
+	}
+	var p pattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// A slash at the beginning or in the middle anchors the pattern to the directory of the
+	// file. Otherwise, it matches at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return pattern{}, false
+	}
+
+	prefix := "^(?:.*/)?"
+	if anchored {
+		prefix = "^"
+	}
+	re, err := regexp.Compile(prefix + globToRegexp(line) + "$")
+	if err != nil {
+		return pattern{}, false
+	}
+	p.re = re
+	return p, true
+}
+
+// The trailing spaces are ignored, unless they are escaped with a backslash
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		case '*':
+			if !strings.HasPrefix(glob[i:], "**") || (i > 0 && glob[i-1] != '/') {
+				sb.WriteString("[^/]*")
+				continue
+			}
+			// "**" as a whole path segment matches any number of directories
+			switch {
+			case i+2 == len(glob):
+				sb.WriteString(".*")
+				i++
+			case glob[i+2] == '/':
+				sb.WriteString("(?:.*/)?")
+				i += 2
+			default:
+				sb.WriteString("[^/]*")
+				i++
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			class, n := bracketToRegexp(glob[i:])
+			if n == 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			sb.WriteString(class)
+			i += n - 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return sb.String()
+}
+
+// bracketToRegexp converts the bracket expression at the start of glob, and returns its
+// length in glob. The length is zero if the bracket is not closed
+func bracketToRegexp(glob string) (string, int) {
+	var sb strings.Builder
+	sb.WriteString("[")
+	i := 1
+	if i < len(glob) && (glob[i] == '!' || glob[i] == '^') {
+		// As the wildcards, a negated class does not match the separator
+		sb.WriteString("^/")
+		i++
+	}
+	for start := i; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == ']' && i > start:
+			sb.WriteString("]")
+			return sb.String(), i + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case c == '-':
+			sb.WriteString("-")
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return "", 0
+}
@@ -0,0 +1,95 @@
+package gitignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	base := filepath.FromSlash("/repo")
+	m := Parse(base, []byte(`# comment
+*.log
+!keep.log
+build/
+/root.txt
+docs/*.md
+a/**/z
+**/cache
+out/**
+file[0-9].txt
+name[!a].txt
+\#hash
+trailing   
+`))
+
+	testdata := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"debug.log", false, true},
+		{"sub/debug.log", false, true},
+		{"keep.log", false, false},
+		{"sub/keep.log", false, false},
+		{"build", true, true},
+		{"sub/build", true, true},
+		{"build", false, false},
+		{"root.txt", false, true},
+		{"sub/root.txt", false, false},
+		{"docs/readme.md", false, true},
+		{"docs/sub/readme.md", false, false},
+		{"sub/docs/readme.md", false, false},
+		{"a/z", false, true},
+		{"a/b/c/z", false, true},
+		{"cache", true, true},
+		{"x/y/cache", true, true},
+		{"out/file", false, true},
+		{"out", true, false},
+		{"file1.txt", false, true},
+		{"filex.txt", false, false},
+		{"nameb.txt", false, true},
+		{"namea.txt", false, false},
+		{"#hash", false, true},
+		{"trailing", false, true},
+		{"comment", false, false},
+		{"main.go", false, false},
+	}
+	for _, tt := range testdata {
+		t.Run(tt.path, func(t *testing.T) {
+			ignored, _ := m.Match(filepath.Join(base, filepath.FromSlash(tt.path)), tt.isDir)
+			assert.Equal(t, tt.expected, ignored)
+		})
+	}
+
+	_, matched := m.Match(filepath.FromSlash("/other/debug.log"), false)
+	assert.False(t, matched, "Paths outside of the base directory do not match")
+}
+
+func TestRules(t *testing.T) {
+	repo := t.TempDir()
+	sub := filepath.Join(repo, "sub")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0o755))
+	require.NoError(t, os.MkdirAll(sub, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, FileName), []byte("*.tmp\n*.log\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(sub, FileName), []byte("!keep.log\n"), 0o644))
+
+	rules := RulesFor(sub)
+	require.Len(t, rules, 2)
+	assert.True(t, rules.Ignored(filepath.Join(sub, "a.tmp"), false))
+	assert.True(t, rules.Ignored(filepath.Join(sub, "a.log"), false))
+	assert.False(t, rules.Ignored(filepath.Join(sub, "keep.log"), false),
+		"The patterns of the deeper file take precedence")
+	assert.False(t, rules.Ignored(filepath.Join(sub, "main.go"), false))
+
+	// Outside of a repository, the files of the parents are not read
+	outside := t.TempDir()
+	nested := filepath.Join(outside, "nested")
+	require.NoError(t, os.MkdirAll(nested, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, FileName), []byte("*\n"), 0o644))
+	assert.Empty(t, RulesFor(nested))
+	assert.Len(t, RulesFor(outside), 1)
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"slices"
+
+	"github.com/yorukot/superfile/src/internal/archivefs"
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/notify"
+)
+
+// archiveReadOnlyKey refuses the keys that would change the contents of an archive while
+// it is browsed in the focused panel. Extracting members is allowed, as it only writes
+// outside of the archive. Returns true if msg was refused
+func (m *model) archiveReadOnlyKey(msg string) bool {
+	if m.focusPanel != nonePanelFocus || !archivefs.IsArchivePath(m.getFocusedFilePanel().location) {
+		return false
+	}
+	mutatingKeys := [][]string{
+		common.Hotkeys.PasteItems,
+		common.Hotkeys.PasteAsSymlink,
+		common.Hotkeys.PasteAsRelativeSymlink,
+		common.Hotkeys.PasteAsHardlink,
+		common.Hotkeys.CutItems,
+		common.Hotkeys.MoveToNextPanel,
+		common.Hotkeys.DeleteItems,
+		common.Hotkeys.PermanentlyDeleteItems,
+		common.Hotkeys.FilePanelItemCreate,
+		common.Hotkeys.FilePanelItemRename,
+		common.Hotkeys.BulkRename,
+		common.Hotkeys.PatternRename,
+		common.Hotkeys.CompressFile,
+		common.Hotkeys.OpenFileWithEditor,
+		common.Hotkeys.OpenCurrentDirectoryWithEditor,
+	}
+	for _, keys := range mutatingKeys {
+		if slices.Contains(keys, msg) {
+			m.notifyModel = notify.New(true, common.ArchiveReadOnlyTitle, common.ArchiveReadOnlyContent,
+				notify.NoAction)
+			return true
+		}
+	}
+	return false
+}
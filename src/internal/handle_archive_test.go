@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/archivefs"
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func TestBrowseArchive(t *testing.T) {
+	oldBrowseArchives := common.Config.BrowseArchives
+	common.Config.BrowseArchives = true
+	t.Cleanup(func() {
+		common.Config.BrowseArchives = oldBrowseArchives
+	})
+	curTestDir := t.TempDir()
+	srcDir := filepath.Join(curTestDir, "src")
+	destDir := filepath.Join(curTestDir, "dest")
+	utils.SetupDirectories(t, srcDir, filepath.Join(srcDir, "sub"), destDir)
+	utils.SetupFilesWithData(t, []byte("a"), filepath.Join(srcDir, "a.txt"))
+	utils.SetupFilesWithData(t, []byte("b"), filepath.Join(srcDir, "sub", "b.txt"))
+
+	m := defaultTestModel(curTestDir)
+
+	archive := filepath.Join(curTestDir, "src.tar.gz")
+	require.NoError(t, compressSources([]string{srcDir}, archive,
+		compressOptions{format: archiveTarGz, level: defaultCompressLevel}, &m.processBarModel))
+	root := archivefs.Root(archive)
+	TeaUpdate(m, nil)
+	panel := m.getFocusedFilePanel()
+
+	t.Run("Enter the archive and its directories", func(t *testing.T) {
+		setFilePanelSelectedItemByLocation(t, panel, archive)
+		TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.Confirm[0]))
+		assert.Equal(t, root, panel.location)
+		setFilePanelSelectedItemByName(t, panel, "src")
+		TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.Confirm[0]))
+		assert.Equal(t, root+"src", panel.location)
+		assert.Len(t, panel.element, 2)
+		assert.Equal(t, root+"src/sub", panel.element[0].location)
+		assert.True(t, panel.element[0].directory)
+	})
+
+	t.Run("Archives are read-only", func(t *testing.T) {
+		setFilePanelSelectedItemByName(t, panel, "a.txt")
+		TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.DeleteItems[0]))
+		assert.True(t, m.notifyModel.IsOpen())
+		m.notifyModel.Close()
+
+		require.Error(t, validatePasteOperation(root, []string{filepath.Join(srcDir, "a.txt")}, false))
+		require.Error(t, validatePasteOperation(destDir, []string{root + "src/a.txt"}, true))
+	})
+
+	t.Run("Copy members out of the archive", func(t *testing.T) {
+		m.copySingleItem(false)
+		setFilePanelSelectedItemByName(t, panel, "sub")
+		m.copyItems.items = append(m.copyItems.items, root+"src/sub")
+		require.NoError(t, m.updateCurrentFilePanelDir(destDir))
+
+		cmd := m.getPasteItemCmd()
+		require.NotNil(t, cmd)
+		msg, ok := cmd().(PasteOperationMsg)
+		require.True(t, ok)
+		assert.Equal(t, processbar.Successful, msg.state)
+		for file, data := range map[string]string{
+			filepath.Join(destDir, "a.txt"):        "a",
+			filepath.Join(destDir, "sub", "b.txt"): "b",
+		} {
+			content, err := os.ReadFile(file)
+			require.NoError(t, err)
+			assert.Equal(t, data, string(content))
+		}
+	})
+
+	t.Run("Leave the archive", func(t *testing.T) {
+		require.NoError(t, m.updateCurrentFilePanelDir(root+"src"))
+		m.parentDirectory()
+		assert.Equal(t, root, panel.location)
+		m.parentDirectory()
+		assert.Equal(t, curTestDir, panel.location)
+	})
+}
+
+func TestPasteEncryptedArchiveMember(t *testing.T) {
+	curTestDir := t.TempDir()
+	srcDir := filepath.Join(curTestDir, "secret")
+	destDir := filepath.Join(curTestDir, "dest")
+	utils.SetupDirectories(t, srcDir, destDir)
+	utils.SetupFilesWithData(t, []byte("secret"), filepath.Join(srcDir, "secret.txt"))
+
+	m := defaultTestModel(destDir)
+	TeaUpdate(m, nil)
+
+	archive := filepath.Join(curTestDir, "secret.zip")
+	require.NoError(t, compressSources([]string{srcDir}, archive, compressOptions{
+		format: archiveZip, level: defaultCompressLevel, password: "hunter2",
+	}, &m.processBarModel))
+	m.copyItems.items = []string{archivefs.Root(archive) + "secret/secret.txt"}
+	p := &m.passwordModal
+
+	paste := func(password string) tea.Msg {
+		p.textInput.SetValue(password)
+		cmd := m.passwordModalKey(common.Hotkeys.ConfirmTyping[0])
+		require.NotNil(t, cmd)
+		return cmd()
+	}
+
+	msg, ok := m.getPasteItemCmd()().(PastePasswordMsg)
+	require.True(t, ok, "The paste should ask for the password before starting")
+	msg.ApplyToModel(m)
+	require.True(t, p.open)
+	assert.Equal(t, "Password of secret.zip", p.title)
+	assert.Empty(t, p.errorMessage)
+
+	msg, ok = paste("wrong").(PastePasswordMsg)
+	require.True(t, ok)
+	msg.ApplyToModel(m)
+	require.True(t, p.open)
+	assert.Equal(t, "Wrong password", p.errorMessage)
+
+	result, ok := paste("hunter2").(PasteOperationMsg)
+	require.True(t, ok)
+	assert.False(t, p.open)
+	assert.Equal(t, processbar.Successful, result.state)
+	assertFileContent(t, filepath.Join(destDir, "secret.txt"), "secret")
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/notify"
+)
+
+// Write the names of the selected items to a temporary file, and open it in the editor.
+// The edited names are applied once the editor exits, and the plan is confirmed
+func (m *model) getBulkRenameCmd() tea.Cmd {
+	panel := m.getFocusedFilePanel()
+	if len(panel.selected) == 0 {
+		return nil
+	}
+	items := slices.Clone(panel.selected)
+	reqID := m.ioReqCnt
+	m.ioReqCnt++
+	slog.Debug("Submitting bulk rename request", "id", reqID, "items cnt", len(items))
+
+	tmpPath, err := writeBulkRenameFile(items)
+	if err != nil {
+		slog.Error("Could not write the names for bulk rename", "error", err)
+		return func() tea.Msg {
+			return NewNotifyModalMsg(notify.New(true, common.BulkRenameFailedTitle, err.Error(),
+				notify.NoAction), reqID)
+		}
+	}
+	return tea.ExecProcess(getFileEditorCmd(tmpPath), func(err error) tea.Msg {
+		return NewBulkRenameEditedMsg(items, tmpPath, err, reqID)
+	})
+}
+
+func writeBulkRenameFile(items []string) (string, error) {
+	var b strings.Builder
+	for _, item := range items {
+		name := filepath.Base(item)
+		if strings.ContainsAny(name, "\r\n") {
+			return "", fmt.Errorf("%q has a line break in its name, and cannot be renamed in bulk", name)
+		}
+		b.WriteString(name + "\n")
+	}
+	f, err := os.CreateTemp("", "spf-bulk-rename-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err = f.WriteString(b.String()); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// readBulkRenameFile returns the names in the edited file, one per line
+func readBulkRenameFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if content == "" {
+		return nil, errors.New("all the names were removed")
+	}
+	return strings.Split(content, "\n"), nil
+}
+
+// openRenamePlan shows the renames for confirmation, or an error if they cannot be done
+func (m *model) openRenamePlan(items []string, newNames []string) {
+	ops, err := buildRenamePlan(items, newNames)
+	if err != nil {
+		slog.Error("Invalid bulk rename", "error", err)
+		m.notifyModel = notify.New(true, common.BulkRenameFailedTitle, err.Error(), notify.NoAction)
+		return
+	}
+	if len(ops) == 0 {
+		slog.Debug("Bulk rename did not change any names")
+		return
+	}
+	m.renamePlanModal.open = true
+	m.renamePlanModal.ops = ops
+	m.renamePlanModal.renderIndex = 0
+}
+
+// Handles key inputs while the rename plan is shown
+func (m *model) renamePlanKey(msg string) tea.Cmd {
+	switch {
+	case slices.Contains(common.Hotkeys.Quit, msg), slices.Contains(common.Hotkeys.CancelTyping, msg):
+		m.renamePlanModal.close()
+	case slices.Contains(common.Hotkeys.ListUp, msg):
+		m.renamePlanModal.scroll(-1)
+	case slices.Contains(common.Hotkeys.ListDown, msg):
+		m.renamePlanModal.scroll(1)
+	case slices.Contains(common.Hotkeys.Confirm, msg):
+		return m.confirmRenamePlan()
+	}
+	return nil
+}
+
+// Apply the renames of the plan in the background, the same way as the pattern rename
+func (m *model) confirmRenamePlan() tea.Cmd {
+	ops := m.renamePlanModal.ops
+	m.renamePlanModal.close()
+	m.getFocusedFilePanel().resetSelected()
+
+	reqID := m.ioReqCnt
+	m.ioReqCnt++
+	slog.Debug("Submitting bulk rename plan", "id", reqID, "ops cnt", len(ops))
+	return func() tea.Msg {
+		state := executeRenameOperation(&m.processBarModel, ops, m.journal)
+		return NewRenameOperationMsg(state, reqID)
+	}
+}
+
+func (r *renamePlanModal) close() {
+	r.open = false
+	r.ops = nil
+	r.renderIndex = 0
+}
+
+func (r *renamePlanModal) visibleOpCnt() int {
+	// Borders, title line, hint line, and the two dividers
+	return max(r.height-6, 1)
+}
+
+func (r *renamePlanModal) scroll(delta int) {
+	r.renderIndex = max(min(r.renderIndex+delta, len(r.ops)-r.visibleOpCnt()), 0)
+}
@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yorukot/superfile/src/internal/common"
+)
+
+const (
+	compressModalWidth = 50
+	// Borders, the item count, the five formats, the level, the encryption, the hint line
+	// and the three dividers
+	compressModalHeight = 14
+)
+
+// The format and level last chosen in the compress modal
+type compressChoice struct {
+	Format archiveFormat `json:"format"`
+	Level  int           `json:"level"`
+}
+
+// newCompressModal returns the compress modal, with the choice remembered in choiceFile
+// selected. An empty choiceFile does not remember the choice
+func newCompressModal(choiceFile string) compressModal {
+	c := compressModal{
+		level:      defaultCompressLevel,
+		choiceFile: choiceFile,
+	}
+	if choiceFile == "" {
+		return c
+	}
+	data, err := os.ReadFile(choiceFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Error("Error while reading the compress choice", "error", err)
+		}
+		return c
+	}
+	var choice compressChoice
+	if err = json.Unmarshal(data, &choice); err != nil {
+		slog.Error("Error while parsing the compress choice", "error", err)
+		return c
+	}
+	if idx := slices.Index(archiveFormats, choice.Format); idx != -1 {
+		c.cursor = idx
+	}
+	if choice.Level >= minCompressLevel && choice.Level <= maxCompressLevel {
+		c.level = choice.Level
+	}
+	return c
+}
+
+func (c *compressModal) getOptions() compressOptions {
+	return compressOptions{format: archiveFormats[c.cursor], level: c.level}
+}
+
+func (c *compressModal) saveChoice() {
+	if c.choiceFile == "" {
+		return
+	}
+	data, err := json.Marshal(compressChoice{Format: archiveFormats[c.cursor], Level: c.level})
+	if err == nil {
+		err = os.WriteFile(c.choiceFile, data, 0o644)
+	}
+	if err != nil {
+		slog.Error("Error while saving the compress choice", "error", err)
+	}
+}
+
+func (c *compressModal) close() {
+	c.open = false
+	c.items = nil
+	c.location = ""
+	c.encrypt = false
+}
+
+// Only zip archives can be encrypted
+func (c *compressModal) canEncrypt() bool {
+	return archiveFormats[c.cursor] == archiveZip
+}
+
+// Handles key inputs while the compress modal is open. Digits set the compression level, and
+// next input toggles the encryption of zip archives
+func (m *model) compressModalKey(msg string) tea.Cmd {
+	c := &m.compressModal
+	switch {
+	case slices.Contains(common.Hotkeys.Quit, msg), slices.Contains(common.Hotkeys.CancelTyping, msg):
+		c.close()
+	case slices.Contains(common.Hotkeys.ListUp, msg):
+		c.cursor = (c.cursor + len(archiveFormats) - 1) % len(archiveFormats)
+	case slices.Contains(common.Hotkeys.ListDown, msg):
+		c.cursor = (c.cursor + 1) % len(archiveFormats)
+	case slices.Contains(common.Hotkeys.NextInput, msg):
+		c.encrypt = !c.encrypt
+	case slices.Contains(common.Hotkeys.Confirm, msg):
+		items, location, opts := c.items, c.location, c.getOptions()
+		encrypt := c.encrypt && c.canEncrypt()
+		c.saveChoice()
+		c.close()
+		if encrypt {
+			m.openCompressPasswordModal(pendingCompress{items: items, location: location, opts: opts})
+			return nil
+		}
+		return m.getCompressCmd(items, location, opts)
+	default:
+		if level, err := strconv.Atoi(msg); err == nil && level >= minCompressLevel && level <= maxCompressLevel {
+			c.level = level
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	variable "github.com/yorukot/superfile/src/config"
+	"github.com/yorukot/superfile/src/config/icon"
+	"github.com/yorukot/superfile/src/internal/common"
+	contentsearchui "github.com/yorukot/superfile/src/internal/ui/contentsearch"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+)
+
+// Open the content search modal, to search under the directory of the focused panel
+func (m *model) openContentSearch() {
+	m.contentSearchModal.Open(m.getFocusedFilePanel().location)
+	m.ignoreOpeningKey()
+}
+
+// Handles key inputs while the content search modal is open. Other keys are typed in the
+// query, via updateFilePanelsState
+func (m *model) contentSearchModalKey(msg tea.KeyMsg) tea.Cmd {
+	s := &m.contentSearchModal
+	key := msg.String()
+	switch {
+	case slices.Contains(common.Hotkeys.CancelTyping, key):
+		s.Close()
+	case slices.Contains(common.Hotkeys.ConfirmTyping, key):
+		if s.NeedsSearch() {
+			return m.getContentSearchCmd()
+		}
+		return m.openContentSearchMatch()
+	case slices.Contains(common.Hotkeys.SelectContentSearchFile, key):
+		m.selectContentSearchFile()
+	case slices.Contains(common.Hotkeys.ToggleContentSearchRegex, key):
+		s.ToggleRegex()
+	// Letters are typed in the query, and are not used for scrolling
+	case slices.Contains(common.Hotkeys.ListUp, key) && msg.Type != tea.KeyRunes:
+		s.ListUp()
+	case slices.Contains(common.Hotkeys.ListDown, key) && msg.Type != tea.KeyRunes:
+		s.ListDown()
+	}
+	return nil
+}
+
+// Starts a search of the query of the modal, as a process. Its matches are received by
+// getContentSearchBatchCmd
+func (m *model) getContentSearchCmd() tea.Cmd {
+	search := m.contentSearchModal.StartSearch(m.toggleDotFile)
+	if search == nil {
+		return nil
+	}
+	slog.Debug("Starting content search", "location", search.Location(), "query", search.Query().Text)
+	processBar := &m.processBarModel
+	return tea.Batch(func() tea.Msg {
+		runContentSearch(search, processBar)
+		return nil
+	}, m.getContentSearchBatchCmd(search))
+}
+
+func (m *model) getContentSearchBatchCmd(search *contentsearchui.Search) tea.Cmd {
+	reqID := m.ioReqCnt
+	m.ioReqCnt++
+	return func() tea.Msg {
+		batch, ok := search.Next()
+		if !ok {
+			return nil
+		}
+		return NewContentSearchMsg(search, batch, reqID)
+	}
+}
+
+// Shows the files searched by a content search as the progress of its process
+type contentSearchProgress struct {
+	p          *processbar.Process
+	processBar *processbar.Model
+}
+
+func (c *contentSearchProgress) SetTotal(total int) {
+	c.p.Total = total
+	c.processBar.TrySendingUpdateProcessMsg(*c.p)
+}
+
+func (c *contentSearchProgress) FileSearched() {
+	c.p.Done++
+	c.processBar.TrySendingThrottledUpdateProcessMsg(c.p)
+}
+
+func (c *contentSearchProgress) Checkpoint() error {
+	return c.p.Checkpoint()
+}
+
+// Runs the search as a process, until it is done or cancelled
+func runContentSearch(search *contentsearchui.Search, processBar *processbar.Model) {
+	p, err := processBar.SendAddProcessMsg(icon.Search+icon.Space+search.Query().Text, 0, true)
+	if err != nil {
+		slog.Error("Cannot spawn content search process", "error", err)
+		search.Cancel()
+		return
+	}
+	p.Operation = operationContentSearch
+	p.Sources = []string{search.Location()}
+
+	err = search.Run(&contentSearchProgress{p: &p, processBar: processBar})
+	switch {
+	case errors.Is(err, contentsearchui.ErrCancelled):
+		p.State = processbar.Cancelled
+	case err != nil:
+		p.SetFailed(err)
+	default:
+		p.State = processbar.Successful
+		p.Done = p.Total
+	}
+	p.DoneTime = time.Now()
+	if pSendErr := processBar.SendUpdateProcessMsg(p, true); pSendErr != nil {
+		slog.Error("Error sending process update", "error", pSendErr)
+	}
+}
+
+// Open the file of the match under the cursor in the editor, at the line of the match
+func (m *model) openContentSearchMatch() tea.Cmd {
+	match, ok := m.contentSearchModal.GetSelectedMatch()
+	if !ok {
+		return nil
+	}
+	if variable.ChooserFile != "" {
+		err := m.chooserFileWriteAndQuit(match.Path)
+		if err == nil {
+			return nil
+		}
+		slog.Error("Error while writing to chooser file, continuing with open via file editor", "error", err)
+	}
+	return tea.ExecProcess(getFileEditorAtLineCmd(match.Path, match.Line), func(err error) tea.Msg {
+		return editorFinishedMsg{err}
+	})
+}
+
+// Close the modal, and move the cursor of the focused panel to the file of the match under
+// the cursor
+func (m *model) selectContentSearchFile() {
+	match, ok := m.contentSearchModal.GetSelectedMatch()
+	if !ok {
+		return
+	}
+	m.contentSearchModal.Close()
+	panel := m.getFocusedFilePanel()
+	// The file may not be shown by the tree or flat view
+	if panel.panelMode == treeMode || panel.panelMode == flatMode {
+		panel.resetSelected()
+		panel.panelMode = browserMode
+	}
+	panel.searchBar.SetValue("")
+	if err := m.updateCurrentFilePanelDir(filepath.Dir(match.Path)); err != nil {
+		slog.Error("Error while changing to directory", "error", err, "target", match.Path)
+		return
+	}
+	m.listFlatPanels()
+	panel.readElements(m.toggleDotFile)
+	panel.moveCursorToLocation(match.Path, m.mainPanelHeight)
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func TestContentSearch(t *testing.T) {
+	processBar := processbar.New()
+	history := processbar.NewHistory("")
+	processBar.SetHistory(history)
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+
+	curTestDir := t.TempDir()
+	subDir := filepath.Join(curTestDir, "sub")
+	utils.SetupDirectories(t, subDir)
+	file1 := filepath.Join(subDir, "file1.txt")
+	require.NoError(t, os.WriteFile(file1, []byte("first\nneedle here\n"), 0o644))
+	utils.SetupFiles(t, filepath.Join(curTestDir, "file2.txt"))
+
+	m := defaultTestModel(curTestDir)
+	m.processBarModel = processBar
+	TeaUpdate(m, nil)
+	s := &m.contentSearchModal
+
+	TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.OpenContentSearch[0]))
+	require.True(t, s.IsOpen())
+	TeaUpdate(m, utils.TeaRuneKeyMsg("needle"))
+	require.Equal(t, "needle", s.GetQuery().Text)
+
+	// Run the search, and apply its batches until it is done
+	msg := ExecuteTeaCmdWithTimeout(m.getContentSearchCmd(), DefaultTestTimeout)
+	cmds, ok := msg.(tea.BatchMsg)
+	require.True(t, ok)
+	require.Len(t, cmds, 2)
+	go cmds[0]()
+	batchCmd := cmds[1]
+	for batchCmd != nil {
+		msg = ExecuteTeaCmdWithTimeout(batchCmd, DefaultTestTimeout)
+		require.NotNil(t, msg, "The search did not finish in time")
+		batchCmd = msg.(ContentSearchMsg).ApplyToModel(m)
+	}
+	assert.False(t, s.IsSearching())
+	require.Eventually(t, func() bool {
+		return len(history.Entries()) == 1
+	}, DefaultTestTimeout, DefaultTestTick)
+	assert.Equal(t, operationContentSearch, history.Entries()[0].Operation)
+	match, ok := s.GetSelectedMatch()
+	require.True(t, ok)
+	assert.Equal(t, file1, match.Path)
+	assert.Equal(t, 2, match.Line)
+	assert.Equal(t, "needle here", match.Text)
+	assert.Contains(t, m.View(), "needle here")
+
+	TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.SelectContentSearchFile[0]))
+	assert.False(t, s.IsOpen())
+	panel := m.getFocusedFilePanel()
+	assert.Equal(t, subDir, panel.location)
+	assert.Equal(t, file1, panel.getSelectedItem().location)
+}
+
+func TestGetEditorFileArgs(t *testing.T) {
+	path := "/tmp/file.txt"
+	assert.Equal(t, []string{path}, getEditorFileArgs("nvim", path, 0))
+	assert.Equal(t, []string{"+3", path}, getEditorFileArgs("nvim", path, 3))
+	assert.Equal(t, []string{path + ":3"}, getEditorFileArgs("hx", path, 3))
+	assert.Equal(t, []string{"--goto", path + ":3"}, getEditorFileArgs("code", path, 3))
+	assert.Equal(t, []string{path}, getEditorFileArgs("unknown-editor", path, 3))
+}
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yorukot/superfile/src/internal/archivefs"
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+const (
+	extractModalWidth = 60
+	// Borders, the summary, the destination label and input, the policy, the hint line
+	// and the three dividers
+	extractModalHeight = 10
+)
+
+// The policies offered for the items that already exist at the destination, in the order
+// they are cycled through
+var extractPolicies = []pasteConflictPolicy{ //nolint: gochecknoglobals // This is effectively const.
+	conflictSkip, conflictOverwrite, conflictKeepBoth,
+}
+
+// Open the extract modal. Outside of archives, the archive under the cursor is extracted as
+// a whole. While browsing inside an archive, the selected members are extracted, or the one
+// under the cursor
+func (m *model) openExtractModal() {
+	panel := m.getFocusedFilePanel()
+	if len(panel.element) == 0 {
+		return
+	}
+	e := &m.extractModal
+	if archive, _, ok := archivefs.SplitPath(panel.location); ok {
+		e.archive = archive
+		e.members = slices.Clone(panel.selected)
+		if len(e.members) == 0 {
+			e.members = []string{panel.getSelectedItem().location}
+		}
+	} else {
+		item := panel.getSelectedItem().location
+		ext := strings.ToLower(filepath.Ext(item))
+		if !common.IsExtensionExtractable(ext) {
+			slog.Error("Error unexpected file", "extension type", ext, "item", item, "error", errors.ErrUnsupported)
+			return
+		}
+		e.archive = item
+		e.members = nil
+	}
+	e.dest = common.GeneratePatternRenameTextInput(extractModalWidth-4, "Directory to extract to")
+	e.dest.SetValue(m.getDefaultExtractDest(e.archive, len(e.members) == 0))
+	e.dest.CursorEnd()
+	e.dest.Focus()
+	e.policy = extractPolicies[0]
+	e.destErr = ""
+	e.open = true
+	m.ignoreOpeningKey()
+}
+
+// getDefaultExtractDest returns the location of the next file panel that is not inside an
+// archive. Without one, a whole archive is extracted to a new directory named after it, and
+// members are extracted next to the archive
+func (m *model) getDefaultExtractDest(archive string, whole bool) string {
+	panels := m.fileModel.filePanels
+	for offset := 1; offset < len(panels); offset++ {
+		location := panels[(m.filePanelFocusIndex+offset)%len(panels)].location
+		if !archivefs.IsArchivePath(location) {
+			return location
+		}
+	}
+	if !whole {
+		return filepath.Dir(archive)
+	}
+	dest, err := renameIfDuplicate(common.FileNameWithoutExtension(archive))
+	if err != nil {
+		slog.Error("Error while renaming for duplicates", "error", err)
+		return filepath.Dir(archive)
+	}
+	return dest
+}
+
+func (e *extractModal) close() {
+	e.open = false
+	e.archive = ""
+	e.members = nil
+	e.destErr = ""
+}
+
+// Handles key inputs while the extract modal is open. Other keys are typed in the
+// destination, via updateFilePanelsState
+func (m *model) extractModalKey(msg string) tea.Cmd {
+	e := &m.extractModal
+	switch {
+	case slices.Contains(common.Hotkeys.CancelTyping, msg):
+		e.close()
+	case slices.Contains(common.Hotkeys.NextInput, msg):
+		idx := slices.Index(extractPolicies, e.policy)
+		e.policy = extractPolicies[(idx+1)%len(extractPolicies)]
+	case slices.Contains(common.Hotkeys.ConfirmTyping, msg):
+		dest, err := e.getDest()
+		if err != nil {
+			e.destErr = err.Error()
+			return nil
+		}
+		archive, opts := e.archive, extractOptions{members: e.members, dest: dest, policy: e.policy}
+		e.close()
+		return m.getExtractCmd(archive, opts)
+	}
+	return nil
+}
+
+// getDest returns the absolute path of the destination. Relative paths are relative to the
+// directory of the archive
+func (e *extractModal) getDest() (string, error) {
+	value := strings.TrimSpace(e.dest.Value())
+	if value == "" {
+		return "", errors.New("destination is empty")
+	}
+	if archivefs.IsArchivePath(value) {
+		return "", errors.New("cannot extract into an archive")
+	}
+	dest := utils.ResolveAbsPath(filepath.Dir(e.archive), value)
+	info, err := os.Stat(dest)
+	switch {
+	case os.IsNotExist(err):
+		return dest, nil
+	case err != nil:
+		return "", err
+	case !info.IsDir():
+		return "", errors.New("destination is not a directory")
+	}
+	return dest, nil
+}
+
+// TODO : err should be returned and properly handled by the caller
+func (m *model) getExtractCmd(archive string, opts extractOptions) tea.Cmd {
+	reqID := m.ioReqCnt
+	m.ioReqCnt++
+
+	slog.Debug("Submitting Extract file request", "reqID", reqID, "item", archive,
+		"members", opts.members, "dest", opts.dest, "policy", opts.policy)
+
+	return func() tea.Msg {
+		err := extractCompressFile(archive, opts, &m.processBarModel)
+		if isArchivePasswordError(err) {
+			return NewArchivePasswordMsg(pendingExtract{archive: archive, opts: opts}, err, reqID)
+		}
+		if err != nil {
+			slog.Error("Error extract file", "error", err)
+			return NewCompressOperationMsg(processbar.Failed, reqID)
+		}
+		return NewCompressOperationMsg(processbar.Successful, reqID)
+	}
+}
@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/archivefs"
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func TestExtractModal(t *testing.T) {
+	curTestDir := t.TempDir()
+	srcDir := filepath.Join(curTestDir, "src")
+	destDir := filepath.Join(curTestDir, "dest")
+	utils.SetupDirectories(t, srcDir, destDir)
+	utils.SetupFilesWithData(t, []byte("a"), filepath.Join(srcDir, "a.txt"))
+	utils.SetupFilesWithData(t, []byte("b"), filepath.Join(srcDir, "b.txt"))
+
+	m := defaultTestModel(curTestDir, destDir)
+	archive := filepath.Join(curTestDir, "src.zip")
+	require.NoError(t, compressSources([]string{srcDir}, archive,
+		compressOptions{format: archiveZip, level: defaultCompressLevel}, &m.processBarModel))
+	root := archivefs.Root(archive)
+	TeaUpdate(m, nil)
+	panel := m.getFocusedFilePanel()
+	e := &m.extractModal
+
+	t.Run("The other panel is the default destination", func(t *testing.T) {
+		setFilePanelSelectedItemByLocation(t, panel, archive)
+		TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.ExtractFile[0]))
+		require.True(t, e.open)
+		assert.Equal(t, archive, e.archive)
+		assert.Empty(t, e.members)
+		assert.Equal(t, destDir, e.dest.Value())
+		assert.Equal(t, conflictSkip, e.policy)
+		TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.CancelTyping[0]))
+		assert.False(t, e.open)
+	})
+
+	t.Run("Invalid destinations are refused", func(t *testing.T) {
+		TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.ExtractFile[0]))
+		e.dest.SetValue(filepath.Join(srcDir, "a.txt"))
+		assert.Nil(t, m.extractModalKey(common.Hotkeys.ConfirmTyping[0]))
+		assert.True(t, e.open)
+		assert.NotEmpty(t, e.destErr)
+		e.dest.SetValue(root)
+		assert.Nil(t, m.extractModalKey(common.Hotkeys.ConfirmTyping[0]))
+		assert.True(t, e.open)
+		e.close()
+	})
+
+	t.Run("Extract the selected members", func(t *testing.T) {
+		require.NoError(t, m.updateCurrentFilePanelDir(root+"src"))
+		panel.selected = []string{root + "src/b.txt"}
+		TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.ExtractFile[0]))
+		require.True(t, e.open)
+		assert.Equal(t, archive, e.archive)
+		assert.Equal(t, []string{root + "src/b.txt"}, e.members)
+
+		utils.SetupFilesWithData(t, []byte("old"), filepath.Join(destDir, "b.txt"))
+		TeaUpdate(m, tea.KeyMsg{Type: tea.KeyTab})
+		assert.Equal(t, conflictOverwrite, e.policy)
+		cmd := m.extractModalKey(common.Hotkeys.ConfirmTyping[0])
+		require.NotNil(t, cmd)
+		assert.False(t, e.open)
+		msg, ok := cmd().(CompressOperationMsg)
+		require.True(t, ok)
+		assert.Equal(t, processbar.Successful, msg.state)
+		assertFileContent(t, filepath.Join(destDir, "b.txt"), "b")
+		assert.NoFileExists(t, filepath.Join(destDir, "a.txt"))
+	})
+
+	t.Run("Encrypted archives ask for the password", func(t *testing.T) {
+		secretDir := filepath.Join(curTestDir, "secret")
+		utils.SetupDirectories(t, secretDir)
+		utils.SetupFilesWithData(t, []byte("secret"), filepath.Join(secretDir, "secret.txt"))
+		secretArchive := filepath.Join(curTestDir, "secret.zip")
+		require.NoError(t, compressSources([]string{secretDir}, secretArchive, compressOptions{
+			format: archiveZip, level: defaultCompressLevel, password: "hunter2",
+		}, &m.processBarModel))
+		secretDest := filepath.Join(curTestDir, "secret-dest")
+		p := &m.passwordModal
+
+		extract := func(password string) tea.Msg {
+			p.textInput.SetValue(password)
+			cmd := m.passwordModalKey(common.Hotkeys.ConfirmTyping[0])
+			require.NotNil(t, cmd)
+			return cmd()
+		}
+
+		cmd := m.getExtractCmd(secretArchive, extractOptions{dest: secretDest, policy: conflictSkip})
+		msg, ok := cmd().(ArchivePasswordMsg)
+		require.True(t, ok)
+		msg.ApplyToModel(m)
+		require.True(t, p.open)
+		assert.Empty(t, p.errorMessage)
+
+		// An empty password is not submitted
+		assert.Nil(t, m.passwordModalKey(common.Hotkeys.ConfirmTyping[0]))
+		assert.True(t, p.open)
+		assert.NotEmpty(t, p.errorMessage)
+
+		msg, ok = extract("wrong").(ArchivePasswordMsg)
+		require.True(t, ok)
+		msg.ApplyToModel(m)
+		require.True(t, p.open)
+		assert.Equal(t, "Wrong password", p.errorMessage)
+
+		result, ok := extract("hunter2").(CompressOperationMsg)
+		require.True(t, ok)
+		assert.False(t, p.open)
+		assert.Equal(t, processbar.Successful, result.state)
+		assertFileContent(t, filepath.Join(secretDest, "secret", "secret.txt"), "secret")
+	})
+}
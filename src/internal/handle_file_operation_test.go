@@ -4,11 +4,13 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/notify"
 	"github.com/yorukot/superfile/src/internal/utils"
 )
 
@@ -113,6 +115,8 @@ func TestCompressSelectedFiles(t *testing.T) {
 			}
 
 			p.SendKey(common.Hotkeys.CompressFile[0])
+			// Compress in the default format, zip
+			p.SendKey(common.Hotkeys.Confirm[0])
 			zipFile := filepath.Join(tt.startDir, tt.expectedZipName)
 			// Actual compress may take time, since its an os operations
 			assert.Eventually(t, func() bool {
@@ -139,6 +143,8 @@ func TestCompressSelectedFiles(t *testing.T) {
 				"Panel location for extraction is a directory, expected a zip file: %s", selectedItemLocation)
 
 			p.SendKey(common.Hotkeys.ExtractFile[0])
+			// Extract to the default destination, a new directory next to the archive
+			p.SendKey(common.Hotkeys.ConfirmTyping[0])
 			// File extraction is supposedly async. So function's return doesn't means its done.
 			extractedDir := filepath.Join(tt.startDir, tt.extractedDirName)
 
@@ -253,9 +259,9 @@ func TestPasteItem(t *testing.T) {
 	for _, tt := range testdata {
 		t.Run(tt.name, func(t *testing.T) {
 			m := setupModelAndPerformOperation(t, tt.startDir, tt.selectMode, tt.itemName, tt.selectedItems, tt.isCut)
-			p := NewTestTeaProgWithEventLoop(t, m)
 			// Navigate to target directory
 			navigateToTargetDir(t, m, tt.startDir, tt.targetDir)
+			p := NewTestTeaProgWithEventLoop(t, m)
 
 			// Get original file path for existence check
 			originalPath := getOriginalPath(tt.selectMode, tt.itemName, tt.startDir)
@@ -311,10 +317,10 @@ func TestPasteItem(t *testing.T) {
 
 		selectedItems := []string{multiFile1, multiFile2}
 		m := setupModelAndPerformOperation(t, sourceDir, true, "", selectedItems, false)
-		p := NewTestTeaProgWithEventLoop(t, m)
 
 		// Navigate to destination
 		navigateToTargetDir(t, m, sourceDir, destDir)
+		p := NewTestTeaProgWithEventLoop(t, m)
 
 		// Paste items
 		p.SendKey(common.Hotkeys.PasteItems[0])
@@ -333,10 +339,10 @@ func TestPasteItem(t *testing.T) {
 
 		// Test the logic that prevents cutting a directory into its subdirectory
 		m := setupModelAndPerformOperation(t, sourceDir, false, "testsubdir", nil, true)
-		p := NewTestTeaProgWithEventLoop(t, m)
 
 		// Navigate into the subdirectory and try to paste there (should be prevented)
 		navigateToTargetDir(t, m, sourceDir, testSubDir)
+		p := NewTestTeaProgWithEventLoop(t, m)
 		p.SendKey(common.Hotkeys.PasteItems[0])
 
 		// Directory should still exist in original location after prevention
@@ -349,9 +355,9 @@ func TestPasteItem(t *testing.T) {
 		utils.SetupFiles(t, dupFile)
 
 		m := setupModelAndPerformOperation(t, sourceDir, false, "duplicate.txt", nil, false)
-		p := NewTestTeaProgWithEventLoop(t, m)
 		// Navigate to destination and paste
 		navigateToTargetDir(t, m, sourceDir, destDir)
+		p := NewTestTeaProgWithEventLoop(t, m)
 		p.SendKey(common.Hotkeys.PasteItems[0])
 
 		// Verify first copy
@@ -359,14 +365,219 @@ func TestPasteItem(t *testing.T) {
 
 		// Paste again to test duplicate handling
 		p.SendKey(common.Hotkeys.PasteItems[0])
+		choosePasteConflictPolicy(t, p, conflictKeepBoth, false)
 
 		// Verify duplicate file with different name
 		verifyDestinationFiles(t, destDir, []string{"duplicate(1).txt"})
 	})
 }
 
+func TestPasteConflict(t *testing.T) {
+	oldData := []byte("old")
+	newData := []byte("new data")
+	sameSizeData := []byte("abc")
+
+	testdata := []struct {
+		name            string
+		srcData         []byte
+		srcIsNewer      bool
+		isCut           bool
+		policy          pasteConflictPolicy
+		expectedData    []byte
+		expectedFiles   []string
+		shouldSrcRemain bool
+	}{
+		{
+			name:            "Overwrite",
+			srcData:         newData,
+			policy:          conflictOverwrite,
+			expectedData:    newData,
+			shouldSrcRemain: true,
+		},
+		{
+			name:            "Skip",
+			srcData:         newData,
+			policy:          conflictSkip,
+			expectedData:    oldData,
+			shouldSrcRemain: true,
+		},
+		{
+			name:            "Keep both",
+			srcData:         newData,
+			policy:          conflictKeepBoth,
+			expectedData:    oldData,
+			expectedFiles:   []string{"file(1).txt"},
+			shouldSrcRemain: true,
+		},
+		{
+			name:            "Overwrite if newer with newer source",
+			srcData:         newData,
+			srcIsNewer:      true,
+			policy:          conflictOverwriteIfNewer,
+			expectedData:    newData,
+			shouldSrcRemain: true,
+		},
+		{
+			name:            "Overwrite if newer with older source",
+			srcData:         newData,
+			policy:          conflictOverwriteIfNewer,
+			expectedData:    oldData,
+			shouldSrcRemain: true,
+		},
+		{
+			name:            "Compare sizes with different size",
+			srcData:         newData,
+			policy:          conflictCompareSizes,
+			expectedData:    newData,
+			shouldSrcRemain: true,
+		},
+		{
+			name:            "Compare sizes with same size",
+			srcData:         sameSizeData,
+			policy:          conflictCompareSizes,
+			expectedData:    oldData,
+			shouldSrcRemain: true,
+		},
+		{
+			name:            "Cut with Overwrite",
+			srcData:         newData,
+			isCut:           true,
+			policy:          conflictOverwrite,
+			expectedData:    newData,
+			shouldSrcRemain: false,
+		},
+		{
+			name:            "Cut with Skip keeps the source",
+			srcData:         newData,
+			isCut:           true,
+			policy:          conflictSkip,
+			expectedData:    oldData,
+			shouldSrcRemain: true,
+		},
+	}
+
+	for _, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			curTestDir := t.TempDir()
+			sourceDir := filepath.Join(curTestDir, "source")
+			destDir := filepath.Join(curTestDir, "dest")
+			srcFile := filepath.Join(sourceDir, "file.txt")
+			dstFile := filepath.Join(destDir, "file.txt")
+			utils.SetupDirectories(t, sourceDir, destDir)
+			utils.SetupFilesWithData(t, tt.srcData, srcFile)
+			utils.SetupFilesWithData(t, oldData, dstFile)
+
+			olderTime := time.Now().Add(-time.Hour)
+			olderFile := srcFile
+			if tt.srcIsNewer {
+				olderFile = dstFile
+			}
+			require.NoError(t, os.Chtimes(olderFile, olderTime, olderTime))
+
+			m := setupModelAndPerformOperation(t, sourceDir, false, "file.txt", nil, tt.isCut)
+			navigateToTargetDir(t, m, sourceDir, destDir)
+			p := NewTestTeaProgWithEventLoop(t, m)
+			p.SendKey(common.Hotkeys.PasteItems[0])
+			choosePasteConflictPolicy(t, p, tt.policy, false)
+
+			verifyDestinationFiles(t, destDir, tt.expectedFiles)
+			assert.Eventually(t, func() bool {
+				data, err := os.ReadFile(dstFile)
+				return err == nil && string(data) == string(tt.expectedData)
+			}, DefaultTestTimeout, DefaultTestTick, "Destination should have the expected content")
+			if tt.shouldSrcRemain {
+				assert.FileExists(t, srcFile)
+			} else {
+				verifyPathNotExistsEventually(t, srcFile, "Source should be removed after cut")
+			}
+		})
+	}
+
+	t.Run("Apply to all with directory merge", func(t *testing.T) {
+		curTestDir := t.TempDir()
+		sourceDir := filepath.Join(curTestDir, "source")
+		destDir := filepath.Join(curTestDir, "dest")
+		srcSubDir := filepath.Join(sourceDir, "dir")
+		dstSubDir := filepath.Join(destDir, "dir")
+		utils.SetupDirectories(t, sourceDir, destDir, srcSubDir, dstSubDir)
+		utils.SetupFilesWithData(t, newData, filepath.Join(sourceDir, "file.txt"),
+			filepath.Join(srcSubDir, "common.txt"), filepath.Join(srcSubDir, "src_only.txt"))
+		utils.SetupFilesWithData(t, oldData, filepath.Join(destDir, "file.txt"),
+			filepath.Join(dstSubDir, "common.txt"), filepath.Join(dstSubDir, "dst_only.txt"))
+
+		selectedItems := []string{filepath.Join(sourceDir, "file.txt"), srcSubDir}
+		m := setupModelAndPerformOperation(t, sourceDir, true, "", selectedItems, false)
+		navigateToTargetDir(t, m, sourceDir, destDir)
+		p := NewTestTeaProgWithEventLoop(t, m)
+		p.SendKey(common.Hotkeys.PasteItems[0])
+		choosePasteConflictPolicy(t, p, conflictOverwrite, true)
+
+		verifyDestinationFiles(t, dstSubDir, []string{"common.txt", "src_only.txt", "dst_only.txt"})
+		for _, file := range []string{filepath.Join(destDir, "file.txt"), filepath.Join(dstSubDir, "common.txt")} {
+			assert.Eventually(t, func() bool {
+				data, err := os.ReadFile(file)
+				return err == nil && string(data) == string(newData)
+			}, DefaultTestTimeout, DefaultTestTick, "%s should be overwritten", file)
+		}
+		p.ReadModel(func(m *model) {
+			assert.Nil(t, m.pendingPaste, "All conflicts should be resolved at once")
+		})
+	})
+
+	t.Run("Cancel aborts the paste", func(t *testing.T) {
+		curTestDir := t.TempDir()
+		sourceDir := filepath.Join(curTestDir, "source")
+		destDir := filepath.Join(curTestDir, "dest")
+		utils.SetupDirectories(t, sourceDir, destDir)
+		utils.SetupFilesWithData(t, newData, filepath.Join(sourceDir, "file.txt"))
+		utils.SetupFilesWithData(t, oldData, filepath.Join(destDir, "file.txt"))
+
+		m := setupModelAndPerformOperation(t, sourceDir, false, "file.txt", nil, false)
+		navigateToTargetDir(t, m, sourceDir, destDir)
+		p := NewTestTeaProgWithEventLoop(t, m)
+		p.SendKey(common.Hotkeys.PasteItems[0])
+		waitForPasteConflictModal(t, p)
+		p.SendKey(common.Hotkeys.Quit[0])
+
+		assert.Eventually(t, func() bool {
+			closed := false
+			p.ReadModel(func(m *model) {
+				closed = !m.notifyModel.IsOpen() && m.pendingPaste == nil
+			})
+			return closed
+		}, DefaultTestTimeout, DefaultTestTick)
+		data, err := os.ReadFile(filepath.Join(destDir, "file.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, oldData, data)
+	})
+}
+
 // ------  Very specific utilities that are required for this test case file only
 
+func waitForPasteConflictModal(t *testing.T, p *TeaProg) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		open := false
+		p.ReadModel(func(m *model) {
+			open = m.notifyModel.IsOpen() && m.notifyModel.GetConfirmAction() == notify.PasteConflictAction
+		})
+		return open
+	}, DefaultTestTimeout, DefaultTestTick, "Paste conflict dialog should open")
+}
+
+// Wait for the paste conflict dialog, and pick the choice for given policy
+func choosePasteConflictPolicy(t *testing.T, p *TeaProg, policy pasteConflictPolicy, applyToAll bool) {
+	t.Helper()
+	waitForPasteConflictModal(t, p)
+	for range int(policy) {
+		p.SendKey(common.Hotkeys.ListDown[0])
+	}
+	if applyToAll {
+		p.SendKey(common.Hotkeys.FilePanelSelectAllItem[0])
+	}
+	p.SendKey(common.Hotkeys.Confirm[0])
+}
+
 // Helper function to setup model and perform copy/cut operation
 func setupModelAndPerformOperation(t *testing.T, startDir string, useSelectMode bool,
 	itemName string, selectedItems []string, isCut bool) *model {
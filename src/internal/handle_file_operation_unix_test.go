@@ -0,0 +1,198 @@
+//go:build linux || darwin
+
+package internal
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func TestPasteSpecialFiles(t *testing.T) {
+	processBar := processbar.New()
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+
+	oldDereference := common.Config.DereferenceSymlinks
+	t.Cleanup(func() {
+		common.Config.DereferenceSymlinks = oldDereference
+	})
+
+	// Returns the source item to paste
+	setupSrc := func(t *testing.T) string {
+		srcDir := filepath.Join(t.TempDir(), "src")
+		subDir := filepath.Join(srcDir, "sub")
+		utils.SetupDirectories(t, subDir)
+		utils.SetupFilesWithData(t, []byte("data"), filepath.Join(srcDir, "file.txt"))
+		require.NoError(t, os.Symlink("file.txt", filepath.Join(srcDir, "file_link")))
+		require.NoError(t, os.Symlink("sub", filepath.Join(srcDir, "dir_link")))
+		// Points to its own parent
+		require.NoError(t, os.Symlink("..", filepath.Join(subDir, "loop_link")))
+		require.NoError(t, os.Symlink("missing", filepath.Join(srcDir, "broken_link")))
+		require.NoError(t, os.Link(filepath.Join(srcDir, "file.txt"), filepath.Join(subDir, "hardlink.txt")))
+		require.NoError(t, syscall.Mkfifo(filepath.Join(srcDir, "fifo"), 0o644))
+		return srcDir
+	}
+
+	isSymlink := func(t *testing.T, path string) bool {
+		info, err := os.Lstat(path)
+		require.NoError(t, err)
+		return info.Mode()&os.ModeSymlink != 0
+	}
+
+	testdata := []struct {
+		name        string
+		cut         bool
+		dereference bool
+	}{
+		{name: "Copy", cut: false, dereference: false},
+		{name: "Move", cut: true, dereference: false},
+		{name: "Copy with dereference", cut: false, dereference: true},
+		{name: "Move ignores dereference", cut: true, dereference: true},
+	}
+
+	for _, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			common.Config.DereferenceSymlinks = tt.dereference
+			srcDir := setupSrc(t)
+			dstDir := filepath.Join(t.TempDir(), "dst")
+			p, err := processBar.SendAddProcessMsg("paste", 0, true)
+			require.NoError(t, err)
+
+			// Force the copy path, as isSamePartition would allow a rename for a move
+			require.NoError(t, pasteTree(srcDir, dstDir, false, conflictKeepBoth,
+				newPasteContext(&p, &processBar, tt.cut)))
+
+			dereferenced := tt.dereference && !tt.cut
+			assert.Equal(t, !dereferenced, isSymlink(t, filepath.Join(dstDir, "file_link")))
+			assert.Equal(t, !dereferenced, isSymlink(t, filepath.Join(dstDir, "dir_link")))
+			assert.True(t, isSymlink(t, filepath.Join(dstDir, "broken_link")), "Broken symlinks stay symlinks")
+			assert.True(t, isSymlink(t, filepath.Join(dstDir, "sub", "loop_link")))
+			if dereferenced {
+				assert.FileExists(t, filepath.Join(dstDir, "dir_link", "hardlink.txt"))
+			} else {
+				target, err := os.Readlink(filepath.Join(dstDir, "file_link"))
+				require.NoError(t, err)
+				assert.Equal(t, "file.txt", target)
+			}
+
+			fileInfo, err := os.Stat(filepath.Join(dstDir, "file.txt"))
+			require.NoError(t, err)
+			hardlinkInfo, err := os.Stat(filepath.Join(dstDir, "sub", "hardlink.txt"))
+			require.NoError(t, err)
+			assert.True(t, os.SameFile(fileInfo, hardlinkInfo), "Hardlinks should stay hardlinked")
+
+			fifoInfo, err := os.Lstat(filepath.Join(dstDir, "fifo"))
+			require.NoError(t, err)
+			assert.NotZero(t, fifoInfo.Mode()&os.ModeNamedPipe, "FIFO should be recreated")
+
+			if tt.cut {
+				assert.NoFileExists(t, filepath.Join(srcDir, "file.txt"))
+				assert.NoFileExists(t, filepath.Join(srcDir, "fifo"))
+			}
+		})
+	}
+}
+
+func TestCopySkipsSockets(t *testing.T) {
+	// Unix socket paths have a short length limit, so not using t.TempDir()
+	curTestDir, err := os.MkdirTemp("", "spf")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(curTestDir)
+	})
+	socketPath := filepath.Join(curTestDir, "sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		listener.Close()
+	})
+
+	info, err := os.Lstat(socketPath)
+	require.NoError(t, err)
+	p := processbar.NewProcess("1", "copy", 1)
+	pasted, err := copyEntry(socketPath, filepath.Join(curTestDir, "sock_copy"), info, nil, &p, nil)
+	require.NoError(t, err)
+	assert.False(t, pasted)
+	assert.Len(t, p.Warnings, 1)
+	assert.NoFileExists(t, filepath.Join(curTestDir, "sock_copy"))
+}
+
+func TestCopySparseFile(t *testing.T) {
+	curTestDir := t.TempDir()
+	src := filepath.Join(curTestDir, "sparse")
+	dst := filepath.Join(curTestDir, "sparse_copy")
+	const size = 16 * 1024 * 1024
+
+	f, err := os.Create(src)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte("start"), 0)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte("middle"), size/2)
+	require.NoError(t, err)
+	require.NoError(t, f.Truncate(size))
+	require.NoError(t, f.Close())
+
+	srcInfo, err := os.Stat(src)
+	require.NoError(t, err)
+	if !isSparse(srcInfo) {
+		t.Skip("Filesystem does not support sparse files")
+	}
+	require.NoError(t, copyFile(src, dst, srcInfo, nil, nil))
+
+	dstInfo, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.Equal(t, srcInfo.Size(), dstInfo.Size())
+	assert.True(t, isSparse(dstInfo), "Holes should not be filled in the copy")
+	data := make([]byte, 6)
+	dstFile, err := os.Open(dst)
+	require.NoError(t, err)
+	defer dstFile.Close()
+	_, err = dstFile.ReadAt(data, size/2)
+	require.NoError(t, err)
+	assert.Equal(t, "middle", string(data))
+}
+
+func TestProcessDevice(t *testing.T) {
+	curTestDir := t.TempDir()
+	dstDir := filepath.Join(curTestDir, "dst")
+	utils.SetupDirectories(t, dstDir)
+	assert.Equal(t, getProcessDevice(curTestDir), getProcessDevice(dstDir))
+	assert.Equal(t, getProcessDevice(dstDir), getProcessDevice(filepath.Join(dstDir, "new", "file.zip")),
+		"A new path is on the device of its parent")
+
+	// tmpfs, which is another device than the temp directory on most Linux systems
+	otherDir, err := os.MkdirTemp("/dev/shm", "spf_test")
+	if err != nil {
+		t.Skip("/dev/shm is not available")
+	}
+	t.Cleanup(func() { os.RemoveAll(otherDir) })
+	if getProcessDevice(otherDir) == getProcessDevice(dstDir) {
+		t.Skip("/dev/shm is on the same device as the temp directory")
+	}
+
+	processBar := processbar.New()
+	processBar.SetQueue(processbar.NewQueue(1))
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+	file1 := filepath.Join(curTestDir, "file1.txt")
+	utils.SetupFiles(t, file1)
+
+	// A process running on the device of dstDir does not hold back a paste to another device
+	running, err := processBar.SendAddProcessMsg("running", 1, true)
+	require.NoError(t, err)
+	release := processBar.WaitForTurn(&running, getProcessDevice(dstDir))
+	t.Cleanup(release)
+	assert.Equal(t, processbar.Successful,
+		executePasteOperation(&processBar, otherDir, []string{file1}, false, nil, "", nil))
+	assert.FileExists(t, filepath.Join(otherDir, "file1.txt"))
+}
@@ -8,10 +8,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	variable "github.com/yorukot/superfile/src/config"
+	"github.com/yorukot/superfile/src/internal/archivefs"
+	"github.com/yorukot/superfile/src/internal/journal"
 	"github.com/yorukot/superfile/src/internal/ui/notify"
 	"github.com/yorukot/superfile/src/internal/ui/processbar"
 	"github.com/yorukot/superfile/src/internal/utils"
@@ -47,7 +51,7 @@ func (m *model) IsRenamingConflicting() bool {
 	}
 
 	oldPath := panel.element[panel.cursor].location
-	newPath := filepath.Join(panel.location, panel.rename.Value())
+	newPath := filepath.Join(filepath.Dir(oldPath), panel.rename.Value())
 
 	if oldPath == newPath {
 		return false
@@ -82,7 +86,9 @@ func (m *model) panelItemRename() {
 	}
 
 	cursorPos := -1
-	nameRunes := []rune(panel.element[panel.cursor].name)
+	// The name of the elements of the flat view is their relative path
+	name := filepath.Base(panel.element[panel.cursor].location)
+	nameRunes := []rune(name)
 	nameLen := len(nameRunes)
 	for i := nameLen - 1; i >= 0; i-- {
 		if nameRunes[i] == '.' {
@@ -97,7 +103,7 @@ func (m *model) panelItemRename() {
 	m.fileModel.renaming = true
 	panel.renaming = true
 	m.firstTextInput = true
-	panel.rename = common.GenerateRenameTextInput(m.fileModel.width-4, cursorPos, panel.element[panel.cursor].name)
+	panel.rename = common.GenerateRenameTextInput(m.fileModel.width-4, cursorPos, name)
 }
 
 func (m *model) getDeleteCmd(permDelete bool) tea.Cmd {
@@ -107,24 +113,28 @@ func (m *model) getDeleteCmd(permDelete bool) tea.Cmd {
 	}
 
 	var items []string
-	if panel.panelMode == selectMode {
+	if panel.usesSelection() {
 		items = panel.selected
 	} else {
 		items = []string{panel.getSelectedItem().location}
 	}
 
 	useTrash := m.hasTrash && !isExternalDiskPath(panel.location) && !permDelete
+	return m.getDeleteItemsCmd(items, useTrash)
+}
 
+func (m *model) getDeleteItemsCmd(items []string, useTrash bool) tea.Cmd {
 	reqID := m.ioReqCnt
 	m.ioReqCnt++
 	slog.Debug("Submitting delete request", "id", reqID, "items cnt", len(items))
 	return func() tea.Msg {
-		state := deleteOperation(&m.processBarModel, items, useTrash)
+		state := deleteOperation(&m.processBarModel, items, useTrash, m.journal)
 		return NewDeleteOperationMsg(state, reqID)
 	}
 }
 
-func deleteOperation(processBarModel *processbar.Model, items []string, useTrash bool) processbar.ProcessState {
+func deleteOperation(processBarModel *processbar.Model, items []string, useTrash bool,
+	j *journal.Journal) processbar.ProcessState {
 	if len(items) == 0 {
 		return processbar.Cancelled
 	}
@@ -133,24 +143,46 @@ func deleteOperation(processBarModel *processbar.Model, items []string, useTrash
 		slog.Error("Cannot spawn a new process", "error", err)
 		return processbar.Failed
 	}
+	p.Operation = operationDelete
+	if useTrash {
+		p.Operation = operationTrash
+	}
+	p.Sources = items
+	p.Retryable = true
+	release := processBarModel.WaitForTurn(&p, getProcessDevice(items[0]))
+	defer release()
 
 	deleteFunc := os.RemoveAll
 	if useTrash {
 		deleteFunc = moveToTrash
 	}
+	// Trashed items can be restored, so they are recorded in the journal
+	var trashedItems []journal.Item
 	for _, item := range items {
-		err = deleteFunc(item)
+		trashPath := ""
+		if useTrash {
+			trashPath = getTrashPath(item)
+		}
+		err = p.Checkpoint()
+		if err == nil {
+			err = deleteFunc(item)
+		}
 		if err != nil {
-			p.State = processbar.Failed
+			p.SetFailed(fmt.Errorf("%s: %w", item, err))
 			slog.Error("Error in delete operation", "item", item, "useTrash", useTrash, "error", err)
 			break
 		}
+		if trashPath != "" {
+			trashedItems = append(trashedItems, journal.NewItem(item, trashPath))
+		}
 		p.Name = icon.Delete + icon.Space + filepath.Base(item)
+		p.Completed = append(p.Completed, item)
 		p.Done++
 		processBarModel.TrySendingUpdateProcessMsg(p)
 	}
+	j.Record(journal.TrashOperation, trashedItems, nil)
 
-	if p.State != processbar.Failed {
+	if p.State == processbar.InOperation {
 		p.State = processbar.Successful
 	}
 	p.DoneTime = time.Now()
@@ -163,8 +195,8 @@ func deleteOperation(processBarModel *processbar.Model, items []string, useTrash
 
 func (m *model) getDeleteTriggerCmd(deletePermanent bool) tea.Cmd {
 	panel := m.getFocusedFilePanel()
-	if (panel.panelMode == selectMode && len(panel.selected) == 0) ||
-		(panel.panelMode == browserMode && len(panel.element) == 0) {
+	if (panel.usesSelection() && len(panel.selected) == 0) ||
+		(!panel.usesSelection() && len(panel.element) == 0) {
 		return nil
 	}
 
@@ -198,6 +230,15 @@ func (m *model) copySingleItem(cut bool) {
 	m.copyItems.items = append(m.copyItems.items, panel.element[panel.cursor].location)
 }
 
+// Copy the selected items of the tree view, or else the item under the cursor
+func (m *model) copyPanelItems(cut bool) {
+	if m.getFocusedFilePanel().usesSelection() {
+		m.copyMultipleItem(cut)
+	} else {
+		m.copySingleItem(cut)
+	}
+}
+
 // Copy all selected file or directory's paths to the clipboard
 func (m *model) copyMultipleItem(cut bool) {
 	panel := &m.fileModel.filePanels[m.filePanelFocusIndex]
@@ -211,33 +252,191 @@ func (m *model) copyMultipleItem(cut bool) {
 }
 
 func (m *model) getPasteItemCmd() tea.Cmd {
-	copyItems := m.copyItems.items
-	cut := m.copyItems.cut
+	return m.getPasteCmd(m.copyItems.items, m.getFocusedFilePanel().location, m.copyItems.cut)
+}
+
+// getPasteCmd pastes the items to panelLocation, once the conflicts with existing items are resolved
+func (m *model) getPasteCmd(copyItems []string, panelLocation string, cut bool) tea.Cmd {
 	if len(copyItems) == 0 {
 		return nil
 	}
+	return m.getPendingPasteCmd(pendingPaste{
+		panelLocation: panelLocation,
+		items:         slices.Clone(copyItems),
+		cut:           cut,
+	})
+}
 
+// getPendingPasteCmd pastes the items of pending, once the password of the encrypted archive
+// they are copied out of is given, and the conflicts with existing items are resolved
+func (m *model) getPendingPasteCmd(pending pendingPaste) tea.Cmd {
 	// TODO: Do it via m.getNewReqID()
 	// TODO: Have an IO Req Management, collecting info about pending IO Req too
 	reqID := m.ioReqCnt
 	m.ioReqCnt++
-	panelLocation := m.getFocusedFilePanel().location
+	pending.reqID = reqID
 
-	slog.Debug("Submitting pasteItems request", "id", reqID, "items cnt", len(copyItems), "dest", panelLocation)
+	slog.Debug("Submitting pasteItems request", "id", reqID, "items cnt", len(pending.items),
+		"dest", pending.panelLocation)
 	return func() tea.Msg {
-		err := validatePasteOperation(panelLocation, copyItems, cut)
+		err := validatePasteOperation(pending.panelLocation, pending.items, pending.cut)
 		if err != nil {
 			return NewNotifyModalMsg(notify.New(true, "Invalid paste location", err.Error(), notify.NoAction),
 				reqID)
 		}
-		state := executePasteOperation(&m.processBarModel, panelLocation, copyItems, cut)
+		if archive, err := checkPastePassword(pending.items, pending.password); err != nil {
+			return NewPastePasswordMsg(pending, archive, err, reqID)
+		}
+		conflicts := getPasteConflicts(pending.panelLocation, pending.items)
+		if len(conflicts) > 0 {
+			pending.conflicts = conflicts
+			pending.policies = make(map[string]pasteConflictPolicy)
+			return NewPasteConflictMsg(pending, reqID)
+		}
+		state := executePasteOperation(&m.processBarModel, pending.panelLocation, pending.items, pending.cut,
+			nil, pending.password, m.journal)
 		return NewPasteOperationMsg(state, reqID)
 	}
 }
 
+// checkPastePassword checks password against the encrypted archives the items are copied out
+// of. Returns the archive whose password is missing or wrong, with the error of zipcrypto.
+// Other errors are left to the paste to report
+func checkPastePassword(items []string, password string) (string, error) {
+	for _, item := range items {
+		if !archivefs.IsArchivePath(item) {
+			continue
+		}
+		if err := archivefs.CheckPassword(item, password); isArchivePasswordError(err) {
+			archive, _, _ := archivefs.SplitPath(item)
+			return archive, err
+		}
+	}
+	return "", nil
+}
+
+// getPasteLinkCmd creates links to the clipboard items in the current file panel, instead of
+// pasting them. Conflicts with existing items are resolved the same way as when pasting
+func (m *model) getPasteLinkCmd(kind linkKind) tea.Cmd {
+	items, panelLocation := m.copyItems.items, m.getFocusedFilePanel().location
+	if len(items) == 0 {
+		return nil
+	}
+
+	reqID := m.ioReqCnt
+	m.ioReqCnt++
+
+	slog.Debug("Submitting link request", "id", reqID, "items cnt", len(items), "dest", panelLocation,
+		"kind", kind)
+	return func() tea.Msg {
+		err := validateLinkOperation(panelLocation, items, kind)
+		if err != nil {
+			return NewNotifyModalMsg(notify.New(true, "Cannot create the links", err.Error(), notify.NoAction),
+				reqID)
+		}
+		conflicts := getPasteConflicts(panelLocation, items)
+		if len(conflicts) > 0 {
+			return NewPasteConflictMsg(pendingPaste{
+				reqID:         reqID,
+				panelLocation: panelLocation,
+				items:         slices.Clone(items),
+				link:          kind,
+				conflicts:     conflicts,
+				policies:      make(map[string]pasteConflictPolicy),
+			}, reqID)
+		}
+		state := executeLinkOperation(&m.processBarModel, panelLocation, items, kind, nil, m.journal)
+		return NewLinkOperationMsg(state, reqID)
+	}
+}
+
+// getRetryProcessCmd runs the failed process in the details view again, only for its
+// sources that were not completed. The details are closed, to show the new process
+func (m *model) getRetryProcessCmd() tea.Cmd {
+	p, ok := m.processBarModel.GetProcessInDetails()
+	if !ok || !p.CanRetry() {
+		return nil
+	}
+	items := p.PendingSources()
+	slog.Debug("Retrying process", "id", p.ID, "operation", p.Operation, "items", items)
+	m.processBarModel.CloseProcessDetails()
+	switch p.Operation {
+	case operationCopy, operationMove:
+		return m.getPasteCmd(items, p.Dest, p.Operation == operationMove)
+	case operationDelete, operationTrash:
+		return m.getDeleteItemsCmd(items, p.Operation == operationTrash)
+	default:
+		slog.Error("Cannot retry process", "id", p.ID, "operation", p.Operation)
+		return nil
+	}
+}
+
+// getPasteConflicts returns the items that already exist at the paste location.
+// Copying an item into its own directory is not a conflict, it is always duplicated
+// with a new name.
+func getPasteConflicts(panelLocation string, copyItems []string) []string {
+	var conflicts []string
+	for _, srcPath := range copyItems {
+		if filepath.Dir(srcPath) == panelLocation {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(panelLocation, filepath.Base(srcPath))); err == nil {
+			conflicts = append(conflicts, srcPath)
+		}
+	}
+	return conflicts
+}
+
+// Record the user's choice for the current conflict, and either ask about
+// the next one or start the paste once everything is resolved
+func (m *model) resolvePasteConflict(choice int, applyToAll bool) tea.Cmd {
+	pending := m.pendingPaste
+	if pending == nil {
+		slog.Error("Paste conflict resolved without a pending paste operation")
+		return nil
+	}
+
+	resolvedCnt := 1
+	if applyToAll {
+		resolvedCnt = len(pending.conflicts)
+	}
+	for _, srcPath := range pending.conflicts[:resolvedCnt] {
+		pending.policies[srcPath] = pasteConflictPolicy(choice)
+	}
+	pending.conflicts = pending.conflicts[resolvedCnt:]
+
+	if len(pending.conflicts) > 0 {
+		m.notifyModel = pending.conflictModal()
+		return nil
+	}
+
+	m.pendingPaste = nil
+	slog.Debug("Paste conflicts resolved", "id", pending.reqID, "policies", pending.policies)
+	return func() tea.Msg {
+		if pending.restore != nil {
+			state := trashOperation(&m.processBarModel, pending.restore, true, pending.policies)
+			return NewTrashOperationMsg(state, pending.reqID)
+		}
+		if pending.link != noLink {
+			state := executeLinkOperation(&m.processBarModel, pending.panelLocation, pending.items,
+				pending.link, pending.policies, m.journal)
+			return NewLinkOperationMsg(state, pending.reqID)
+		}
+		state := executePasteOperation(&m.processBarModel, pending.panelLocation, pending.items,
+			pending.cut, pending.policies, pending.password, m.journal)
+		return NewPasteOperationMsg(state, pending.reqID)
+	}
+}
+
 func validatePasteOperation(panelLocation string, copyItems []string, cut bool) error {
+	if archivefs.IsArchivePath(panelLocation) {
+		return errors.New("cannot paste into an archive, archives are read-only")
+	}
 	// Check if trying to paste into source or subdirectory for both cut and copy operations
 	for _, srcPath := range copyItems {
+		if cut && archivefs.IsArchivePath(srcPath) {
+			return errors.New("cannot move items out of an archive, archives are read-only")
+		}
 		// Check if trying to cut and paste into the same directory - this would be a no-op
 		// and could potentially cause issues, so we prevent it
 		if filepath.Dir(srcPath) == panelLocation && cut {
@@ -260,50 +459,97 @@ func validatePasteOperation(panelLocation string, copyItems []string, cut bool)
 // new func to check and return an error that will go in m.content
 // create a new error type
 
-// Paste all clipboard items
+// Paste all clipboard items. policies decides how to handle the items that
+// already exist at the paste location. Items without a policy are kept
+// alongside the existing ones with a new name. password decrypts the items copied
+// out of an encrypted archive. Moved items are recorded in the journal
 func executePasteOperation(processBarModel *processbar.Model,
-	panelLocation string, copyItems []string, cut bool,
+	panelLocation string, copyItems []string, cut bool, policies map[string]pasteConflictPolicy,
+	password string, j *journal.Journal,
 ) processbar.ProcessState {
 	slog.Debug("executePasteOperation", "items", copyItems, "cut", cut, "panel location", panelLocation)
 
+	itemSizes, totalSize := getItemsSize(copyItems)
 	p, err := processBarModel.SendAddProcessMsg(
 		icon.GetCopyOrCutIcon(cut)+icon.Space+filepath.Base(copyItems[0]),
-		getTotalFilesCnt(copyItems), true)
+		totalSize.files, true)
 	if err != nil {
 		slog.Error("Cannot spawn a new process", "error", err)
 		return processbar.Failed
 	}
-
-	for _, filePath := range copyItems {
-		errMessage := "cut item error"
-		if cut && !isExternalDiskPath(filePath) {
-			err = moveElement(filePath, filepath.Join(panelLocation, filepath.Base(filePath)))
-		} else {
+	p.TotalBytes = totalSize.bytes
+	p.Operation = operationCopy
+	if cut {
+		p.Operation = operationMove
+	}
+	p.Sources, p.Dest = copyItems, panelLocation
+	p.Retryable = true
+	release := processBarModel.WaitForTurn(&p, getProcessDevice(panelLocation))
+	defer release()
+
+	// Only moves to a new destination can be reversed. Overwritten or merged items cannot
+	var movedItems []journal.Item
+	ctx := newPasteContext(&p, processBarModel, cut)
+	ctx.verify = shouldVerifyCopy(panelLocation)
+	ctx.password = password
+	for i, filePath := range copyItems {
+		errMessage := "paste item error"
+		if cut {
+			errMessage = "cut item error"
+		}
+		dst := filepath.Join(panelLocation, filepath.Base(filePath))
+		policy, hasConflict := policies[filePath]
+		if !hasConflict {
+			policy = conflictKeepBoth
+		}
+		doneBefore, doneBytesBefore := p.Done, p.DoneBytes
+		pastedPath := ""
+		err = p.Checkpoint()
+		if err == nil {
 			// TODO : These error cases are hard to test. We have to somehow make the paste operations fail,
 			// which is time consuming and manual. We should test these with automated testcases
-			err = pasteDir(filePath, filepath.Join(panelLocation, filepath.Base(filePath)), &p, cut, processBarModel)
-			if err != nil {
-				errMessage = "paste item error"
-			} else if cut {
-				// TODO: Fix unhandled error
-				os.RemoveAll(filePath)
+			if archivefs.IsArchivePath(filePath) {
+				pastedPath, err = pasteArchiveItem(filePath, dst, policy, icon.GetCopyOrCutIcon(cut), ctx)
+			} else {
+				pastedPath, err = pasteDir(filePath, dst, policy, ctx)
 			}
 		}
 
 		p.Name = icon.GetCopyOrCutIcon(cut) + icon.Space + filepath.Base(filePath)
 		if err != nil {
-			slog.Debug("model.pasteItem - paste failure", "error", err,
-				"current item", filePath, "errMessage", errMessage)
-			p.State = processbar.Failed
-			slog.Error(errMessage, "error", err)
+			p.SetFailed(fmt.Errorf("%s: %w", filePath, err))
+			slog.Error(errMessage, "error", err, "current item", filePath, "state", p.State)
 			break
 		}
+		// Fast moves and skipped items are not tracked file by file
+		p.Done = doneBefore + itemSizes[i].files
+		p.SetDoneBytes(doneBytesBefore + itemSizes[i].bytes)
+		p.Completed = append(p.Completed, filePath)
 		processBarModel.TrySendingUpdateProcessMsg(p)
+		if cut && pastedPath != "" && policy == conflictKeepBoth {
+			movedItems = append(movedItems, journal.NewItem(filePath, pastedPath))
+		}
+	}
+	// Sources of the files that were not verified are left behind, such moves cannot be reversed
+	verified := len(ctx.copied) == 0
+	if !verified && p.State == processbar.InOperation {
+		err = verifyPaste(ctx)
+		verified = err == nil
+		if err != nil {
+			p.SetFailed(err)
+			// Items with files that were not verified are pasted again when retried
+			p.Completed = ctx.verifiedItems(p.Completed)
+			slog.Error("Verification of pasted files failed", "error", err, "state", p.State)
+		}
+	}
+	if verified {
+		j.Record(journal.MoveOperation, movedItems, nil)
 	}
 
-	if p.State != processbar.Failed {
+	if p.State == processbar.InOperation {
 		p.State = processbar.Successful
 		p.Done = p.Total
+		p.DoneBytes = p.TotalBytes
 	}
 	p.DoneTime = time.Now()
 	err = processBarModel.SendUpdateProcessMsg(p, true)
@@ -314,9 +560,11 @@ func executePasteOperation(processBarModel *processbar.Model,
 	return p.State
 }
 
-func getTotalFilesCnt(copyItems []string) int {
-	totalFiles := 0
-	for _, folderPath := range copyItems {
+// Returns count and size of files in each of the items, and their total
+func getItemsSize(copyItems []string) ([]itemSize, itemSize) {
+	itemSizes := make([]itemSize, len(copyItems))
+	var total itemSize
+	for i, folderPath := range copyItems {
 		// TODO : Fix this. This is inefficient
 		// In case of a cut operations for a directory with a lot of files
 		// we are unnecessarily walking the whole directory recursively
@@ -329,89 +577,57 @@ func getTotalFilesCnt(copyItems []string) int {
 		// instead, we could just track progress based on total items in
 		// copyItems
 		// efficiency should be prioritized over more detailed feedback.
-		count, err := countFiles(folderPath)
+		count, size, err := getFilesCntAndSize(folderPath)
 		if err != nil {
-			slog.Error("Error in countFiles", "error", err)
+			slog.Error("Error in getFilesCntAndSize", "error", err)
 			continue
 		}
-		totalFiles += count
+		itemSizes[i] = itemSize{files: count, bytes: size}
+		total.files += count
+		total.bytes += size
 	}
-	return totalFiles
+	return itemSizes, total
 }
 
-// Extract compressed file
-// TODO : err should be returned and properly handled by the caller
-func (m *model) getExtractFileCmd() tea.Cmd {
-	panel := m.getFocusedFilePanel()
-	if len(panel.element) == 0 {
-		return nil
-	}
-
-	item := panel.getSelectedItem().location
-
-	ext := strings.ToLower(filepath.Ext(item))
-	if !common.IsExtensionExtractable(ext) {
-		slog.Error("Error unexpected file", "extension type", ext, "item", item, "error", errors.ErrUnsupported)
-		return nil
-	}
-	reqID := m.ioReqCnt
-	m.ioReqCnt++
-
-	slog.Debug("Submitting Extract file request", "reqID", reqID, "item", item)
-
-	return func() tea.Msg {
-		outputDir := common.FileNameWithoutExtension(item)
-		outputDir, err := renameIfDuplicate(outputDir)
-		if err != nil {
-			slog.Error("Error while renaming for duplicates", "error", err)
-			return NewCompressOperationMsg(processbar.Failed, reqID)
-		}
-
-		err = os.MkdirAll(outputDir, 0o755)
-		if err != nil {
-			slog.Error("Error while making directory for extracting files", "error", err)
-			return NewCompressOperationMsg(processbar.Failed, reqID)
-		}
-		err = extractCompressFile(item, outputDir, &m.processBarModel)
-		if err != nil {
-			slog.Error("Error extract file", "error", err)
-			return NewCompressOperationMsg(processbar.Failed, reqID)
-		}
-		return NewCompressOperationMsg(processbar.Successful, reqID)
-	}
-}
-
-func (m *model) getCompressSelectedFilesCmd() tea.Cmd {
+// Open the compress modal for the selected items, or the item under the cursor
+func (m *model) openCompressModal() {
 	panel := m.getFocusedFilePanel()
 
 	if len(panel.element) == 0 {
-		return nil
+		return
 	}
 	var filesToCompress []string
-	var firstFile string
 
 	if len(panel.selected) == 0 {
-		firstFile = panel.element[panel.cursor].location
-		filesToCompress = append(filesToCompress, firstFile)
+		filesToCompress = append(filesToCompress, panel.element[panel.cursor].location)
 	} else {
-		firstFile = panel.selected[0]
-		filesToCompress = panel.selected
+		filesToCompress = slices.Clone(panel.selected)
 	}
+	m.compressModal.open = true
+	m.compressModal.items = filesToCompress
+	m.compressModal.location = panel.location
+}
 
+func (m *model) getCompressCmd(filesToCompress []string, location string, opts compressOptions) tea.Cmd {
 	reqID := m.ioReqCnt
 	m.ioReqCnt++
 
 	return func() tea.Msg {
-		zipName, err := getZipArchiveName(filepath.Base(firstFile))
+		archivePath, err := getArchivePath(location, filepath.Base(filesToCompress[0]), opts.format)
 		if err != nil {
-			slog.Error("Error in getZipArchiveName", "error", err)
+			slog.Error("Error in getArchivePath", "error", err)
 			return NewCompressOperationMsg(processbar.Failed, reqID)
 		}
-		zipPath := filepath.Join(panel.location, zipName)
-		if err := zipSources(filesToCompress, zipPath, &m.processBarModel); err != nil {
-			slog.Error("Error in zipping files", "error", err)
+		if err := compressSources(filesToCompress, archivePath, opts, &m.processBarModel); err != nil {
+			slog.Error("Error in compressing files", "error", err)
 			return NewCompressOperationMsg(processbar.Failed, reqID)
 		}
+		items := []journal.Item{journal.NewItem("", archivePath)}
+		if opts.password != "" {
+			m.journal.RecordEncryptedCompress(items, filesToCompress)
+		} else {
+			m.journal.Record(journal.CompressOperation, items, filesToCompress)
+		}
 		return NewCompressOperationMsg(processbar.Successful, reqID)
 	}
 }
@@ -444,6 +660,20 @@ func (m *model) openFileWithEditor() tea.Cmd {
 		slog.Error("Error while writing to chooser file, continuing with open via file editor", "error", err)
 	}
 
+	return tea.ExecProcess(getFileEditorCmd(panel.element[panel.cursor].location), func(err error) tea.Msg {
+		return editorFinishedMsg{err}
+	})
+}
+
+// getFileEditorCmd returns the command that opens the file with the configured editor
+func getFileEditorCmd(path string) *exec.Cmd {
+	return getFileEditorAtLineCmd(path, 0)
+}
+
+// getFileEditorAtLineCmd returns the command that opens the file with the configured editor,
+// at the given line. The file is opened at its beginning if line is zero, or if the way to
+// go to a line is not known for the editor
+func getFileEditorAtLineCmd(path string, line int) *exec.Cmd {
 	editor := common.Config.Editor
 	if editor == "" {
 		editor = os.Getenv("EDITOR")
@@ -463,13 +693,28 @@ func (m *model) openFileWithEditor() tea.Cmd {
 	cmd := parts[0]
 
 	//nolint:gocritic // appendAssign: intentionally creating a new slice
-	args := append(parts[1:], panel.element[panel.cursor].location)
+	args := append(parts[1:], getEditorFileArgs(cmd, path, line)...)
 
-	c := exec.Command(cmd, args...)
+	return exec.Command(cmd, args...)
+}
 
-	return tea.ExecProcess(c, func(err error) tea.Msg {
-		return editorFinishedMsg{err}
-	})
+// getEditorFileArgs returns the arguments that make the editor open path at line
+func getEditorFileArgs(editor string, path string, line int) []string {
+	if line <= 0 {
+		return []string{path}
+	}
+	lineStr := strconv.Itoa(line)
+	switch strings.TrimSuffix(filepath.Base(editor), ".exe") {
+	case "vi", "vim", "nvim", "gvim", "view", "nano", "pico", "emacs", "emacsclient", "micro", "kak",
+		"ne", "joe", "mg", "mcedit":
+		return []string{"+" + lineStr, path}
+	case "hx", "helix", "subl", "zed":
+		return []string{path + ":" + lineStr}
+	case "code", "code-insiders", "codium", "cursor":
+		return []string{"--goto", path + ":" + lineStr}
+	default:
+		return []string{path}
+	}
 }
 
 // Open directory with default editor
@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/journal"
+	"github.com/yorukot/superfile/src/internal/ui/notify"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+)
+
+// Undo the most recent operation in the journal
+func (m *model) getUndoCmd() tea.Cmd {
+	return m.getJournalCmd(true)
+}
+
+// Redo the most recently undone operation
+func (m *model) getRedoCmd() tea.Cmd {
+	return m.getJournalCmd(false)
+}
+
+func (m *model) getJournalCmd(undo bool) tea.Cmd {
+	reqID := m.ioReqCnt
+	m.ioReqCnt++
+	slog.Debug("Submitting journal request", "id", reqID, "undo", undo)
+	return func() tea.Msg {
+		err := switchJournalEntry(m.journal, &m.processBarModel, undo)
+		if err == nil {
+			return nil
+		}
+		slog.Error("Error in journal operation", "undo", undo, "error", err)
+		title := common.UndoFailedTitle
+		if !undo {
+			title = common.RedoFailedTitle
+		}
+		return NewNotifyModalMsg(notify.New(true, title, err.Error(), notify.NoAction), reqID)
+	}
+}
+
+// switchJournalEntry undoes the last applied entry, or redoes the first undone entry
+func switchJournalEntry(j *journal.Journal, processBar *processbar.Model, undo bool) error {
+	var entry journal.Entry
+	var ok bool
+	if undo {
+		entry, ok = j.LastApplied()
+	} else {
+		entry, ok = j.FirstUndone()
+	}
+	if !ok {
+		return errors.New("there is nothing to " + getUndoOrRedoName(undo))
+	}
+	if err := entry.VerifyUnchanged(); err != nil {
+		return err
+	}
+	if !undo && entry.Encrypted {
+		return errors.New("cannot redo the compression of an encrypted archive, as its password " +
+			"is not kept. Compress the files again instead")
+	}
+	if entry.Type == journal.RenameOperation {
+		return switchRenameEntry(j, entry, undo)
+	}
+
+	newItems := slices.Clone(entry.Items)
+	for i, item := range entry.Items {
+		newItem, err := switchJournalItem(entry, item, undo, processBar)
+		if err != nil {
+			// Put the items that were already switched back, so that the entry stays valid
+			for k := range i {
+				if _, rollbackErr := switchJournalItem(entry, newItems[k], !undo, processBar); rollbackErr != nil {
+					slog.Error("Error while rolling back journal item", "item", newItems[k], "error", rollbackErr)
+				}
+			}
+			return fmt.Errorf("could not %s %s of %s : %w", getUndoOrRedoName(undo), entry.Type,
+				entry.CurrentPath(item), err)
+		}
+		newItems[i] = newItem
+	}
+	j.SetUndone(entry.ID, undo, newItems)
+	return nil
+}
+
+// switchRenameEntry renames all the items together, as they could have swapped names
+func switchRenameEntry(j *journal.Journal, entry journal.Entry, undo bool) error {
+	ops := make([]renameOp, len(entry.Items))
+	for i, item := range entry.Items {
+		ops[i] = renameOp{src: item.Src, dst: item.Dst}
+		if undo {
+			ops[i] = renameOp{src: item.Dst, dst: item.Src}
+		}
+	}
+	if _, err := executeRenamePlan(ops); err != nil {
+		return fmt.Errorf("could not %s %s : %w", getUndoOrRedoName(undo), entry.Type, err)
+	}
+	newItems := slices.Clone(entry.Items)
+	for i := range newItems {
+		newItems[i].Fingerprint = journal.NewFingerprint(ops[i].dst)
+	}
+	j.SetUndone(entry.ID, undo, newItems)
+	return nil
+}
+
+// switchJournalItem reverses the operation on a single item, or performs it again.
+// Returns the item with updated fingerprint of its new location
+func switchJournalItem(entry journal.Entry, item journal.Item, undo bool,
+	processBar *processbar.Model) (journal.Item, error) {
+	var err error
+	curPath := item.Dst
+	switch entry.Type {
+	case journal.RenameOperation, journal.MoveOperation:
+		if undo {
+			err = moveElement(item.Dst, item.Src)
+			curPath = item.Src
+		} else {
+			err = moveElement(item.Src, item.Dst)
+		}
+	case journal.TrashOperation:
+		if undo {
+			err = restoreFromTrash(item.Dst, item.Src)
+			curPath = item.Src
+		} else {
+			// Trash could have another item with the same name by now
+			item.Dst = getTrashPath(item.Src)
+			if item.Dst == "" {
+				return item, errors.New("restoring from trash is not supported on this platform")
+			}
+			err = moveToTrash(item.Src)
+			curPath = item.Dst
+		}
+	case journal.CreateOperation:
+		switch {
+		case undo:
+			// Not using os.RemoveAll(), as a directory should only be removed if it is empty
+			err = os.Remove(item.Dst)
+		case item.IsDir:
+			err = os.Mkdir(item.Dst, 0755)
+		default:
+			var f *os.File
+			f, err = os.OpenFile(item.Dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+			if err == nil {
+				err = f.Close()
+			}
+		}
+	case journal.SymlinkOperation, journal.HardlinkOperation:
+		switch {
+		case undo:
+			err = os.Remove(item.Dst)
+		case entry.Type == journal.SymlinkOperation:
+			err = os.Symlink(item.Src, item.Dst)
+		default:
+			err = os.Link(item.Src, item.Dst)
+		}
+	case journal.CompressOperation:
+		if undo {
+			err = os.Remove(item.Dst)
+		} else {
+			err = compressSources(entry.Sources, item.Dst, compressOptions{
+				format: archiveFormatFromPath(item.Dst),
+				level:  defaultCompressLevel,
+			}, processBar)
+		}
+	default:
+		err = fmt.Errorf("unknown operation type %q", entry.Type)
+	}
+	if err != nil {
+		return item, err
+	}
+	item.Fingerprint = journal.NewFingerprint(curPath)
+	return item, nil
+}
+
+func getUndoOrRedoName(undo bool) string {
+	if undo {
+		return "undo"
+	}
+	return "redo"
+}
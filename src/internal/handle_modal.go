@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/yorukot/superfile/src/internal/journal"
 	"github.com/yorukot/superfile/src/internal/utils"
 )
 
@@ -42,14 +43,20 @@ func (m *model) createItem() {
 			slog.Error("Error while createItem during file creation", "error", err)
 			return
 		}
-		defer f.Close()
+		f.Close()
 	} else {
+		path = filepath.Clean(path)
+		if _, err := os.Stat(path); err == nil {
+			// Nothing was created
+			return
+		}
 		err := os.MkdirAll(path, 0755)
 		if err != nil {
 			slog.Error("Error while createItem during directory creation", "error", err)
 			return
 		}
 	}
+	m.journal.Record(journal.CreateOperation, []journal.Item{journal.NewItem("", path)}, nil)
 }
 
 // Cancel rename file or directory
@@ -72,13 +79,16 @@ func (m *model) confirmRename() {
 	}
 
 	oldPath := panel.element[panel.cursor].location
-	newPath := filepath.Join(panel.location, panel.rename.Value())
+	// Items of the tree view can be in a subdirectory of the panel
+	newPath := filepath.Join(filepath.Dir(oldPath), panel.rename.Value())
 
 	// Rename the file
 	err := os.Rename(oldPath, newPath)
 	if err != nil {
 		slog.Error("Error while confirmRename during rename", "error", err)
 		// Dont return. We have to also reset the panel and model information
+	} else if oldPath != newPath {
+		m.journal.Record(journal.RenameOperation, []journal.Item{journal.NewItem(oldPath, newPath)}, nil)
 	}
 	m.fileModel.renaming = false
 	panel.rename.Blur()
@@ -9,6 +9,9 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/yorukot/superfile/src/internal/archivefs"
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/notify"
 	"github.com/yorukot/superfile/src/internal/utils"
 
 	variable "github.com/yorukot/superfile/src/config"
@@ -22,7 +25,7 @@ func (m *model) parentDirectory() {
 	}
 }
 
-// Enter directory or open file with default application
+// Enter directory or archive, or open file with default application
 // TODO: Unit test this
 func (m *model) enterPanel() {
 	panel := m.getFocusedFilePanel()
@@ -39,6 +42,11 @@ func (m *model) enterPanel() {
 		}
 		return
 	}
+	if archivefs.IsArchivePath(selectedItem.location) {
+		m.notifyModel = notify.New(true, common.ArchiveMemberOpenTitle, common.ArchiveMemberOpenContent,
+			notify.NoAction)
+		return
+	}
 	fileInfo, err := os.Lstat(selectedItem.location)
 	if err != nil {
 		slog.Error("Error while getting file info", "error", err)
@@ -74,6 +82,14 @@ func (m *model) enterPanel() {
 		// Continue with preview if file is not writable
 		slog.Error("Error while writing to chooser file, continuing with file open", "error", chooserErr)
 	}
+	if common.Config.BrowseArchives && archivefs.IsArchive(selectedItem.location) {
+		err = m.updateCurrentFilePanelDir(archivefs.Root(selectedItem.location))
+		if err == nil {
+			return
+		}
+		// Archives that cannot be read are still opened with the default application
+		slog.Error("Error while browsing archive", "error", err, "archive", selectedItem.location)
+	}
 	m.executeOpenCommand()
 }
 
@@ -104,13 +120,17 @@ func (m *model) executeOpenCommand() {
 	}
 }
 
-// Switch to the directory where the sidebar cursor is located
+// Switch to the directory where the sidebar cursor is located, or open the trash browser
 func (m *model) sidebarSelectDirectory() {
 	// We can't do this when we have only divider directories
 	// m.sidebarModel.directories[m.sidebarModel.cursor].location would point to a divider dir.
 	if m.sidebarModel.NoActualDir() {
 		return
 	}
+	if m.sidebarModel.IsTrashSelected() {
+		m.trashModal.Open()
+		return
+	}
 	// TODO(Refactor): Move this to a function m.ResetFocus()
 	m.focusPanel = nonePanelFocus
 	panel := m.getFocusedFilePanel()
@@ -150,7 +170,6 @@ func (panel *filePanel) singleItemSelect() {
 // Toggle dotfile display or not
 func (m *model) toggleDotFileController() {
 	m.toggleDotFile = !m.toggleDotFile
-	m.updatedToggleDotFile = true
 	err := utils.WriteBoolFile(variable.ToggleDotFile, m.toggleDotFile)
 	if err != nil {
 		slog.Error("Error while updating toggleDotFile data", "error", err)
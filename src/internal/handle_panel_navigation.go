@@ -4,8 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"os"
 
+	"github.com/yorukot/superfile/src/internal/archivefs"
 	"github.com/yorukot/superfile/src/internal/common"
 
 	variable "github.com/yorukot/superfile/src/config"
@@ -34,7 +34,7 @@ func (m *model) createNewFilePanel(location string) error {
 		location = variable.HomeDir
 	}
 
-	if _, err := os.Stat(location); err != nil {
+	if _, err := archivefs.Stat(location); err != nil {
 		return fmt.Errorf("cannot access location : %s", location)
 	}
 
@@ -44,6 +44,7 @@ func (m *model) createNewFilePanel(location string) error {
 		panelMode:        browserMode,
 		isFocused:        false,
 		directoryRecords: make(map[string]directoryRecord),
+		expandedDirs:     make(map[string]struct{}),
 		searchBar:        common.GenerateSearchBar(),
 	})
 
@@ -51,7 +52,7 @@ func (m *model) createNewFilePanel(location string) error {
 		// File preview panel width same as file panel
 		if common.Config.FilePreviewWidth == 0 {
 			m.fileModel.filePreview.SetWidth((m.fullWidth - common.Config.SidebarWidth -
-				(4 + (len(m.fileModel.filePanels))*2)) / (len(m.fileModel.filePanels) + 1))
+				(4 + m.filePanelColumnCnt()*2)) / (m.filePanelColumnCnt() + 1))
 		} else {
 			m.fileModel.filePreview.SetWidth((m.fullWidth - common.Config.SidebarWidth) / common.Config.FilePreviewWidth)
 		}
@@ -60,7 +61,7 @@ func (m *model) createNewFilePanel(location string) error {
 	m.fileModel.filePanels[m.filePanelFocusIndex].isFocused = false
 	m.fileModel.filePanels[m.filePanelFocusIndex+1].isFocused = returnFocusType(m.focusPanel)
 	m.fileModel.width = (m.fullWidth - common.Config.SidebarWidth - m.fileModel.filePreview.GetWidth() -
-		(4 + (len(m.fileModel.filePanels)-1)*2)) / len(m.fileModel.filePanels)
+		(4 + (m.filePanelColumnCnt()-1)*2)) / m.filePanelColumnCnt()
 	m.filePanelFocusIndex++
 
 	m.fileModel.maxFilePanel = (m.fullWidth - common.Config.SidebarWidth - m.fileModel.filePreview.GetWidth()) / 20
@@ -84,7 +85,7 @@ func (m *model) closeFilePanel() {
 		// File preview panel width same as file panel
 		if common.Config.FilePreviewWidth == 0 {
 			m.fileModel.filePreview.SetWidth((m.fullWidth - common.Config.SidebarWidth -
-				(4 + (len(m.fileModel.filePanels))*2)) / (len(m.fileModel.filePanels) + 1))
+				(4 + m.filePanelColumnCnt()*2)) / (m.filePanelColumnCnt() + 1))
 		} else {
 			m.fileModel.filePreview.SetWidth((m.fullWidth - common.Config.SidebarWidth) / common.Config.FilePreviewWidth)
 		}
@@ -95,7 +96,7 @@ func (m *model) closeFilePanel() {
 	}
 
 	m.fileModel.width = (m.fullWidth - common.Config.SidebarWidth - m.fileModel.filePreview.GetWidth() -
-		(4 + (len(m.fileModel.filePanels)-1)*2)) / len(m.fileModel.filePanels)
+		(4 + (m.filePanelColumnCnt()-1)*2)) / m.filePanelColumnCnt()
 	m.fileModel.filePanels[m.filePanelFocusIndex].isFocused = returnFocusType(m.focusPanel)
 
 	m.fileModel.maxFilePanel = (m.fullWidth - common.Config.SidebarWidth - m.fileModel.filePreview.GetWidth()) / 20
@@ -113,14 +114,14 @@ func (m *model) toggleFilePreviewPanel() {
 		// File preview panel width same as file panel
 		if common.Config.FilePreviewWidth == 0 {
 			m.fileModel.filePreview.SetWidth((m.fullWidth - common.Config.SidebarWidth -
-				(4 + (len(m.fileModel.filePanels))*2)) / (len(m.fileModel.filePanels) + 1))
+				(4 + m.filePanelColumnCnt()*2)) / (m.filePanelColumnCnt() + 1))
 		} else {
 			m.fileModel.filePreview.SetWidth((m.fullWidth - common.Config.SidebarWidth) / common.Config.FilePreviewWidth)
 		}
 	}
 
 	m.fileModel.width = (m.fullWidth - common.Config.SidebarWidth - m.fileModel.filePreview.GetWidth() -
-		(4 + (len(m.fileModel.filePanels)-1)*2)) / len(m.fileModel.filePanels)
+		(4 + (m.filePanelColumnCnt()-1)*2)) / m.filePanelColumnCnt()
 
 	m.fileModel.maxFilePanel = (m.fullWidth - common.Config.SidebarWidth - m.fileModel.filePreview.GetWidth()) / 20
 
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yorukot/superfile/src/internal/common"
+)
+
+const panelPickerWidth = 60
+
+// Borders, the item count, the hint line and the two dividers, along with a line per panel
+func (p *panelPickerModal) height() int {
+	return len(p.panels) + 6
+}
+
+func (p *panelPickerModal) close() {
+	p.open = false
+	p.items = nil
+	p.panels = nil
+	p.cursor = 0
+}
+
+// copyToNextPanel copies or moves the selected items, or the item under the cursor, to the
+// location of another file panel. With two panels, the other one is used right away. With
+// more, a picker chooses it, starting with the next panel
+func (m *model) copyToNextPanel(cut bool) tea.Cmd {
+	panel := m.getFocusedFilePanel()
+	panelCnt := len(m.fileModel.filePanels)
+	if len(panel.element) == 0 || panelCnt < 2 {
+		return nil
+	}
+
+	var items []string
+	if panel.usesSelection() {
+		items = slices.Clone(panel.selected)
+	} else {
+		items = []string{panel.getSelectedItem().location}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	if panelCnt == 2 {
+		next := m.fileModel.filePanels[(m.filePanelFocusIndex+1)%panelCnt]
+		return m.getPasteCmd(items, next.location, cut)
+	}
+	panels := make([]int, 0, panelCnt-1)
+	for i := 1; i < panelCnt; i++ {
+		panels = append(panels, (m.filePanelFocusIndex+i)%panelCnt)
+	}
+	m.panelPickerModal = panelPickerModal{
+		open:   true,
+		items:  items,
+		cut:    cut,
+		panels: panels,
+	}
+	return nil
+}
+
+// Handles key inputs while the panel picker is open
+func (m *model) panelPickerKey(msg string) tea.Cmd {
+	p := &m.panelPickerModal
+	switch {
+	case slices.Contains(common.Hotkeys.Quit, msg), slices.Contains(common.Hotkeys.CancelTyping, msg):
+		p.close()
+	case slices.Contains(common.Hotkeys.ListUp, msg):
+		p.cursor = (p.cursor + len(p.panels) - 1) % len(p.panels)
+	case slices.Contains(common.Hotkeys.ListDown, msg):
+		p.cursor = (p.cursor + 1) % len(p.panels)
+	case slices.Contains(common.Hotkeys.Confirm, msg):
+		items, cut := p.items, p.cut
+		location := m.fileModel.filePanels[p.panels[p.cursor]].location
+		p.close()
+		return m.getPasteCmd(items, location, cut)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/zipcrypto"
+)
+
+// Ask for the password of an archive, and retry its extraction with it. err tells whether
+// the password was missing or wrong
+func (m *model) openExtractPasswordModal(extract pendingExtract, err error) {
+	m.openPasswordModal("Password of " + filepath.Base(extract.archive))
+	m.passwordModal.extract = &extract
+	if errors.Is(err, zipcrypto.ErrWrongPassword) {
+		m.passwordModal.errorMessage = "Wrong password"
+	}
+}
+
+// Ask for the password of the archive items are copied out of, and retry the paste with it
+func (m *model) openPastePasswordModal(paste pendingPaste, archive string, err error) {
+	m.openPasswordModal("Password of " + filepath.Base(archive))
+	m.passwordModal.paste = &paste
+	if errors.Is(err, zipcrypto.ErrWrongPassword) {
+		m.passwordModal.errorMessage = "Wrong password"
+	}
+}
+
+// Ask for the password to encrypt a new zip with, and compress the items once it is given
+func (m *model) openCompressPasswordModal(compress pendingCompress) {
+	m.openPasswordModal("Password to encrypt the zip with")
+	m.passwordModal.compress = &compress
+}
+
+func (m *model) openPasswordModal(title string) {
+	m.passwordModal = passwordModal{
+		open:      true,
+		textInput: common.GeneratePasswordTextInput(),
+		title:     title,
+	}
+	m.ignoreOpeningKey()
+}
+
+func (p *passwordModal) close() {
+	p.textInput.Blur()
+	// Dont keep the password around
+	p.textInput.Reset()
+	p.open = false
+	p.errorMessage = ""
+	p.extract = nil
+	p.compress = nil
+	p.paste = nil
+}
+
+// Handles key inputs while the password modal is open. Other keys are typed in the
+// password, via updateFilePanelsState
+func (m *model) passwordModalKey(msg string) tea.Cmd {
+	p := &m.passwordModal
+	switch {
+	case slices.Contains(common.Hotkeys.CancelTyping, msg):
+		p.close()
+	case slices.Contains(common.Hotkeys.ConfirmTyping, msg):
+		password := p.textInput.Value()
+		if password == "" {
+			p.errorMessage = "Password is empty"
+			return nil
+		}
+		extract, compress, paste := p.extract, p.compress, p.paste
+		p.close()
+		if extract != nil {
+			extract.opts.password = password
+			return m.getExtractCmd(extract.archive, extract.opts)
+		}
+		if compress != nil {
+			compress.opts.password = password
+			return m.getCompressCmd(compress.items, compress.location, compress.opts)
+		}
+		if paste != nil {
+			paste.password = password
+			return m.getPendingPasteCmd(*paste)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package internal
+
+import (
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/metadata"
+)
+
+// Open the pattern rename modal for the selected items
+func (m *model) openPatternRename() tea.Cmd {
+	panel := m.getFocusedFilePanel()
+	if len(panel.selected) == 0 {
+		return nil
+	}
+	r := &m.patternRenameModal
+	r.open = true
+	r.items = slices.Clone(panel.selected)
+	r.find = common.GeneratePatternRenameTextInput(r.width-12, "Regex, like (\\d+)")
+	r.replace = common.GeneratePatternRenameTextInput(r.width-12, "Like ${1}_{n:03}{ext}")
+	r.find.Focus()
+	r.replaceFocused = false
+	r.nameCase = renameCaseKeep
+	r.exifDates = make(map[string]exifDateResult)
+	r.exifToFetch = nil
+	r.renderIndex = 0
+	r.updatePreview()
+	m.ignoreOpeningKey()
+	return m.getExifDatesCmd()
+}
+
+// Handles key inputs while the pattern rename modal is open. Other keys are typed in
+// the focused input, via updateFilePanelsState
+func (m *model) patternRenameKey(msg tea.KeyMsg) tea.Cmd {
+	r := &m.patternRenameModal
+	key := msg.String()
+	switch {
+	case slices.Contains(common.Hotkeys.CancelTyping, key):
+		r.close()
+	case slices.Contains(common.Hotkeys.ConfirmTyping, key):
+		return m.confirmPatternRename()
+	case slices.Contains(common.Hotkeys.NextInput, key):
+		r.replaceFocused = !r.replaceFocused
+		if r.replaceFocused {
+			r.find.Blur()
+			return r.replace.Focus()
+		}
+		r.replace.Blur()
+		return r.find.Focus()
+	case slices.Contains(common.Hotkeys.ToggleCase, key):
+		r.nameCase = r.nameCase.next()
+		r.updatePreview()
+		return m.getExifDatesCmd()
+	// Letters are typed in the inputs, and are not used for scrolling
+	case slices.Contains(common.Hotkeys.ListUp, key) && msg.Type != tea.KeyRunes:
+		r.scroll(-1)
+	case slices.Contains(common.Hotkeys.ListDown, key) && msg.Type != tea.KeyRunes:
+		r.scroll(1)
+	}
+	return nil
+}
+
+// Update the focused input, and the preview if the input changed
+func (r *patternRenameModal) updateInput(msg tea.Msg) tea.Cmd {
+	input := &r.find
+	if r.replaceFocused {
+		input = &r.replace
+	}
+	prevValue := input.Value()
+	var cmd tea.Cmd
+	*input, cmd = input.Update(msg)
+	if input.Value() != prevValue {
+		r.updatePreview()
+	}
+	return cmd
+}
+
+// updatePreview computes the new names, and marks the ones that cannot be applied. The
+// names that need an exif date wait for it to be fetched by getExifDatesCmd
+func (r *patternRenameModal) updatePreview() {
+	r.rows = make([]patternRenameRow, len(r.items))
+	r.patternErr = ""
+	pattern, err := newRenamePattern(r.find.Value(), r.replace.Value(), r.nameCase)
+	if err != nil {
+		r.patternErr = err.Error()
+	}
+
+	srcs := make(map[string]bool, len(r.items))
+	for _, item := range r.items {
+		srcs[item] = true
+	}
+	dsts := make(map[string]int, len(r.items))
+	for i, item := range r.items {
+		row := &r.rows[i]
+		row.src = item
+		row.newName = filepath.Base(item)
+		if r.patternErr != "" {
+			continue
+		}
+		row.newName, err = pattern.apply(item, i, r.getExifDate)
+		if errors.Is(err, errExifDatePending) {
+			row.newName = ""
+			row.pending = true
+			continue
+		}
+		if err != nil {
+			row.conflict = err.Error()
+			continue
+		}
+		dst, err := getRenameTarget(item, row.newName, srcs)
+		if err != nil {
+			row.conflict = err.Error()
+			continue
+		}
+		if other, ok := dsts[dst]; ok {
+			row.conflict = "same new name as " + filepath.Base(r.items[other])
+			if r.rows[other].conflict == "" {
+				r.rows[other].conflict = "same new name as " + filepath.Base(item)
+			}
+			continue
+		}
+		dsts[dst] = i
+	}
+}
+
+// getExifDate returns the fetched exif date of the item, or errExifDatePending. The item is
+// then queued for getExifDatesCmd
+func (r *patternRenameModal) getExifDate(path string) (time.Time, error) {
+	res, ok := r.exifDates[path]
+	if !ok {
+		res.err = errExifDatePending
+		r.exifDates[path] = res
+		r.exifToFetch = append(r.exifToFetch, path)
+	}
+	return res.date, res.err
+}
+
+// getExifDatesCmd fetches the exif dates queued by the preview, outside of the UI thread
+func (m *model) getExifDatesCmd() tea.Cmd {
+	r := &m.patternRenameModal
+	if len(r.exifToFetch) == 0 {
+		return nil
+	}
+	paths := r.exifToFetch
+	r.exifToFetch = nil
+	reqID := m.ioReqCnt
+	m.ioReqCnt++
+	slog.Debug("Submitting exif dates request", "id", reqID, "items cnt", len(paths))
+	return func() tea.Msg {
+		dates := make(map[string]exifDateResult, len(paths))
+		for _, path := range paths {
+			var res exifDateResult
+			res.date, res.err = metadata.GetExifDate(path, et)
+			dates[path] = res
+		}
+		return NewExifDatesMsg(dates, reqID)
+	}
+}
+
+// Store the fetched exif dates, and compute the new names that waited for them. Dates
+// fetched for a previous opening of the modal are ignored
+func (r *patternRenameModal) setExifDates(dates map[string]exifDateResult) {
+	if !r.open {
+		return
+	}
+	updated := false
+	for path, res := range dates {
+		if cur, ok := r.exifDates[path]; ok && errors.Is(cur.err, errExifDatePending) {
+			r.exifDates[path] = res
+			updated = true
+		}
+	}
+	if updated {
+		r.updatePreview()
+	}
+}
+
+// Returns the new names, or false if any of them cannot be applied
+func (r *patternRenameModal) getNewNames() ([]string, bool) {
+	if r.patternErr != "" {
+		return nil, false
+	}
+	newNames := make([]string, len(r.rows))
+	for i, row := range r.rows {
+		if row.conflict != "" || row.pending {
+			return nil, false
+		}
+		newNames[i] = row.newName
+	}
+	return newNames, true
+}
+
+func (r *patternRenameModal) pendingCnt() int {
+	cnt := 0
+	for _, row := range r.rows {
+		if row.pending {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+func (r *patternRenameModal) conflictCnt() int {
+	cnt := 0
+	for _, row := range r.rows {
+		if row.conflict != "" {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// Apply the renames, if none of them conflict. They are done as a single process, and
+// are rolled back if any of them fails
+func (m *model) confirmPatternRename() tea.Cmd {
+	newNames, ok := m.patternRenameModal.getNewNames()
+	if !ok {
+		return nil
+	}
+	ops, err := buildRenamePlan(m.patternRenameModal.items, newNames)
+	if err != nil {
+		// The files changed since the preview
+		slog.Error("Invalid pattern rename", "error", err)
+		m.patternRenameModal.patternErr = err.Error()
+		return nil
+	}
+	m.patternRenameModal.close()
+	if len(ops) == 0 {
+		slog.Debug("Pattern rename did not change any names")
+		return nil
+	}
+	m.getFocusedFilePanel().resetSelected()
+
+	reqID := m.ioReqCnt
+	m.ioReqCnt++
+	slog.Debug("Submitting pattern rename request", "id", reqID, "ops cnt", len(ops))
+	return func() tea.Msg {
+		state := executeRenameOperation(&m.processBarModel, ops, m.journal)
+		return NewRenameOperationMsg(state, reqID)
+	}
+}
+
+func (r *patternRenameModal) close() {
+	r.open = false
+	r.items = nil
+	r.rows = nil
+	r.patternErr = ""
+	r.exifDates = nil
+	r.exifToFetch = nil
+	r.renderIndex = 0
+}
+
+func (r *patternRenameModal) visibleRowCnt() int {
+	// Borders, the two inputs, the status line, the hint line and the three dividers
+	return max(r.height-9, 1)
+}
+
+func (r *patternRenameModal) scroll(delta int) {
+	r.renderIndex = max(min(r.renderIndex+delta, len(r.rows)-r.visibleRowCnt()), 0)
+}
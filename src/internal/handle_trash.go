@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yorukot/superfile/src/config/icon"
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/notify"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	trashui "github.com/yorukot/superfile/src/internal/ui/trash"
+)
+
+// Restore the trash item under the cursor to its original location. If something else exists
+// there by now, the user is asked how to resolve it, the same way as for a paste
+func (m *model) getRestoreTrashItemCmd() tea.Cmd {
+	item, ok := m.trashModal.GetSelectedItem()
+	if !ok {
+		return nil
+	}
+	reqID := m.ioReqCnt
+	m.ioReqCnt++
+	slog.Debug("Submitting trash restore request", "id", reqID, "item", item.Path)
+	return func() tea.Msg {
+		items := []trashui.Item{item}
+		if _, err := os.Lstat(item.OriginalPath); err == nil {
+			return NewPasteConflictMsg(pendingPaste{
+				reqID:     reqID,
+				restore:   items,
+				conflicts: []string{item.OriginalPath},
+				policies:  make(map[string]pasteConflictPolicy),
+			}, reqID)
+		}
+		state := trashOperation(&m.processBarModel, items, true, nil)
+		return NewTrashOperationMsg(state, reqID)
+	}
+}
+
+// Ask for a confirmation before deleting the trash item under the cursor, or all of them
+func (m *model) getDeleteTrashItemsTriggerCmd(all bool) tea.Cmd {
+	if len(m.trashModal.GetItems()) == 0 {
+		return nil
+	}
+	reqID := m.ioReqCnt
+	m.ioReqCnt++
+	return func() tea.Msg {
+		title := common.DeleteTrashItemWarnTitle
+		action := notify.DeleteTrashItemAction
+		if all {
+			title = common.EmptyTrashWarnTitle
+			action = notify.EmptyTrashAction
+		}
+		return NewNotifyModalMsg(notify.New(true, title, common.PermanentDeleteWarnContent, action), reqID)
+	}
+}
+
+func (m *model) getDeleteTrashItemsCmd(all bool) tea.Cmd {
+	items := m.trashModal.GetItems()
+	if !all {
+		item, ok := m.trashModal.GetSelectedItem()
+		if !ok {
+			return nil
+		}
+		items = []trashui.Item{item}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	reqID := m.ioReqCnt
+	m.ioReqCnt++
+	slog.Debug("Submitting trash delete request", "id", reqID, "items cnt", len(items))
+	return func() tea.Msg {
+		state := trashOperation(&m.processBarModel, items, false, nil)
+		return NewTrashOperationMsg(state, reqID)
+	}
+}
+
+// trashOperation restores the trash items to their original location, or deletes them permanently.
+// policies decides how to handle the restored items whose original path exists, keyed by that
+// path. Items without a policy are restored next to what exists with a new name
+func trashOperation(processBarModel *processbar.Model, items []trashui.Item, restore bool,
+	policies map[string]pasteConflictPolicy,
+) processbar.ProcessState {
+	opIcon := icon.Delete
+	opFunc := deleteTrashItem
+	operation := operationDeleteFromTrash
+	if restore {
+		opIcon = icon.Restore
+		opFunc = func(item trashui.Item, p *processbar.Process) error {
+			policy, ok := policies[item.OriginalPath]
+			if !ok {
+				policy = conflictKeepBoth
+			}
+			return restoreTrashItem(item, policy, p)
+		}
+		operation = operationRestore
+	}
+	p, err := processBarModel.SendAddProcessMsg(opIcon+icon.Space+items[0].Name, len(items), true)
+	if err != nil {
+		slog.Error("Cannot spawn a new process", "error", err)
+		return processbar.Failed
+	}
+	p.Operation = operation
+	for _, item := range items {
+		p.Sources = append(p.Sources, item.OriginalPath)
+	}
+
+	for _, item := range items {
+		p.Name = opIcon + icon.Space + item.Name
+		err = p.Checkpoint()
+		if err == nil {
+			err = opFunc(item, &p)
+		}
+		if err != nil {
+			p.State = getProcessStateFromError(err)
+			p.AddError(item.Path + ": " + err.Error())
+			slog.Error("Error in trash operation", "item", item.Path, "restore", restore, "error", err)
+			break
+		}
+		p.Done++
+		processBarModel.TrySendingUpdateProcessMsg(p)
+	}
+
+	if p.State == processbar.InOperation {
+		p.State = processbar.Successful
+	}
+	p.DoneTime = time.Now()
+	err = processBarModel.SendUpdateProcessMsg(p, true)
+	if err != nil {
+		slog.Error("Failed to send final trash operation update", "error", err)
+	}
+	return p.State
+}
+
+// restoreTrashItem moves the item back to its original location. If something else exists
+// there by now, policy decides whether it is replaced, or kept with the item restored next
+// to it, or the item is left in the trash
+func restoreTrashItem(item trashui.Item, policy pasteConflictPolicy, p *processbar.Process) error {
+	info, err := os.Lstat(item.Path)
+	if err != nil {
+		return err
+	}
+	dst, restore, err := resolvePasteConflict(info, item.OriginalPath, policy)
+	if err != nil || !restore {
+		return err
+	}
+	if dst != item.OriginalPath {
+		p.AddWarning("Restored " + item.OriginalPath + " as " + filepath.Base(dst) + ", as it already exists")
+	}
+	// The original directory could have been removed since
+	if err = os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create the original directory: %w", err)
+	}
+	// A restored directory replaces the existing one, instead of being merged into it
+	if err = os.RemoveAll(dst); err != nil {
+		return fmt.Errorf("failed to remove the existing item: %w", err)
+	}
+	if err = moveElement(item.Path, dst); err != nil {
+		return err
+	}
+	return removeTrashInfo(item)
+}
+
+func deleteTrashItem(item trashui.Item, _ *processbar.Process) error {
+	if err := os.RemoveAll(item.Path); err != nil {
+		return err
+	}
+	return removeTrashInfo(item)
+}
+
+// The trash would list an item that isn't there anymore, if its trashinfo was left behind
+func removeTrashInfo(item trashui.Item) error {
+	if err := os.Remove(item.InfoPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove trash info: %w", err)
+	}
+	return nil
+}
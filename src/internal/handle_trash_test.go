@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/ui/sidebar"
+	trashui "github.com/yorukot/superfile/src/internal/ui/trash"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func TestTrashOperation(t *testing.T) {
+	processBar := processbar.New()
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+
+	// Trash items as they would be listed by the trash browser
+	setup := func(t *testing.T, names ...string) (string, []trashui.Item) {
+		curTestDir := t.TempDir()
+		filesDir := filepath.Join(curTestDir, "Trash", "files")
+		infoDir := filepath.Join(curTestDir, "Trash", "info")
+		origDir := filepath.Join(curTestDir, "orig")
+		utils.SetupDirectories(t, filesDir, infoDir, origDir)
+		var items []trashui.Item
+		for _, name := range names {
+			item := trashui.Item{
+				Name:         name,
+				Path:         filepath.Join(filesDir, name),
+				InfoPath:     filepath.Join(infoDir, name+".trashinfo"),
+				OriginalPath: filepath.Join(origDir, name),
+			}
+			utils.SetupFilesWithData(t, []byte("trashed "+name), item.Path)
+			utils.SetupFiles(t, item.InfoPath)
+			items = append(items, item)
+		}
+		return origDir, items
+	}
+
+	t.Run("Restore", func(t *testing.T) {
+		origDir, items := setup(t, "file1.txt", "file2.txt")
+		// Something else now exists at the original location of file2.txt
+		utils.SetupFilesWithData(t, []byte("new"), filepath.Join(origDir, "file2.txt"))
+
+		state := trashOperation(&processBar, items, true, nil)
+		assert.Equal(t, processbar.Successful, state)
+		for _, item := range items {
+			assert.NoFileExists(t, item.Path)
+			assert.NoFileExists(t, item.InfoPath)
+		}
+		data, err := os.ReadFile(filepath.Join(origDir, "file1.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "trashed file1.txt", string(data))
+		data, err = os.ReadFile(filepath.Join(origDir, "file2(1).txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "trashed file2.txt", string(data))
+		data, err = os.ReadFile(filepath.Join(origDir, "file2.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(data), "Existing file should be kept")
+	})
+
+	t.Run("Restore to a removed directory", func(t *testing.T) {
+		origDir, items := setup(t, "file.txt")
+		items[0].OriginalPath = filepath.Join(origDir, "removed", "file.txt")
+
+		state := trashOperation(&processBar, items, true, nil)
+		assert.Equal(t, processbar.Successful, state)
+		assert.FileExists(t, items[0].OriginalPath)
+	})
+
+	t.Run("Restore with conflict policies", func(t *testing.T) {
+		origDir, items := setup(t, "skip.txt", "overwrite.txt", "keep.txt")
+		for _, item := range items {
+			utils.SetupFilesWithData(t, []byte("new"), item.OriginalPath)
+		}
+		policies := map[string]pasteConflictPolicy{
+			items[0].OriginalPath: conflictSkip,
+			items[1].OriginalPath: conflictOverwrite,
+			items[2].OriginalPath: conflictKeepBoth,
+		}
+
+		state := trashOperation(&processBar, items, true, policies)
+		assert.Equal(t, processbar.Successful, state)
+		assert.FileExists(t, items[0].Path, "Skipped item should stay in the trash")
+		assert.FileExists(t, items[0].InfoPath)
+		assertFileContent(t, items[0].OriginalPath, "new")
+		assertFileContent(t, items[1].OriginalPath, "trashed overwrite.txt")
+		assertFileContent(t, items[2].OriginalPath, "new")
+		assertFileContent(t, filepath.Join(origDir, "keep(1).txt"), "trashed keep.txt")
+		for _, item := range items[1:] {
+			assert.NoFileExists(t, item.Path)
+			assert.NoFileExists(t, item.InfoPath)
+		}
+	})
+
+	t.Run("Restored directory replaces the existing one", func(t *testing.T) {
+		_, items := setup(t, "dir")
+		item := items[0]
+		// Trashed as a directory instead
+		require.NoError(t, os.Remove(item.Path))
+		utils.SetupDirectories(t, item.Path, item.OriginalPath)
+		utils.SetupFilesWithData(t, []byte("trashed"), filepath.Join(item.Path, "trashed.txt"))
+		utils.SetupFiles(t, filepath.Join(item.OriginalPath, "new.txt"))
+
+		state := trashOperation(&processBar, items, true,
+			map[string]pasteConflictPolicy{item.OriginalPath: conflictOverwrite})
+		assert.Equal(t, processbar.Successful, state)
+		assertFileContent(t, filepath.Join(item.OriginalPath, "trashed.txt"), "trashed")
+		assert.NoFileExists(t, filepath.Join(item.OriginalPath, "new.txt"))
+		assert.NoDirExists(t, item.Path)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		origDir, items := setup(t, "file1.txt", "file2.txt")
+
+		state := trashOperation(&processBar, items, false, nil)
+		assert.Equal(t, processbar.Successful, state)
+		for _, item := range items {
+			assert.NoFileExists(t, item.Path)
+			assert.NoFileExists(t, item.InfoPath)
+			assert.NoFileExists(t, filepath.Join(origDir, item.Name))
+		}
+	})
+}
+
+func TestRestoreTrashItemConflict(t *testing.T) {
+	curTestDir := t.TempDir()
+	filesDir := filepath.Join(curTestDir, "Trash", "files")
+	infoDir := filepath.Join(curTestDir, "Trash", "info")
+	origDir := filepath.Join(curTestDir, "orig")
+	origPath := filepath.Join(origDir, "file.txt")
+	utils.SetupDirectories(t, filesDir, infoDir, origDir)
+	utils.SetupFilesWithData(t, []byte("trashed"), filepath.Join(filesDir, "file.txt"))
+	utils.SetupFilesWithData(t, []byte("[Trash Info]\nPath="+origPath+"\n"),
+		filepath.Join(infoDir, "file.txt.trashinfo"))
+	utils.SetupFilesWithData(t, []byte("new"), origPath)
+
+	m := defaultTestModel(origDir)
+	TeaUpdate(m, nil)
+	m.trashModal = trashui.New(filesDir, infoDir, true, trashui.TrashMinHeight, trashui.TrashMinWidth)
+	m.trashModal.Open()
+	require.Len(t, m.trashModal.GetItems(), 1)
+
+	p := NewTestTeaProgWithEventLoop(t, m)
+	p.SendKey(common.Hotkeys.Confirm[0])
+	choosePasteConflictPolicy(t, p, conflictOverwrite, false)
+
+	assert.Eventually(t, func() bool {
+		data, err := os.ReadFile(origPath)
+		return err == nil && string(data) == "trashed"
+	}, DefaultTestTimeout, DefaultTestTick, "Restored item should overwrite the existing one")
+	assert.Eventually(t, func() bool {
+		empty := false
+		p.ReadModel(func(m *model) {
+			empty = len(m.trashModal.GetItems()) == 0
+		})
+		return empty
+	}, DefaultTestTimeout, DefaultTestTick, "Trash should be reloaded after the restore")
+}
+
+func TestSidebarOpensTrash(t *testing.T) {
+	curTestDir := t.TempDir()
+	filesDir := filepath.Join(curTestDir, "Trash", "files")
+	infoDir := filepath.Join(curTestDir, "Trash", "info")
+	utils.SetupDirectories(t, filesDir, infoDir)
+
+	m := defaultTestModel(curTestDir)
+	TeaUpdate(m, nil)
+	m.sidebarModel = sidebar.New(true)
+	m.trashModal = trashui.New(filesDir, infoDir, true, trashui.TrashMinHeight, trashui.TrashMinWidth)
+	TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.FocusOnSidebar[0]))
+	require.Equal(t, sidebarFocus, m.focusPanel)
+	for range 20 {
+		if m.sidebarModel.IsTrashSelected() {
+			break
+		}
+		TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.ListDown[0]))
+	}
+	require.True(t, m.sidebarModel.IsTrashSelected(), "Trash should be listed in the sidebar")
+
+	TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.Confirm[0]))
+	assert.True(t, m.trashModal.IsOpen())
+	assert.Equal(t, curTestDir, m.getFocusedFilePanel().location, "File panel should stay in place")
+}
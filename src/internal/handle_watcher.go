@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yorukot/superfile/src/internal/fswatch"
+)
+
+// Changes in a directory are reported at most this often, so that copying many files
+// does not read the panel again for each of them
+const watcherDebounce = 100 * time.Millisecond
+
+// newWatcher returns nil if directories cannot be watched, so that all panels are polled
+func newWatcher() *fswatch.Watcher {
+	w, err := fswatch.New(watcherDebounce)
+	if err != nil {
+		slog.Info("Cannot watch directories, file panels will be polled", "error", err)
+		return nil
+	}
+	return w
+}
+
+// watchFilePanels watches the directories shown in the open file panels, and only those
+func (m *model) watchFilePanels() {
+	if m.watcher == nil {
+		return
+	}
+	dirs := make([]string, 0, len(m.fileModel.filePanels))
+	for _, panel := range m.fileModel.filePanels {
+		dirs = append(dirs, panel.shownDirs()...)
+	}
+	if m.fileModel.parentColumn.location != "" {
+		dirs = append(dirs, m.fileModel.parentColumn.location)
+	}
+	m.watcher.Sync(dirs)
+}
+
+// isWatched reports whether the watcher reports the changes of all the directories shown
+// in the panel
+func (m *model) isWatched(panel *filePanel) bool {
+	for _, dir := range panel.shownDirs() {
+		if !m.watcher.IsWatched(dir) {
+			return false
+		}
+	}
+	return true
+}
+
+// An IO Operation, that waits for the next directory change reported by the watcher
+func (m *model) getWatcherCmd() tea.Cmd {
+	if m.watcher == nil {
+		return nil
+	}
+	changes := m.watcher.Changes()
+	reqID := m.ioReqCnt
+	m.ioReqCnt++
+	return func() tea.Msg {
+		return NewDirectoryChangedMsg(<-changes, reqID)
+	}
+}
@@ -0,0 +1,58 @@
+//go:build linux
+
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func TestWatchedFilePanels(t *testing.T) {
+	curTestDir := t.TempDir()
+	dir1 := filepath.Join(curTestDir, "dir1")
+	dir2 := filepath.Join(curTestDir, "dir2")
+	utils.SetupDirectories(t, dir1, dir2)
+
+	m := defaultTestModel(dir1, dir2)
+	m.watcher = newWatcher()
+	require.NotNil(t, m.watcher)
+	t.Cleanup(func() { _ = m.watcher.Close() })
+	TeaUpdate(m, nil)
+	require.True(t, m.watcher.IsWatched(dir1))
+	require.True(t, m.watcher.IsWatched(dir2))
+
+	t.Run("Only the changed panel is read again", func(t *testing.T) {
+		cmd := m.getWatcherCmd()
+		utils.SetupFiles(t, filepath.Join(dir2, "file2.txt"))
+		msg := ExecuteTeaCmdWithTimeout(cmd, DefaultTestTimeout)
+		changed, ok := msg.(DirectoryChangedMsg)
+		require.True(t, ok)
+		assert.Equal(t, dir2, changed.dir)
+
+		TeaUpdate(m, msg)
+		assert.Len(t, m.fileModel.filePanels[1].element, 1)
+		assert.Empty(t, m.fileModel.filePanels[0].element)
+	})
+
+	t.Run("Watched panels are not polled", func(t *testing.T) {
+		cmd := m.getWatcherCmd()
+		utils.SetupFiles(t, filepath.Join(dir1, ".hidden"))
+		TeaUpdate(m, nil)
+		assert.Empty(t, m.fileModel.filePanels[0].element, "Hidden files are not shown")
+
+		m.toggleDotFile = true
+		TeaUpdate(m, nil)
+		assert.Len(t, m.fileModel.filePanels[0].element, 1, "Panels are read again once they show something else")
+
+		utils.SetupFiles(t, filepath.Join(dir1, "file1.txt"))
+		TeaUpdate(m, nil)
+		assert.Len(t, m.fileModel.filePanels[0].element, 1)
+		TeaUpdate(m, ExecuteTeaCmdWithTimeout(cmd, DefaultTestTimeout))
+		assert.Len(t, m.fileModel.filePanels[0].element, 2)
+	})
+}
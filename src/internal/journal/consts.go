@@ -0,0 +1,4 @@
+package journal
+
+// Older entries are dropped once the journal grows beyond this
+const maxEntries = 100
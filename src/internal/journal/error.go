@@ -0,0 +1,17 @@
+package journal
+
+type PathChangedError struct {
+	path string
+}
+
+func (p *PathChangedError) Error() string {
+	return p.path + " has changed since the operation"
+}
+
+type PathExistsError struct {
+	path string
+}
+
+func (p *PathExistsError) Error() string {
+	return p.path + " already exists"
+}
@@ -0,0 +1,37 @@
+package journal
+
+import (
+	"os"
+)
+
+func NewFingerprint(path string) Fingerprint {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return Fingerprint{Exists: false}
+	}
+	f := Fingerprint{
+		Exists:  true,
+		IsDir:   info.IsDir(),
+		ModTime: info.ModTime().UnixNano(),
+	}
+	// Size of a directory is filesystem specific, and not meaningful
+	if !info.IsDir() {
+		f.Size = info.Size()
+	}
+	return f
+}
+
+func (f Fingerprint) Matches(path string) bool {
+	return f == NewFingerprint(path)
+}
+
+// NewItem creates an item for an operation that was just performed
+func NewItem(src string, dst string) Item {
+	f := NewFingerprint(dst)
+	return Item{
+		Src:         src,
+		Dst:         dst,
+		IsDir:       f.IsDir,
+		Fingerprint: f,
+	}
+}
@@ -0,0 +1,170 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lithammer/shortuuid"
+)
+
+func New(filePath string) *Journal {
+	return &Journal{
+		filePath: filePath,
+	}
+}
+
+// Record adds a new operation to the journal. This discards all the undone entries,
+// as they cannot be redone anymore.
+func (j *Journal) Record(opType OperationType, items []Item, sources []string) {
+	j.record(Entry{Type: opType, Items: items, Sources: sources})
+}
+
+// RecordEncryptedCompress adds the compression of an archive encrypted with a password
+func (j *Journal) RecordEncryptedCompress(items []Item, sources []string) {
+	j.record(Entry{Type: CompressOperation, Items: items, Sources: sources, Encrypted: true})
+}
+
+func (j *Journal) record(entry Entry) {
+	if j == nil || len(entry.Items) == 0 {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.load()
+
+	for len(j.entries) > 0 && j.entries[len(j.entries)-1].Undone {
+		j.entries = j.entries[:len(j.entries)-1]
+	}
+	entry.ID = shortuuid.New()
+	entry.Time = time.Now()
+	j.entries = append(j.entries, entry)
+	if len(j.entries) > maxEntries {
+		j.entries = j.entries[len(j.entries)-maxEntries:]
+	}
+	slog.Debug("Recorded operation in journal", "type", entry.Type, "items", len(entry.Items))
+	j.save()
+}
+
+// LastApplied returns the most recent entry that can be undone
+func (j *Journal) LastApplied() (Entry, bool) {
+	if j == nil {
+		return Entry{}, false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.load()
+
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		if !j.entries[i].Undone {
+			return j.entries[i], true
+		}
+	}
+	return Entry{}, false
+}
+
+// FirstUndone returns the oldest undone entry, which is the next one to be redone
+func (j *Journal) FirstUndone() (Entry, bool) {
+	if j == nil {
+		return Entry{}, false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.load()
+
+	for _, e := range j.entries {
+		if e.Undone {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// SetUndone marks the entry as undone or redone, and updates its items to where
+// they are now
+func (j *Journal) SetUndone(id string, undone bool, items []Item) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.load()
+
+	for i := range j.entries {
+		if j.entries[i].ID == id {
+			j.entries[i].Undone = undone
+			j.entries[i].Items = items
+			j.save()
+			return
+		}
+	}
+	slog.Error("Journal entry not found", "id", id)
+}
+
+// Must be called with lock held
+func (j *Journal) load() {
+	if j.loaded {
+		return
+	}
+	j.loaded = true
+	if j.filePath == "" {
+		return
+	}
+	data, err := os.ReadFile(j.filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		slog.Error("Error reading journal file", "error", err)
+		return
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			slog.Error("Skipping invalid journal entry", "error", err)
+			continue
+		}
+		j.entries = append(j.entries, e)
+	}
+}
+
+// Must be called with lock held
+func (j *Journal) save() {
+	if j.filePath == "" {
+		return
+	}
+	if err := j.writeEntries(); err != nil {
+		slog.Error("Error writing journal file", "error", err)
+	}
+}
+
+func (j *Journal) writeEntries() error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, e := range j.entries {
+		if err := encoder.Encode(e); err != nil {
+			return fmt.Errorf("error encoding journal entry : %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(j.filePath), 0o755); err != nil {
+		return err
+	}
+	// Write to a temporary file first, so that a crash does not leave a truncated journal
+	tmpFile := j.filePath + ".tmp"
+	if err := os.WriteFile(tmpFile, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, j.filePath)
+}
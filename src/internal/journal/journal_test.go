@@ -0,0 +1,103 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func TestJournalPersistence(t *testing.T) {
+	curTestDir := t.TempDir()
+	journalFile := filepath.Join(curTestDir, "journal.jsonl")
+	file1 := filepath.Join(curTestDir, "file1.txt")
+	utils.SetupFiles(t, file1)
+
+	j := New(journalFile)
+	j.Record(CreateOperation, []Item{NewItem("", file1)}, nil)
+	j.Record(RenameOperation, nil, nil)
+	entry, ok := j.LastApplied()
+	require.True(t, ok)
+	j.SetUndone(entry.ID, true, entry.Items)
+
+	reloaded := New(journalFile)
+	_, ok = reloaded.LastApplied()
+	assert.False(t, ok, "Undone entry should not be returned as applied")
+	undone, ok := reloaded.FirstUndone()
+	require.True(t, ok)
+	assert.Equal(t, entry.ID, undone.ID)
+	assert.Equal(t, CreateOperation, undone.Type)
+	assert.Equal(t, []Item{NewItem("", file1)}, undone.Items)
+}
+
+func TestJournalRecord(t *testing.T) {
+	t.Run("Recording discards undone entries", func(t *testing.T) {
+		j := New("")
+		j.Record(CreateOperation, []Item{{Dst: "/a"}}, nil)
+		j.Record(CreateOperation, []Item{{Dst: "/b"}}, nil)
+		entry, ok := j.LastApplied()
+		require.True(t, ok)
+		j.SetUndone(entry.ID, true, entry.Items)
+
+		j.Record(CreateOperation, []Item{{Dst: "/c"}}, nil)
+		_, ok = j.FirstUndone()
+		assert.False(t, ok, "Nothing should be left to redo")
+		entry, ok = j.LastApplied()
+		require.True(t, ok)
+		assert.Equal(t, "/c", entry.Items[0].Dst)
+	})
+
+	t.Run("Old entries are trimmed", func(t *testing.T) {
+		j := New("")
+		for range maxEntries + 5 {
+			j.Record(CreateOperation, []Item{{Dst: "/a"}}, nil)
+		}
+		assert.Len(t, j.entries, maxEntries)
+	})
+
+	t.Run("Nil journal", func(t *testing.T) {
+		var j *Journal
+		j.Record(CreateOperation, []Item{{Dst: "/a"}}, nil)
+		_, ok := j.LastApplied()
+		assert.False(t, ok)
+	})
+}
+
+func TestVerifyUnchanged(t *testing.T) {
+	curTestDir := t.TempDir()
+	src := filepath.Join(curTestDir, "src.txt")
+	dst := filepath.Join(curTestDir, "dst.txt")
+	utils.SetupFilesWithData(t, []byte("data"), dst)
+
+	entry := Entry{Type: MoveOperation, Items: []Item{NewItem(src, dst)}}
+	require.NoError(t, entry.VerifyUnchanged())
+
+	t.Run("Modified file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(dst, []byte("more data"), 0644))
+		t.Cleanup(func() {
+			entry.Items[0] = NewItem(src, dst)
+		})
+		var pathChangedErr *PathChangedError
+		require.ErrorAs(t, entry.VerifyUnchanged(), &pathChangedErr)
+	})
+
+	t.Run("Original location is taken", func(t *testing.T) {
+		utils.SetupFiles(t, src)
+		t.Cleanup(func() {
+			require.NoError(t, os.Remove(src))
+		})
+		var pathExistsErr *PathExistsError
+		require.ErrorAs(t, entry.VerifyUnchanged(), &pathExistsErr)
+	})
+
+	t.Run("Undone entry", func(t *testing.T) {
+		undone := Entry{Type: MoveOperation, Undone: true, Items: []Item{{Src: src, Dst: dst,
+			Fingerprint: NewFingerprint(src)}}}
+		var pathExistsErr *PathExistsError
+		require.ErrorAs(t, undone.VerifyUnchanged(), &pathExistsErr, "Redo must not overwrite the destination")
+	})
+}
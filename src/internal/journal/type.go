@@ -0,0 +1,65 @@
+package journal
+
+import (
+	"sync"
+	"time"
+)
+
+type OperationType string
+
+const (
+	RenameOperation   OperationType = "rename"
+	CreateOperation   OperationType = "create"
+	MoveOperation     OperationType = "move"
+	TrashOperation    OperationType = "trash"
+	CompressOperation OperationType = "compress"
+	SymlinkOperation  OperationType = "symlink"
+	HardlinkOperation OperationType = "hardlink"
+)
+
+// Fingerprint is a snapshot of a path's metadata, used to detect whether
+// it was changed after an operation was recorded
+type Fingerprint struct {
+	Exists  bool  `json:"exists"`
+	IsDir   bool  `json:"is_dir"`
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mod_time"`
+}
+
+// Item is a single path affected by an operation.
+// For rename, move and trash, the item is moved from Src to Dst.
+// For create and compress, Src is empty and Dst is the created path.
+// For symlink and hardlink, Src is the target of the link and Dst is the link.
+type Item struct {
+	Src   string `json:"src"`
+	Dst   string `json:"dst"`
+	IsDir bool   `json:"is_dir"`
+	// Fingerprint of the item at its current location. That is Dst when the
+	// entry is applied, and Src after it is undone (Dst for create and compress)
+	Fingerprint Fingerprint `json:"fingerprint"`
+}
+
+type Entry struct {
+	ID    string        `json:"id"`
+	Type  OperationType `json:"type"`
+	Time  time.Time     `json:"time"`
+	Items []Item        `json:"items"`
+	// Sources used to create the archive. Only for compress
+	Sources []string `json:"sources,omitempty"`
+	// Whether the archive was encrypted. Its password is not recorded, so the compression
+	// cannot be redone. Only for compress
+	Encrypted bool `json:"encrypted,omitempty"`
+	Undone    bool `json:"undone"`
+}
+
+// Journal is a persistent list of file operations that can be undone and redone.
+// Entries are kept in order they were performed. All the undone entries are always
+// at the end, and are discarded once a new operation is recorded.
+// All methods are safe for concurrent use, and are no-op on a nil Journal.
+type Journal struct {
+	mu sync.Mutex
+	// File to persist entries to, one JSON object per line. Not persisted if empty
+	filePath string
+	loaded   bool
+	entries  []Entry
+}
@@ -0,0 +1,52 @@
+package journal
+
+import (
+	"os"
+)
+
+// Whether items of this operation are moved from Src to Dst, or just created at Dst
+func (e Entry) isMove() bool {
+	return e.Type == RenameOperation || e.Type == MoveOperation || e.Type == TrashOperation
+}
+
+// CurrentPath returns where the item is now, based on whether the entry is undone
+func (e Entry) CurrentPath(item Item) string {
+	if e.Undone && e.isMove() {
+		return item.Src
+	}
+	return item.Dst
+}
+
+// VerifyUnchanged makes sure that nothing was modified since the entry was last applied
+// or undone, and that reversing it would not overwrite anything. Renamed items can take
+// each other's place, like when names were swapped
+func (e Entry) VerifyUnchanged() error {
+	curPaths := make(map[string]bool, len(e.Items))
+	for _, item := range e.Items {
+		curPaths[e.CurrentPath(item)] = true
+	}
+	for _, item := range e.Items {
+		curPath := e.CurrentPath(item)
+		if !item.Fingerprint.Matches(curPath) {
+			return &PathChangedError{path: curPath}
+		}
+		if !e.isMove() {
+			continue
+		}
+		targetPath := item.Src
+		if e.Undone {
+			targetPath = item.Dst
+			// Items are trashed again under a new name, so there's nothing to verify
+			if e.Type == TrashOperation {
+				continue
+			}
+		}
+		if e.Type == RenameOperation && curPaths[targetPath] {
+			continue
+		}
+		if _, err := os.Lstat(targetPath); err == nil {
+			return &PathExistsError{path: targetPath}
+		}
+	}
+	return nil
+}
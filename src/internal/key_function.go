@@ -18,6 +18,9 @@ import (
 // TODO: This function has grown too big. It needs to be fixed, via major
 // updates and fixes in key handling code
 func (m *model) mainKey(msg string) tea.Cmd { //nolint: gocyclo,cyclop,funlen // See above
+	if m.archiveReadOnlyKey(msg) {
+		return nil
+	}
 	switch {
 	// If move up Key is pressed, check the current state and executes
 	case slices.Contains(common.Hotkeys.ListUp, msg):
@@ -54,6 +57,12 @@ func (m *model) mainKey(msg string) tea.Cmd { //nolint: gocyclo,cyclop,funlen //
 	case slices.Contains(common.Hotkeys.ChangePanelMode, msg):
 		m.getFocusedFilePanel().changeFilePanelMode()
 
+	case slices.Contains(common.Hotkeys.ToggleTreeView, msg):
+		m.toggleTreeView()
+
+	case slices.Contains(common.Hotkeys.ToggleFlatView, msg):
+		m.toggleFlatView()
+
 	case slices.Contains(common.Hotkeys.NextFilePanel, msg):
 		m.nextFilePanel()
 
@@ -83,6 +92,27 @@ func (m *model) mainKey(msg string) tea.Cmd { //nolint: gocyclo,cyclop,funlen //
 	case slices.Contains(common.Hotkeys.PasteItems, msg):
 		return m.getPasteItemCmd()
 
+	case slices.Contains(common.Hotkeys.PasteAsSymlink, msg):
+		return m.getPasteLinkCmd(symlinkAbsolute)
+
+	case slices.Contains(common.Hotkeys.PasteAsRelativeSymlink, msg):
+		return m.getPasteLinkCmd(symlinkRelative)
+
+	case slices.Contains(common.Hotkeys.PasteAsHardlink, msg):
+		return m.getPasteLinkCmd(hardlink)
+
+	case slices.Contains(common.Hotkeys.CopyToNextPanel, msg):
+		return m.copyToNextPanel(false)
+
+	case slices.Contains(common.Hotkeys.MoveToNextPanel, msg):
+		return m.copyToNextPanel(true)
+
+	case slices.Contains(common.Hotkeys.Undo, msg):
+		return m.getUndoCmd()
+
+	case slices.Contains(common.Hotkeys.Redo, msg):
+		return m.getRedoCmd()
+
 	case slices.Contains(common.Hotkeys.FilePanelItemCreate, msg):
 		m.panelCreateNewFile()
 	case slices.Contains(common.Hotkeys.PinnedDirectory, msg):
@@ -95,10 +125,10 @@ func (m *model) mainKey(msg string) tea.Cmd { //nolint: gocyclo,cyclop,funlen //
 		return m.toggleFooterController()
 
 	case slices.Contains(common.Hotkeys.ExtractFile, msg):
-		return m.getExtractFileCmd()
+		m.openExtractModal()
 
 	case slices.Contains(common.Hotkeys.CompressFile, msg):
-		return m.getCompressSelectedFilesCmd()
+		m.openCompressModal()
 
 	case slices.Contains(common.Hotkeys.OpenCommandLine, msg):
 		m.promptModal.Open(true)
@@ -106,6 +136,12 @@ func (m *model) mainKey(msg string) tea.Cmd { //nolint: gocyclo,cyclop,funlen //
 		m.promptModal.Open(false)
 	case slices.Contains(common.Hotkeys.OpenZoxide, msg):
 		return m.zoxideModal.Open()
+	case slices.Contains(common.Hotkeys.OpenTrash, msg):
+		m.trashModal.Open()
+	case slices.Contains(common.Hotkeys.OpenContentSearch, msg):
+		m.openContentSearch()
+	case slices.Contains(common.Hotkeys.OpenProcessHistory, msg):
+		m.processBarModel.OpenProcessHistory()
 
 	case slices.Contains(common.Hotkeys.OpenHelpMenu, msg):
 		m.openHelpMenu()
@@ -141,6 +177,24 @@ func (m *model) normalAndBrowserModeKey(msg string) tea.Cmd {
 		if m.focusPanel == sidebarFocus && slices.Contains(common.Hotkeys.SearchBar, msg) {
 			m.sidebarSearchBarFocus()
 		}
+		if m.focusPanel == processBarFocus && slices.Contains(common.Hotkeys.CancelProcess, msg) {
+			m.processBarModel.CancelSelectedProcess()
+		}
+		if m.focusPanel == processBarFocus && slices.Contains(common.Hotkeys.TogglePauseProcess, msg) {
+			m.processBarModel.TogglePauseSelectedProcess()
+		}
+		if m.focusPanel == processBarFocus && slices.Contains(common.Hotkeys.Confirm, msg) {
+			m.processBarModel.OpenSelectedProcessDetails()
+		}
+		if m.focusPanel == processBarFocus && slices.Contains(common.Hotkeys.MoveProcessUp, msg) {
+			m.processBarModel.MoveSelectedProcessInQueue(-1)
+		}
+		if m.focusPanel == processBarFocus && slices.Contains(common.Hotkeys.MoveProcessDown, msg) {
+			m.processBarModel.MoveSelectedProcessInQueue(1)
+		}
+		if m.focusPanel == processBarFocus && slices.Contains(common.Hotkeys.PrioritizeProcess, msg) {
+			m.processBarModel.PrioritizeSelectedProcess()
+		}
 		return nil
 	}
 	// Check if in the select mode and focusOn filepanel
@@ -162,10 +216,38 @@ func (m *model) normalAndBrowserModeKey(msg string) tea.Cmd {
 			m.copyMultipleItem(true)
 		case slices.Contains(common.Hotkeys.FilePanelSelectAllItem, msg):
 			m.selectAllItem()
+		case slices.Contains(common.Hotkeys.BulkRename, msg):
+			return m.getBulkRenameCmd()
+		case slices.Contains(common.Hotkeys.PatternRename, msg):
+			return m.openPatternRename()
 		}
 		return nil
 	}
 
+	// The tree and flat views browse like the normal mode, and select like the select mode
+	if mode := m.getFocusedFilePanel().panelMode; mode == treeMode || mode == flatMode {
+		switch {
+		case slices.Contains(common.Hotkeys.Confirm, msg) && mode == treeMode:
+			m.treeEnter()
+			return nil
+		case slices.Contains(common.Hotkeys.Confirm, msg):
+			m.flatEnter()
+			return nil
+		case slices.Contains(common.Hotkeys.ParentDirectory, msg) && mode == treeMode:
+			m.treeParent()
+			return nil
+		case slices.Contains(common.Hotkeys.FilePanelSelectModeItemsSelectUp, msg):
+			m.fileModel.filePanels[m.filePanelFocusIndex].itemSelectUp(m.mainPanelHeight)
+			return nil
+		case slices.Contains(common.Hotkeys.FilePanelSelectModeItemsSelectDown, msg):
+			m.fileModel.filePanels[m.filePanelFocusIndex].itemSelectDown(m.mainPanelHeight)
+			return nil
+		case slices.Contains(common.Hotkeys.FilePanelSelectAllItem, msg):
+			m.selectAllItem()
+			return nil
+		}
+	}
+
 	switch {
 	case slices.Contains(common.Hotkeys.Confirm, msg):
 		m.enterPanel()
@@ -176,9 +258,9 @@ func (m *model) normalAndBrowserModeKey(msg string) tea.Cmd {
 	case slices.Contains(common.Hotkeys.PermanentlyDeleteItems, msg):
 		return m.getDeleteTriggerCmd(true)
 	case slices.Contains(common.Hotkeys.CopyItems, msg):
-		m.copySingleItem(false)
+		m.copyPanelItems(false)
 	case slices.Contains(common.Hotkeys.CutItems, msg):
-		m.copySingleItem(true)
+		m.copyPanelItems(true)
 	case slices.Contains(common.Hotkeys.FilePanelItemRename, msg):
 		m.panelItemRename()
 	case slices.Contains(common.Hotkeys.SearchBar, msg):
@@ -203,6 +285,20 @@ func (m *model) typingModalOpenKey(msg string) {
 }
 
 func (m *model) notifyModelOpenKey(msg string) tea.Cmd {
+	if m.notifyModel.HasChoices() {
+		switch {
+		case slices.Contains(common.Hotkeys.ListUp, msg):
+			m.notifyModel.ChoiceUp()
+			return nil
+		case slices.Contains(common.Hotkeys.ListDown, msg):
+			m.notifyModel.ChoiceDown()
+			return nil
+		case slices.Contains(common.Hotkeys.FilePanelSelectAllItem, msg):
+			m.notifyModel.ToggleApplyToAll()
+			return nil
+		}
+	}
+
 	isCancel := slices.Contains(common.Hotkeys.CancelTyping, msg) || slices.Contains(common.Hotkeys.Quit, msg)
 	isConfirm := slices.Contains(common.Hotkeys.Confirm, msg)
 
@@ -224,7 +320,11 @@ func (m *model) handleNotifyModelCancel(action notify.ConfirmActionType) tea.Cmd
 		m.cancelRename()
 	case notify.QuitAction:
 		m.modelQuitState = notQuitting
-	case notify.DeleteAction, notify.NoAction, notify.PermanentDeleteAction:
+	case notify.PasteConflictAction:
+		// Cancelling the dialog cancels the whole paste operation
+		m.pendingPaste = nil
+	case notify.DeleteAction, notify.NoAction, notify.PermanentDeleteAction,
+		notify.DeleteTrashItemAction, notify.EmptyTrashAction:
 		// Do nothing
 	default:
 		slog.Error("Unknown type of action", "action", action)
@@ -242,6 +342,12 @@ func (m *model) handleNotifyModelConfirm(action notify.ConfirmActionType) tea.Cm
 		m.confirmRename()
 	case notify.QuitAction:
 		m.modelQuitState = quitConfirmationReceived
+	case notify.PasteConflictAction:
+		return m.resolvePasteConflict(m.notifyModel.GetChoice(), m.notifyModel.IsApplyToAll())
+	case notify.DeleteTrashItemAction:
+		return m.getDeleteTrashItemsCmd(false)
+	case notify.EmptyTrashAction:
+		return m.getDeleteTrashItemsCmd(true)
 	case notify.NoAction:
 		// Ignore
 	default:
@@ -299,6 +405,55 @@ func (m *model) focusOnSearchbarKey(msg string) {
 	}
 }
 
+// Handles key inputs inside the process details. Possible actions are scrolling,
+// retrying the failed process and closing the details
+func (m *model) processDetailsKey(msg string) tea.Cmd {
+	switch {
+	case slices.Contains(common.Hotkeys.Quit, msg), slices.Contains(common.Hotkeys.Confirm, msg),
+		slices.Contains(common.Hotkeys.CancelTyping, msg):
+		m.processBarModel.CloseProcessDetails()
+	case slices.Contains(common.Hotkeys.ListUp, msg):
+		m.processBarModel.ProcessDetailsScrollUp()
+	case slices.Contains(common.Hotkeys.ListDown, msg):
+		m.processBarModel.ProcessDetailsScrollDown()
+	case slices.Contains(common.Hotkeys.RetryProcess, msg):
+		return m.getRetryProcessCmd()
+	}
+	return nil
+}
+
+// Handles key inputs inside the process history
+func (m *model) processHistoryKey(msg string) {
+	switch {
+	case slices.Contains(common.Hotkeys.Quit, msg), slices.Contains(common.Hotkeys.CancelTyping, msg),
+		slices.Contains(common.Hotkeys.OpenProcessHistory, msg):
+		m.processBarModel.CloseProcessHistory()
+	case slices.Contains(common.Hotkeys.ListUp, msg):
+		m.processBarModel.ProcessHistoryListUp()
+	case slices.Contains(common.Hotkeys.ListDown, msg):
+		m.processBarModel.ProcessHistoryListDown()
+	}
+}
+
+// Handles key inputs inside the trash browser
+func (m *model) trashModalKey(msg string) tea.Cmd {
+	switch {
+	case slices.Contains(common.Hotkeys.Quit, msg), slices.Contains(common.Hotkeys.CancelTyping, msg):
+		m.trashModal.Close()
+	case slices.Contains(common.Hotkeys.ListUp, msg):
+		m.trashModal.ListUp()
+	case slices.Contains(common.Hotkeys.ListDown, msg):
+		m.trashModal.ListDown()
+	case slices.Contains(common.Hotkeys.Confirm, msg):
+		return m.getRestoreTrashItemCmd()
+	case slices.Contains(common.Hotkeys.PermanentlyDeleteItems, msg):
+		return m.getDeleteTrashItemsTriggerCmd(false)
+	case slices.Contains(common.Hotkeys.EmptyTrash, msg):
+		return m.getDeleteTrashItemsTriggerCmd(true)
+	}
+	return nil
+}
+
 // Check hotkey input in help menu. Possible actions are moving up, down
 // and quiting the menu
 func (m *model) helpMenuKey(msg string) {
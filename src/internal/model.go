@@ -4,13 +4,13 @@ import (
 	"errors"
 	"log/slog"
 	"os"
-	"path/filepath"
 	"reflect"
 	"slices"
 	"strings"
 	"time"
 
 	"github.com/yorukot/superfile/src/config/icon"
+	"github.com/yorukot/superfile/src/internal/archivefs"
 	"github.com/yorukot/superfile/src/internal/common"
 	"github.com/yorukot/superfile/src/internal/ui/metadata"
 	"github.com/yorukot/superfile/src/internal/ui/notify"
@@ -53,6 +53,8 @@ func (m *model) Init() tea.Cmd {
 		tea.SetWindowTitle("superfile"),
 		textinput.Blink, // Assuming textinput.Blink is a valid command
 		processCmdToTeaCmd(m.processBarModel.GetListenCmd()),
+		m.getWatcherCmd(),
+		m.getFlatListingCmd(),
 	)
 }
 
@@ -134,6 +136,8 @@ func (m *model) handleMouseMsg(msg tea.MouseMsg) {
 
 func (m *model) updateModelStateAfterMsg() {
 	m.sidebarModel.UpdateDirectories()
+	// Finished processes can expire without any process update
+	m.processBarModel.RemoveExpiredProcesses()
 	m.getFilePanelItems()
 	// TODO: Move to utility
 	if m.focusPanel != metadataFocus {
@@ -233,6 +237,10 @@ func (m *model) handleWindowResize(msg tea.WindowSizeMsg) {
 	m.setProcessBarModelSize()
 	m.setPromptModelSize()
 	m.setZoxideModelSize()
+	m.setTrashModelSize()
+	m.setContentSearchModalSize()
+	m.setRenamePlanModalSize()
+	m.setPatternRenameModalSize()
 
 	if m.fileModel.maxFilePanel >= 10 {
 		m.fileModel.maxFilePanel = 10
@@ -248,16 +256,36 @@ func (m *model) setFilePreviewPanelSize() {
 func (m *model) getFilePreviewWidth() int {
 	if common.Config.FilePreviewWidth == 0 {
 		return (m.fullWidth - common.Config.SidebarWidth -
-			(4 + (len(m.fileModel.filePanels))*2)) / (len(m.fileModel.filePanels) + 1)
+			(4 + m.filePanelColumnCnt()*2)) / (m.filePanelColumnCnt() + 1)
 	}
 	return (m.fullWidth - common.Config.SidebarWidth) / common.Config.FilePreviewWidth
 }
 
+// Number of file panel columns shown side by side. The Miller columns layout only shows the
+// focused panel, after the column of its parent directory
+func (m *model) filePanelColumnCnt() int {
+	if common.Config.FilePanelLayout == common.FilePanelLayoutMiller {
+		return 2
+	}
+	return len(m.fileModel.filePanels)
+}
+
+// Width of the i-th file panel column. The last one also takes the width left over by the
+// division, unless the file preview follows it
+func (m *model) filePanelColumnWidth(i int) int {
+	cnt := m.filePanelColumnCnt()
+	leftOver := (m.fullWidth - common.Config.SidebarWidth - (4 + (cnt-1)*2)) % cnt
+	if i == cnt-1 && !m.fileModel.filePreview.IsOpen() {
+		return m.fileModel.width + leftOver
+	}
+	return m.fileModel.width
+}
+
 // Proper set panels size. Assure that panels do not overlap
 func (m *model) setFilePanelsSize(width int) {
 	// set each file panel size and max file panel amount
 	m.fileModel.width = (width - common.Config.SidebarWidth - m.fileModel.filePreview.GetWidth() -
-		(4 + (len(m.fileModel.filePanels)-1)*2)) / len(m.fileModel.filePanels)
+		(4 + (m.filePanelColumnCnt()-1)*2)) / m.filePanelColumnCnt()
 	m.fileModel.maxFilePanel = (width - common.Config.SidebarWidth - m.fileModel.filePreview.GetWidth()) / 20
 	for i := range m.fileModel.filePanels {
 		m.fileModel.filePanels[i].searchBar.Width = m.fileModel.width - 4
@@ -323,6 +351,28 @@ func (m *model) setZoxideModelSize() {
 	m.zoxideModal.SetWidth(m.fullWidth / 2)
 }
 
+func (m *model) setTrashModelSize() {
+	m.trashModal.SetMaxHeight(m.fullHeight / 2)
+	m.trashModal.SetWidth(m.fullWidth / 2)
+}
+
+func (m *model) setContentSearchModalSize() {
+	m.contentSearchModal.SetMaxHeight(m.fullHeight / 2)
+	m.contentSearchModal.SetWidth(m.fullWidth * 2 / 3)
+}
+
+func (m *model) setRenamePlanModalSize() {
+	m.renamePlanModal.height = m.fullHeight / 2
+	m.renamePlanModal.width = m.fullWidth / 2
+}
+
+func (m *model) setPatternRenameModalSize() {
+	m.patternRenameModal.height = m.fullHeight / 2
+	m.patternRenameModal.width = m.fullWidth / 2
+	m.patternRenameModal.find.Width = m.patternRenameModal.width - 12
+	m.patternRenameModal.replace.Width = m.patternRenameModal.width - 12
+}
+
 func (m *model) setMetadataModelSize() {
 	m.fileMetaData.SetDimensions(utils.FooterWidth(m.fullWidth)+2, m.footerHeight+2)
 }
@@ -330,6 +380,7 @@ func (m *model) setMetadataModelSize() {
 // TODO: Remove this code duplication with footer models
 func (m *model) setProcessBarModelSize() {
 	m.processBarModel.SetDimensions(utils.FooterWidth(m.fullWidth)+2, m.footerHeight+2)
+	m.processBarModel.SetProcessHistoryDimensions(m.fullWidth/2, m.fullHeight/2)
 }
 
 // Identify the current state of the application m and properly handle the
@@ -388,6 +439,26 @@ func (m *model) handleKeyInput(msg tea.KeyMsg) tea.Cmd {
 	// If help menu is open
 	case m.helpMenu.open:
 		m.helpMenuKey(msg.String())
+	case m.processBarModel.IsProcessDetailsOpen():
+		cmd = m.processDetailsKey(msg.String())
+	case m.processBarModel.IsProcessHistoryOpen():
+		m.processHistoryKey(msg.String())
+	case m.trashModal.IsOpen():
+		cmd = m.trashModalKey(msg.String())
+	case m.contentSearchModal.IsOpen():
+		cmd = m.contentSearchModalKey(msg)
+	case m.renamePlanModal.open:
+		cmd = m.renamePlanKey(msg.String())
+	case m.patternRenameModal.open:
+		cmd = m.patternRenameKey(msg)
+	case m.compressModal.open:
+		cmd = m.compressModalKey(msg.String())
+	case m.extractModal.open:
+		cmd = m.extractModalKey(msg.String())
+	case m.passwordModal.open:
+		cmd = m.passwordModalKey(msg.String())
+	case m.panelPickerModal.open:
+		cmd = m.panelPickerKey(msg.String())
 
 	case slices.Contains(common.Hotkeys.Quit, msg.String()):
 		m.modelQuitState = quitInitiated
@@ -419,6 +490,12 @@ func (m *model) handleKeyInput(msg tea.KeyMsg) tea.Cmd {
 	return cmd
 }
 
+// ignoreOpeningKey stops the key that opened a modal from being typed into its text input,
+// as the key is also passed to updateFilePanelsState
+func (m *model) ignoreOpeningKey() {
+	m.firstTextInput = true
+}
+
 // Update the file panel state. Change name of renamed files, filter out files
 // in search, update typingb bar, etc
 func (m *model) updateFilePanelsState(msg tea.Msg) tea.Cmd {
@@ -434,6 +511,14 @@ func (m *model) updateFilePanelsState(msg tea.Msg) tea.Cmd {
 		focusPanel.searchBar, cmd = focusPanel.searchBar.Update(msg)
 	case m.typingModal.open:
 		m.typingModal.textInput, cmd = m.typingModal.textInput.Update(msg)
+	case m.patternRenameModal.open:
+		cmd = tea.Batch(m.patternRenameModal.updateInput(msg), m.getExifDatesCmd())
+	case m.contentSearchModal.IsOpen():
+		cmd = m.contentSearchModal.UpdateInput(msg)
+	case m.extractModal.open:
+		m.extractModal.dest, cmd = m.extractModal.dest.Update(msg)
+	case m.passwordModal.open:
+		m.passwordModal.textInput, cmd = m.passwordModal.textInput.Update(msg)
 	case m.promptModal.IsOpen():
 		// TODO : Separate this to a utility
 		cwdLocation := m.fileModel.filePanels[m.filePanelFocusIndex].location
@@ -495,7 +580,8 @@ func (m *model) applyZoxideModalAction(action common.ModelAction) {
 
 // TODO : Move them around to appropriate places
 func (m *model) applyShellCommandAction(shellCommand string) {
-	focusPanelDir := m.fileModel.filePanels[m.filePanelFocusIndex].location
+	// Commands cannot run inside archives, they run where the archive is
+	focusPanelDir := archivefs.OuterDir(m.fileModel.filePanels[m.filePanelFocusIndex].location)
 
 	retCode, output, err := utils.ExecuteCommandInShell(common.DefaultCommandTimeout, focusPanelDir, shellCommand)
 
@@ -530,7 +616,8 @@ func (m *model) updateCurrentFilePanelDir(path string) error {
 
 // trackDirectoryWithZoxide adds the directory to zoxide database if zoxide is available and enabled
 func (m *model) trackDirectoryWithZoxide(path string) {
-	if !common.Config.ZoxideSupport || m.zClient == nil {
+	// Directories inside archives cannot be entered outside of superfile
+	if !common.Config.ZoxideSupport || m.zClient == nil || archivefs.IsArchivePath(path) {
 		return
 	}
 
@@ -659,9 +746,83 @@ func (m *model) updateRenderForOverlay(finalRender string) string {
 	if m.notifyModel.IsOpen() {
 		notifyModal := m.notifyModel.Render()
 		overlayX := m.fullWidth/2 - common.ModalWidth/2
-		overlayY := m.fullHeight/2 - common.ModalHeight/2
+		overlayY := m.fullHeight/2 - lipgloss.Height(notifyModal)/2
 		return stringfunction.PlaceOverlay(overlayX, overlayY, notifyModal, finalRender)
 	}
+
+	// After the notify modal, as it takes the keys first. A paste conflict or an error can
+	// open it while the details or the history are shown
+	if m.processBarModel.IsProcessDetailsOpen() {
+		processDetails := m.processBarModel.RenderProcessDetails()
+		overlayX := m.fullWidth/2 - lipgloss.Width(processDetails)/2
+		overlayY := m.fullHeight/2 - lipgloss.Height(processDetails)/2
+		return stringfunction.PlaceOverlay(overlayX, overlayY, processDetails, finalRender)
+	}
+
+	if m.processBarModel.IsProcessHistoryOpen() {
+		processHistory := m.processBarModel.RenderProcessHistory()
+		width, height := m.processBarModel.GetProcessHistoryDimensions()
+		overlayX := m.fullWidth/2 - width/2
+		overlayY := m.fullHeight/2 - height/2
+		return stringfunction.PlaceOverlay(overlayX, overlayY, processHistory, finalRender)
+	}
+
+	// After the notify modal, as deleting from the trash asks for a confirmation over it
+	if m.trashModal.IsOpen() {
+		trashModal := m.trashModal.Render()
+		overlayX := m.fullWidth/2 - m.trashModal.GetWidth()/2
+		overlayY := m.fullHeight/2 - m.trashModal.GetMaxHeight()/2
+		return stringfunction.PlaceOverlay(overlayX, overlayY, trashModal, finalRender)
+	}
+
+	if m.contentSearchModal.IsOpen() {
+		contentSearchModal := m.contentSearchModal.Render()
+		overlayX := m.fullWidth/2 - m.contentSearchModal.GetWidth()/2
+		overlayY := m.fullHeight/2 - m.contentSearchModal.GetMaxHeight()/2
+		return stringfunction.PlaceOverlay(overlayX, overlayY, contentSearchModal, finalRender)
+	}
+
+	if m.renamePlanModal.open {
+		renamePlan := m.renamePlanRender()
+		overlayX := m.fullWidth/2 - m.renamePlanModal.width/2
+		overlayY := m.fullHeight/2 - m.renamePlanModal.height/2
+		return stringfunction.PlaceOverlay(overlayX, overlayY, renamePlan, finalRender)
+	}
+
+	if m.patternRenameModal.open {
+		patternRename := m.patternRenameRender()
+		overlayX := m.fullWidth/2 - m.patternRenameModal.width/2
+		overlayY := m.fullHeight/2 - m.patternRenameModal.height/2
+		return stringfunction.PlaceOverlay(overlayX, overlayY, patternRename, finalRender)
+	}
+
+	if m.compressModal.open {
+		compress := m.compressModalRender()
+		overlayX := m.fullWidth/2 - compressModalWidth/2
+		overlayY := m.fullHeight/2 - compressModalHeight/2
+		return stringfunction.PlaceOverlay(overlayX, overlayY, compress, finalRender)
+	}
+
+	if m.extractModal.open {
+		extract := m.extractModalRender()
+		overlayX := m.fullWidth/2 - extractModalWidth/2
+		overlayY := m.fullHeight/2 - extractModalHeight/2
+		return stringfunction.PlaceOverlay(overlayX, overlayY, extract, finalRender)
+	}
+
+	if m.passwordModal.open {
+		password := m.passwordModalRender()
+		overlayX := m.fullWidth/2 - common.ModalWidth/2
+		overlayY := m.fullHeight/2 - common.ModalHeight/2
+		return stringfunction.PlaceOverlay(overlayX, overlayY, password, finalRender)
+	}
+
+	if m.panelPickerModal.open {
+		panelPicker := m.panelPickerRender()
+		overlayX := m.fullWidth/2 - panelPickerWidth/2
+		overlayY := m.fullHeight/2 - m.panelPickerModal.height()/2
+		return stringfunction.PlaceOverlay(overlayX, overlayY, panelPicker, finalRender)
+	}
 	return finalRender
 }
 
@@ -693,26 +854,33 @@ func getMaxW(s string) int {
 	return maxW
 }
 
-// Render and update file panel items. Check for changes and updates in files and
-// folders in the current directory.
+// Render and update file panel items. The panels are read again as soon as what they show
+// changes, and when the watcher reports a change in their directory. The directories that
+// cannot be watched are polled instead
 func (m *model) getFilePanelItems() {
+	m.listFlatPanels()
+	m.watchFilePanels()
 	focusPanel := m.fileModel.filePanels[m.filePanelFocusIndex]
-	for i, filePanel := range m.fileModel.filePanels {
-		var fileElement []element
+	for i := range m.fileModel.filePanels {
+		filePanel := &m.fileModel.filePanels[i]
+		if filePanel.getElementSource(m.toggleDotFile) != filePanel.lastElementSource {
+			filePanel.readElements(m.toggleDotFile)
+			continue
+		}
+		if m.isWatched(filePanel) {
+			continue
+		}
+
 		nowTime := time.Now()
 		// Check last time each element was updated, if less then 3 seconds ignore
 		if !filePanel.isFocused && nowTime.Sub(filePanel.lastTimeGetElement) < 3*time.Second {
-			// TODO : revisit this. This feels like a duct tape solution of an actual
-			// deep rooted problem. This feels very hacky.
-			if !m.updatedToggleDotFile {
-				continue
-			}
+			continue
 		}
 
 		focusPanelReRender := false
 
 		if len(focusPanel.element) > 0 {
-			if filepath.Dir(focusPanel.element[0].location) != focusPanel.location {
+			if archivefs.Dir(focusPanel.element[0].location) != focusPanel.location {
 				focusPanelReRender = true
 			}
 		} else {
@@ -726,20 +894,37 @@ func (m *model) getFilePanelItems() {
 			continue
 		}
 
-		// Get file names based on search bar filter
-		if filePanel.searchBar.Value() != "" {
-			fileElement = returnDirElementBySearchString(filePanel.location, m.toggleDotFile,
-				filePanel.searchBar.Value(), filePanel.sortOptions.data)
-		} else {
-			fileElement = returnDirElement(filePanel.location, m.toggleDotFile, filePanel.sortOptions.data)
-		}
-		// Update file panel list
-		filePanel.element = fileElement
-		m.fileModel.filePanels[i].element = fileElement
-		m.fileModel.filePanels[i].lastTimeGetElement = nowTime
+		filePanel.readElements(m.toggleDotFile)
 	}
+	m.getParentColumnItems()
+}
 
-	m.updatedToggleDotFile = false
+// Reads the parent column of the Miller columns layout, with its cursor on the directory of
+// the focused panel. It is empty at the root
+func (m *model) getParentColumnItems() {
+	if common.Config.FilePanelLayout != common.FilePanelLayoutMiller {
+		return
+	}
+	focusPanel := m.getFocusedFilePanel()
+	column := &m.fileModel.parentColumn
+	column.location = archivefs.Dir(focusPanel.location)
+	column.sortOptions.data = focusPanel.sortOptions.data
+	if column.location == focusPanel.location {
+		column.location = ""
+		column.element = nil
+		return
+	}
+
+	if column.getElementSource(m.toggleDotFile) != column.lastElementSource ||
+		(!m.isWatched(column) && time.Since(column.lastTimeGetElement) >= 3*time.Second) {
+		column.readElements(m.toggleDotFile)
+	}
+	for i, item := range column.element {
+		if item.location == focusPanel.location {
+			column.moveCursorTo(i, m.mainPanelHeight)
+			break
+		}
+	}
 }
 
 // Close superfile application. Cd into the current dir if CdOnQuit on and save
@@ -749,8 +934,11 @@ func (m *model) quitSuperfile(cdOnQuit bool) {
 	if common.Config.Metadata && et != nil {
 		et.Close()
 	}
+	if err := m.watcher.Close(); err != nil {
+		slog.Error("Error while closing the directory watcher", "error", err)
+	}
 	// cd on quit
-	currentDir := m.fileModel.filePanels[m.filePanelFocusIndex].location
+	currentDir := archivefs.OuterDir(m.fileModel.filePanels[m.filePanelFocusIndex].location)
 	variable.SetLastDir(currentDir)
 
 	if cdOnQuit {
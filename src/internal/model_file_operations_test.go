@@ -14,7 +14,9 @@ import (
 
 	variable "github.com/yorukot/superfile/src/config"
 	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/journal"
 	"github.com/yorukot/superfile/src/internal/ui/notify"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
 	"github.com/yorukot/superfile/src/internal/utils"
 )
 
@@ -55,6 +57,7 @@ func TestCopy(t *testing.T) {
 		assert.Equal(t, file1, p.getModel().copyItems.items[0])
 
 		p.SendKey(common.Hotkeys.PasteItems[0])
+		choosePasteConflictPolicy(t, p, conflictKeepBoth, false)
 		assert.Eventually(t, func() bool {
 			_, err := os.Lstat(filepath.Join(dir2, "file1(1).txt"))
 			return err == nil
@@ -64,6 +67,53 @@ func TestCopy(t *testing.T) {
 	})
 }
 
+func TestCopyToNextPanel(t *testing.T) {
+	curTestDir := t.TempDir()
+	dir1 := filepath.Join(curTestDir, "dir1")
+	dir2 := filepath.Join(curTestDir, "dir2")
+	dir3 := filepath.Join(curTestDir, "dir3")
+	utils.SetupDirectories(t, dir1, dir2, dir3)
+	file1 := filepath.Join(dir1, "file1.txt")
+	file2 := filepath.Join(dir1, "file2.txt")
+	utils.SetupFiles(t, file1, file2)
+
+	t.Run("With two panels, the other one is used", func(t *testing.T) {
+		p := NewTestTeaProgWithEventLoop(t, defaultTestModel(dir1, dir2))
+		p.SendKey(common.Hotkeys.CopyToNextPanel[0])
+		assert.Eventually(t, func() bool {
+			_, err := os.Lstat(filepath.Join(dir2, "file1.txt"))
+			return err == nil
+		}, DefaultTestTimeout, DefaultTestTick)
+		assert.FileExists(t, file1)
+		assert.Empty(t, p.getModel().copyItems.items, "The clipboard is not used")
+	})
+
+	t.Run("With more panels, a picker chooses the target", func(t *testing.T) {
+		m := defaultTestModel(dir1, dir2, dir3)
+		TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.MoveToNextPanel[0]))
+		require.True(t, m.panelPickerModal.open)
+		assert.Equal(t, []int{1, 2}, m.panelPickerModal.panels, "The next panel is first")
+		assert.Contains(t, m.panelPickerRender(), dir3)
+
+		TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.ListDown[0]))
+		cmd := m.panelPickerKey(common.Hotkeys.Confirm[0])
+		assert.False(t, m.panelPickerModal.open)
+		require.NotNil(t, cmd)
+		ExecuteTeaCmdWithTimeout(cmd, DefaultTestTimeout)
+		assert.FileExists(t, filepath.Join(dir3, "file1.txt"))
+		assert.NoFileExists(t, file1)
+	})
+
+	t.Run("Invalid targets are refused", func(t *testing.T) {
+		p := NewTestTeaProgWithEventLoop(t, defaultTestModel(dir1, dir1))
+		p.SendKey(common.Hotkeys.MoveToNextPanel[0])
+		assert.Eventually(t, func() bool {
+			return p.getModel().notifyModel.IsOpen()
+		}, DefaultTestTimeout, DefaultTestTick)
+		assert.FileExists(t, file2)
+	})
+}
+
 func TestFileCreation(t *testing.T) {
 	// TODO Also add directory creation test to this
 	curTestDir := filepath.Join(testDir, "TestNaming")
@@ -266,3 +316,109 @@ func TestFileDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestUndoRedo(t *testing.T) {
+	curTestDir := t.TempDir()
+	file1 := filepath.Join(curTestDir, "file1.txt")
+	file1New := filepath.Join(curTestDir, "file1_new.txt")
+	utils.SetupFilesWithData(t, []byte("f1"), file1)
+
+	fileExists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+
+	m := defaultTestModel(curTestDir)
+	setFilePanelSelectedItemByLocation(t, m.getFocusedFilePanel(), file1)
+	p := NewTestTeaProgWithEventLoop(t, m)
+
+	p.SendKey(common.Hotkeys.FilePanelItemRename[0])
+	p.SendKey("_new")
+	p.Send(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Eventually(t, func() bool {
+		return fileExists(file1New)
+	}, DefaultTestTimeout, DefaultTestTick, "File never got renamed")
+
+	p.SendKey(common.Hotkeys.Undo[0])
+	assert.Eventually(t, func() bool {
+		return fileExists(file1) && !fileExists(file1New)
+	}, DefaultTestTimeout, DefaultTestTick, "Rename never got undone")
+
+	p.SendKey(common.Hotkeys.Redo[0])
+	assert.Eventually(t, func() bool {
+		return fileExists(file1New) && !fileExists(file1)
+	}, DefaultTestTimeout, DefaultTestTick, "Rename never got redone")
+
+	// Undo must be refused once the file is modified
+	require.NoError(t, os.WriteFile(file1New, []byte("modified"), 0644))
+	p.SendKey(common.Hotkeys.Undo[0])
+	assert.Eventually(t, func() bool {
+		open := false
+		p.ReadModel(func(m *model) {
+			open = m.notifyModel.IsOpen()
+		})
+		return open
+	}, DefaultTestTimeout, DefaultTestTick, "Notify modal never opened")
+	p.ReadModel(func(m *model) {
+		assert.Equal(t, common.UndoFailedTitle, m.notifyModel.GetTitle())
+	})
+	assert.FileExists(t, file1New)
+	assert.NoFileExists(t, file1)
+}
+
+func TestUndoMoveAndCreate(t *testing.T) {
+	curTestDir := t.TempDir()
+	srcDir := filepath.Join(curTestDir, "src")
+	dstDir := filepath.Join(curTestDir, "dst")
+	utils.SetupDirectories(t, srcDir, dstDir)
+	file1 := filepath.Join(srcDir, "file1.txt")
+	utils.SetupFiles(t, file1)
+
+	j := journal.New("")
+	processBar := processbar.New()
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+	executePasteOperation(&processBar, dstDir, []string{file1}, true, nil, "", j)
+	require.FileExists(t, filepath.Join(dstDir, "file1.txt"))
+
+	require.NoError(t, switchJournalEntry(j, &processBar, true))
+	assert.FileExists(t, file1)
+	assert.NoFileExists(t, filepath.Join(dstDir, "file1.txt"))
+
+	newDir := filepath.Join(curTestDir, "new")
+	require.NoError(t, os.Mkdir(newDir, 0755))
+	j.Record(journal.CreateOperation, []journal.Item{journal.NewItem("", newDir)}, nil)
+	require.NoError(t, switchJournalEntry(j, &processBar, true))
+	assert.NoDirExists(t, newDir)
+	require.NoError(t, switchJournalEntry(j, &processBar, false))
+	assert.DirExists(t, newDir)
+
+	// The move was discarded from redo history by the new create operation
+	require.NoError(t, switchJournalEntry(j, &processBar, true))
+	require.Error(t, switchJournalEntry(j, &processBar, true))
+}
+
+func TestRedoEncryptedCompress(t *testing.T) {
+	curTestDir := t.TempDir()
+	file1 := filepath.Join(curTestDir, "file1.txt")
+	utils.SetupFiles(t, file1)
+
+	m := defaultTestModel(curTestDir)
+	j := journal.New("")
+	m.journal = j
+	cmd := m.getCompressCmd([]string{file1}, curTestDir,
+		compressOptions{format: archiveZip, level: defaultCompressLevel, password: "hunter2"})
+	m.processBarModel.ListenForChannelUpdates()
+	t.Cleanup(m.processBarModel.SendStopListeningMsgBlocking)
+	msg, ok := ExecuteTeaCmdWithTimeout(cmd, DefaultTestTimeout).(CompressOperationMsg)
+	require.True(t, ok)
+	require.Equal(t, processbar.Successful, msg.state)
+	archive := filepath.Join(curTestDir, "file1.zip")
+	require.FileExists(t, archive)
+
+	require.NoError(t, switchJournalEntry(j, &m.processBarModel, true))
+	assert.NoFileExists(t, archive)
+	err := switchJournalEntry(j, &m.processBarModel, false)
+	require.ErrorContains(t, err, "encrypted")
+	assert.NoFileExists(t, archive, "The archive is not recreated without its password")
+}
@@ -2,9 +2,13 @@ package internal
 
 import (
 	"log/slog"
+	"os"
+	"slices"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/yorukot/superfile/src/internal/common"
+	contentsearchui "github.com/yorukot/superfile/src/internal/ui/contentsearch"
 	"github.com/yorukot/superfile/src/internal/ui/metadata"
 	"github.com/yorukot/superfile/src/internal/ui/notify"
 	"github.com/yorukot/superfile/src/internal/ui/processbar"
@@ -45,6 +49,27 @@ func (msg PasteOperationMsg) ApplyToModel(m *model) tea.Cmd {
 	return nil
 }
 
+type PasteConflictMsg struct {
+	BaseMessage
+
+	paste pendingPaste
+}
+
+func NewPasteConflictMsg(paste pendingPaste, reqID int) PasteConflictMsg {
+	return PasteConflictMsg{
+		paste: paste,
+		BaseMessage: BaseMessage{
+			reqID: reqID,
+		},
+	}
+}
+
+func (msg PasteConflictMsg) ApplyToModel(m *model) tea.Cmd {
+	m.pendingPaste = &msg.paste
+	m.notifyModel = m.pendingPaste.conflictModal()
+	return nil
+}
+
 type DeleteOperationMsg struct {
 	BaseMessage
 
@@ -66,6 +91,103 @@ func (msg DeleteOperationMsg) ApplyToModel(m *model) tea.Cmd {
 	return nil
 }
 
+type TrashOperationMsg struct {
+	BaseMessage
+
+	state processbar.ProcessState
+}
+
+func NewTrashOperationMsg(state processbar.ProcessState, reqID int) TrashOperationMsg {
+	return TrashOperationMsg{
+		state: state,
+		BaseMessage: BaseMessage{
+			reqID: reqID,
+		},
+	}
+}
+
+func (msg TrashOperationMsg) ApplyToModel(m *model) tea.Cmd {
+	// Show what is left in the trash
+	if m.trashModal.IsOpen() {
+		m.trashModal.Reload()
+	}
+	return nil
+}
+
+type ExifDatesMsg struct {
+	BaseMessage
+
+	dates map[string]exifDateResult
+}
+
+func NewExifDatesMsg(dates map[string]exifDateResult, reqID int) ExifDatesMsg {
+	return ExifDatesMsg{
+		dates: dates,
+		BaseMessage: BaseMessage{
+			reqID: reqID,
+		},
+	}
+}
+
+func (msg ExifDatesMsg) ApplyToModel(m *model) tea.Cmd {
+	m.patternRenameModal.setExifDates(msg.dates)
+	return nil
+}
+
+type RenameOperationMsg struct {
+	BaseMessage
+
+	state processbar.ProcessState
+}
+
+func NewRenameOperationMsg(state processbar.ProcessState, reqID int) RenameOperationMsg {
+	return RenameOperationMsg{
+		state: state,
+		BaseMessage: BaseMessage{
+			reqID: reqID,
+		},
+	}
+}
+
+func (msg RenameOperationMsg) ApplyToModel(_ *model) tea.Cmd {
+	return nil
+}
+
+type BulkRenameEditedMsg struct {
+	BaseMessage
+
+	items   []string
+	tmpPath string
+	err     error
+}
+
+func NewBulkRenameEditedMsg(items []string, tmpPath string, err error, reqID int) BulkRenameEditedMsg {
+	return BulkRenameEditedMsg{
+		items:   items,
+		tmpPath: tmpPath,
+		err:     err,
+		BaseMessage: BaseMessage{
+			reqID: reqID,
+		},
+	}
+}
+
+func (msg BulkRenameEditedMsg) ApplyToModel(m *model) tea.Cmd {
+	defer os.Remove(msg.tmpPath)
+	err := msg.err
+	var newNames []string
+	if err == nil {
+		newNames, err = readBulkRenameFile(msg.tmpPath)
+	}
+	if err != nil {
+		slog.Error("Could not read the edited names for bulk rename", "error", err)
+		m.notifyModel = notify.New(true, common.BulkRenameFailedTitle, err.Error(), notify.NoAction)
+		return nil
+	}
+	m.openRenamePlan(msg.items, newNames)
+	return nil
+}
+
 type ProcessBarUpdateMsg struct {
 	BaseMessage
 
@@ -99,6 +221,75 @@ func (msg CompressOperationMsg) ApplyToModel(_ *model) tea.Cmd {
 	return nil
 }
 
+type LinkOperationMsg struct {
+	BaseMessage
+
+	state processbar.ProcessState
+}
+
+func NewLinkOperationMsg(state processbar.ProcessState, reqID int) LinkOperationMsg {
+	return LinkOperationMsg{
+		state: state,
+		BaseMessage: BaseMessage{
+			reqID: reqID,
+		},
+	}
+}
+
+// The clipboard is kept as is, even if the items were cut, as they are still there
+func (msg LinkOperationMsg) ApplyToModel(_ *model) tea.Cmd {
+	return nil
+}
+
+// ArchivePasswordMsg is sent when an extraction needs a password, or another one
+type ArchivePasswordMsg struct {
+	BaseMessage
+
+	extract pendingExtract
+	err     error
+}
+
+func NewArchivePasswordMsg(extract pendingExtract, err error, reqID int) ArchivePasswordMsg {
+	return ArchivePasswordMsg{
+		extract: extract,
+		err:     err,
+		BaseMessage: BaseMessage{
+			reqID: reqID,
+		},
+	}
+}
+
+func (msg ArchivePasswordMsg) ApplyToModel(m *model) tea.Cmd {
+	m.openExtractPasswordModal(msg.extract, msg.err)
+	return nil
+}
+
+// PastePasswordMsg is sent when items copied out of an encrypted archive need its password,
+// or another one
+type PastePasswordMsg struct {
+	BaseMessage
+
+	paste   pendingPaste
+	archive string
+	err     error
+}
+
+func NewPastePasswordMsg(paste pendingPaste, archive string, err error, reqID int) PastePasswordMsg {
+	return PastePasswordMsg{
+		paste:   paste,
+		archive: archive,
+		err:     err,
+		BaseMessage: BaseMessage{
+			reqID: reqID,
+		},
+	}
+}
+
+func (msg PastePasswordMsg) ApplyToModel(m *model) tea.Cmd {
+	m.openPastePasswordModal(msg.paste, msg.archive, msg.err)
+	return nil
+}
+
 type ExtractOperationMsg struct {
 	BaseMessage
 
@@ -198,3 +389,83 @@ func (msg FilePreviewUpdateMsg) ApplyToModel(m *model) tea.Cmd {
 	m.fileModel.filePreview.SetContent(msg.content)
 	return nil
 }
+
+type DirectoryChangedMsg struct {
+	BaseMessage
+
+	dir string
+}
+
+func NewDirectoryChangedMsg(dir string, reqID int) DirectoryChangedMsg {
+	return DirectoryChangedMsg{
+		dir: dir,
+		BaseMessage: BaseMessage{
+			reqID: reqID,
+		},
+	}
+}
+
+// Only the panels showing the changed directory are read again
+func (msg DirectoryChangedMsg) ApplyToModel(m *model) tea.Cmd {
+	for i := range m.fileModel.filePanels {
+		if slices.Contains(m.fileModel.filePanels[i].shownDirs(), msg.dir) {
+			m.fileModel.filePanels[i].readElements(m.toggleDotFile)
+		}
+	}
+	if m.fileModel.parentColumn.location == msg.dir {
+		m.fileModel.parentColumn.readElements(m.toggleDotFile)
+	}
+	return m.getWatcherCmd()
+}
+
+type FlatListingMsg struct {
+	BaseMessage
+
+	batch flatBatch
+}
+
+func NewFlatListingMsg(batch flatBatch, reqID int) FlatListingMsg {
+	return FlatListingMsg{
+		batch: batch,
+		BaseMessage: BaseMessage{
+			reqID: reqID,
+		},
+	}
+}
+
+// The files are added to the listing, and shown by the panel as it is read again. The
+// batches of stopped listings are ignored
+func (msg FlatListingMsg) ApplyToModel(m *model) tea.Cmd {
+	listing := msg.batch.listing
+	if !listing.done {
+		listing.files = append(listing.files, msg.batch.files...)
+		listing.done = msg.batch.done
+		listing.truncated = msg.batch.truncated
+	}
+	return m.getFlatListingCmd()
+}
+
+type ContentSearchMsg struct {
+	BaseMessage
+
+	search *contentsearchui.Search
+	batch  contentsearchui.Batch
+}
+
+func NewContentSearchMsg(search *contentsearchui.Search, batch contentsearchui.Batch, reqID int) ContentSearchMsg {
+	return ContentSearchMsg{
+		search: search,
+		batch:  batch,
+		BaseMessage: BaseMessage{
+			reqID: reqID,
+		},
+	}
+}
+
+// The matches are shown in the modal, and the next batch is waited for until the search is done
+func (msg ContentSearchMsg) ApplyToModel(m *model) tea.Cmd {
+	if !m.contentSearchModal.AddBatch(msg.search, msg.batch) {
+		return nil
+	}
+	return m.getContentSearchBatchCmd(msg.search)
+}
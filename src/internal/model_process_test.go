@@ -1,6 +1,18 @@
 package internal
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
 
 func TestProcess(_ *testing.T) {
 	// TODO :
@@ -13,3 +25,220 @@ func TestProcess(_ *testing.T) {
 	// 3 - Process progress tracking
 
 }
+
+func TestCopyFileCancelAndPause(t *testing.T) {
+	curTestDir := t.TempDir()
+	src := filepath.Join(curTestDir, "src.txt")
+	utils.SetupFilesWithData(t, []byte("Some data to copy"), src)
+	srcInfo, err := os.Stat(src)
+	require.NoError(t, err)
+
+	t.Run("Cancelled copy removes destination", func(t *testing.T) {
+		dst := filepath.Join(curTestDir, "cancelled.txt")
+		p := processbar.NewProcess("1", "copy", 1)
+		p.Cancel()
+		err := copyFile(src, dst, srcInfo, &p, nil)
+		assert.Equal(t, processbar.Cancelled, getProcessStateFromError(err))
+		assert.NoFileExists(t, dst)
+	})
+
+	t.Run("Paused copy waits to be resumed", func(t *testing.T) {
+		dst := filepath.Join(curTestDir, "paused.txt")
+		p := processbar.NewProcess("2", "copy", 1)
+		p.SetPaused(true)
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- copyFile(src, dst, srcInfo, &p, nil)
+		}()
+
+		select {
+		case <-errChan:
+			require.Fail(t, "Copy should not finish while paused")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		p.SetPaused(false)
+		require.NoError(t, <-errChan)
+		data, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		assert.Equal(t, "Some data to copy", string(data))
+		assert.Equal(t, srcInfo.Size(), p.DoneBytes, "Copied bytes should be tracked")
+	})
+
+	t.Run("Cancel while paused", func(t *testing.T) {
+		dst := filepath.Join(curTestDir, "paused_cancelled.txt")
+		p := processbar.NewProcess("3", "copy", 1)
+		p.SetPaused(true)
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- copyFile(src, dst, srcInfo, &p, nil)
+		}()
+		p.Cancel()
+		assert.Equal(t, processbar.Cancelled, getProcessStateFromError(<-errChan))
+		assert.NoFileExists(t, dst)
+	})
+}
+
+func TestPasteCancelled(t *testing.T) {
+	processBar := processbar.New()
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+
+	curTestDir := t.TempDir()
+	srcDir := filepath.Join(curTestDir, "src")
+	dstDir := filepath.Join(curTestDir, "dst")
+	utils.SetupDirectories(t, srcDir, dstDir)
+	utils.SetupFiles(t, filepath.Join(srcDir, "file1.txt"), filepath.Join(srcDir, "file2.txt"))
+
+	p, err := processBar.SendAddProcessMsg("copy", 2, true)
+	require.NoError(t, err)
+	p.Cancel()
+
+	_, err = pasteDir(srcDir, filepath.Join(dstDir, "src"), conflictKeepBoth, newPasteContext(&p, &processBar, false))
+	require.Error(t, err)
+	assert.Equal(t, processbar.Cancelled, p.State)
+	assert.Equal(t, 0, p.Done)
+	assert.NoFileExists(t, filepath.Join(dstDir, "src", "file1.txt"))
+	assert.FileExists(t, filepath.Join(srcDir, "file1.txt"))
+}
+
+func TestProcessHistory(t *testing.T) {
+	processBar := processbar.New()
+	history := processbar.NewHistory("")
+	processBar.SetHistory(history)
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+
+	curTestDir := t.TempDir()
+	srcDir := filepath.Join(curTestDir, "src")
+	dstDir := filepath.Join(curTestDir, "dst")
+	utils.SetupDirectories(t, srcDir, dstDir)
+	file1 := filepath.Join(srcDir, "file1.txt")
+	utils.SetupFilesWithData(t, []byte("file1"), file1)
+
+	state := executePasteOperation(&processBar, dstDir, []string{file1}, true, nil, "", nil)
+	require.Equal(t, processbar.Successful, state)
+	require.Eventually(t, func() bool {
+		return len(history.Entries()) == 1
+	}, DefaultTestTimeout, DefaultTestTick)
+	entry := history.Entries()[0]
+	assert.Equal(t, []string{file1}, entry.Sources)
+	assert.Equal(t, dstDir, entry.Dest)
+	assert.Equal(t, processbar.Successful, entry.State)
+	assert.Equal(t, 1, entry.Files)
+	assert.Equal(t, int64(len("file1")), entry.Bytes)
+
+	t.Run("Failures are recorded with their error", func(t *testing.T) {
+		archive := filepath.Join(curTestDir, "missing", "dir", "archive.tar")
+		dstFile := filepath.Join(dstDir, "file1.txt")
+		require.Error(t, compressSources([]string{dstFile}, archive,
+			compressOptions{format: archiveTar, level: defaultCompressLevel}, &processBar))
+		require.Eventually(t, func() bool {
+			return len(history.Entries()) == 2
+		}, DefaultTestTimeout, DefaultTestTick)
+		entry := history.Entries()[0]
+		assert.Equal(t, processbar.Failed, entry.State)
+		assert.Equal(t, []string{dstFile}, entry.Sources)
+		require.NotEmpty(t, entry.Errors)
+	})
+
+	t.Run("The history view opens even with the footer hidden", func(t *testing.T) {
+		m := defaultTestModel(curTestDir)
+		m.processBarModel.SetHistory(history)
+		TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.OpenProcessHistory[0]))
+		require.True(t, m.processBarModel.IsProcessHistoryOpen())
+		assert.Contains(t, m.View(), "Process history")
+		TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.Quit[0]))
+		assert.False(t, m.processBarModel.IsProcessHistoryOpen())
+		assert.Equal(t, notQuitting, m.modelQuitState)
+	})
+}
+
+func TestRetryFailedProcess(t *testing.T) {
+	processBar := processbar.New()
+	history := processbar.NewHistory("")
+	processBar.SetHistory(history)
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+
+	curTestDir := t.TempDir()
+	srcDir := filepath.Join(curTestDir, "src")
+	dstDir := filepath.Join(curTestDir, "dst")
+	utils.SetupDirectories(t, srcDir, dstDir)
+	file1 := filepath.Join(srcDir, "file1.txt")
+	file2 := filepath.Join(srcDir, "file2.txt")
+	file3 := filepath.Join(srcDir, "file3.txt")
+	utils.SetupFiles(t, file1, file3)
+
+	m := defaultTestModel(dstDir)
+	m.processBarModel = processBar
+	TeaUpdate(m, nil)
+
+	// file2 is missing, so the paste fails on it
+	state := executePasteOperation(&m.processBarModel, dstDir, []string{file1, file2, file3}, false, nil, "", nil)
+	require.Equal(t, processbar.Failed, state)
+	require.Eventually(t, func() bool {
+		return len(history.Entries()) == 1
+	}, DefaultTestTimeout, DefaultTestTick)
+
+	m.processBarModel.OpenSelectedProcessDetails()
+	p, ok := m.processBarModel.GetProcessInDetails()
+	require.True(t, ok)
+	assert.Equal(t, operationCopy, p.Operation)
+	assert.Equal(t, []string{file1}, p.Completed)
+	assert.Equal(t, []string{file2, file3}, p.PendingSources())
+	require.Len(t, p.Errors, 1)
+	assert.Contains(t, p.Errors[0], file2)
+	assert.True(t, p.CanRetry())
+	assert.Contains(t, m.processBarModel.RenderProcessDetails(), "retry failed items")
+
+	utils.SetupFiles(t, file2)
+	cmd := m.processDetailsKey(common.Hotkeys.RetryProcess[0])
+	assert.False(t, m.processBarModel.IsProcessDetailsOpen())
+	require.NotNil(t, cmd)
+	ExecuteTeaCmdWithTimeout(cmd, DefaultTestTimeout)
+	require.Eventually(t, func() bool {
+		return len(history.Entries()) == 2
+	}, DefaultTestTimeout, DefaultTestTick)
+	entry := history.Entries()[0]
+	assert.Equal(t, processbar.Successful, entry.State)
+	assert.Equal(t, []string{file2, file3}, entry.Sources, "Only the pending items are pasted again")
+	verifyDestinationFiles(t, dstDir, []string{"file1.txt", "file2.txt", "file3.txt"})
+	entries, err := os.ReadDir(dstDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3, "The completed item is not pasted again")
+}
+
+func TestQueuedPaste(t *testing.T) {
+	processBar := processbar.New()
+	processBar.SetQueue(processbar.NewQueue(1))
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+
+	curTestDir := t.TempDir()
+	srcDir := filepath.Join(curTestDir, "src")
+	dstDir := filepath.Join(curTestDir, "dst")
+	utils.SetupDirectories(t, srcDir, dstDir)
+	file1 := filepath.Join(srcDir, "file1.txt")
+	utils.SetupFiles(t, file1)
+
+	// Another process is running on the destination device
+	running, err := processBar.SendAddProcessMsg("running", 1, true)
+	require.NoError(t, err)
+	release := processBar.WaitForTurn(&running, getProcessDevice(dstDir))
+
+	result := make(chan processbar.ProcessState, 1)
+	go func() {
+		result <- executePasteOperation(&processBar, dstDir, []string{file1}, false, nil, "", nil)
+	}()
+	select {
+	case <-result:
+		require.Fail(t, "Paste should wait for the running process")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.NoFileExists(t, filepath.Join(dstDir, "file1.txt"))
+
+	release()
+	assert.Equal(t, processbar.Successful, <-result)
+	assert.FileExists(t, filepath.Join(dstDir, "file1.txt"))
+}
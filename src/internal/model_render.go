@@ -6,7 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
+	"github.com/yorukot/superfile/src/internal/archivefs"
 	"github.com/yorukot/superfile/src/internal/ui"
 	"github.com/yorukot/superfile/src/internal/ui/rendering"
 	filepreview "github.com/yorukot/superfile/src/pkg/file_preview"
@@ -28,6 +30,9 @@ func (m *model) sidebarRender() string {
 // what modifications we do on this model object are of no consequence.
 // Since bubblea passed this 'model' by value in View() function.
 func (m *model) filePanelRender() string {
+	if common.Config.FilePanelLayout == common.FilePanelLayoutMiller {
+		return m.millerColumnsRender()
+	}
 	f := make([]string, len(m.fileModel.filePanels))
 	for i, filePanel := range m.fileModel.filePanels {
 		// check if cursor or render out of range
@@ -39,28 +44,20 @@ func (m *model) filePanelRender() string {
 		}
 		m.fileModel.filePanels[i] = filePanel
 
-		// TODO : Move this to a utility function and clarify the calculation via comments
-		// Maybe even write unit tests
-		var filePanelWidth int
-		if (m.fullWidth-common.Config.SidebarWidth-(4+(len(m.fileModel.filePanels)-1)*2))%len(
-			m.fileModel.filePanels,
-		) != 0 &&
-			i == len(m.fileModel.filePanels)-1 {
-			if m.fileModel.filePreview.IsOpen() {
-				filePanelWidth = m.fileModel.width
-			} else {
-				filePanelWidth = (m.fileModel.width + (m.fullWidth-common.Config.SidebarWidth-
-					(4+(len(m.fileModel.filePanels)-1)*2))%len(m.fileModel.filePanels))
-			}
-		} else {
-			filePanelWidth = m.fileModel.width
-		}
-
-		f[i] = filePanel.Render(m.mainPanelHeight, filePanelWidth, filePanel.isFocused)
+		f[i] = filePanel.Render(m.mainPanelHeight, m.filePanelColumnWidth(i), filePanel.isFocused)
 	}
 	return lipgloss.JoinHorizontal(lipgloss.Top, f...)
 }
 
+// Only the focused panel is shown, after the column of its parent directory. The file preview
+// follows it, as the column of the item under the cursor
+func (m *model) millerColumnsRender() string {
+	focusedPanel := m.getFocusedFilePanel()
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		m.fileModel.parentColumn.Render(m.mainPanelHeight, m.filePanelColumnWidth(0), false),
+		focusedPanel.Render(m.mainPanelHeight, m.filePanelColumnWidth(1), focusedPanel.isFocused))
+}
+
 func (panel *filePanel) Render(mainPanelHeight int, filePanelWidth int, focussed bool) string {
 	r := ui.FilePanelRenderer(mainPanelHeight+2, filePanelWidth+2, focussed)
 
@@ -137,19 +134,33 @@ func (panel *filePanel) renderFileEntries(r *rendering.Renderer, mainPanelHeight
 		dirExists := err == nil || panel.element[i].directory
 
 		selectBox := panel.renderSelectBox(isSelected)
+		treeGuide := panel.element[i].treeGuide
 
 		// Calculate the actual prefix width for proper alignment
-		prefixWidth := lipgloss.Width(cursor+" ") + lipgloss.Width(selectBox)
-
-		renderedName := common.PrettierName(
-			panel.element[i].name,
-			filePanelWidth-prefixWidth,
-			dirExists,
-			isSelected,
-			common.FilePanelBGColor,
-		)
+		prefixWidth := lipgloss.Width(cursor+" ") + lipgloss.Width(selectBox) + lipgloss.Width(treeGuide)
+
+		var renderedName string
+		if panel.element[i].symlinkTarget != "" {
+			renderedName = common.PrettierSymlinkName(
+				panel.element[i].name,
+				panel.element[i].symlinkTarget,
+				filePanelWidth-prefixWidth,
+				dirExists,
+				isSelected,
+				common.FilePanelBGColor,
+			)
+		} else {
+			renderedName = common.PrettierName(
+				panel.element[i].name,
+				filePanelWidth-prefixWidth,
+				dirExists,
+				isSelected,
+				common.FilePanelBGColor,
+			)
+		}
 
-		r.AddLines(common.FilePanelCursorStyle.Render(cursor+" ") + selectBox + renderedName)
+		r.AddLines(common.FilePanelCursorStyle.Render(cursor+" ") + selectBox +
+			common.FilePanelTreeGuideStyle.Render(treeGuide) + renderedName)
 	}
 }
 
@@ -175,6 +186,13 @@ func (panel *filePanel) getPanelModeInfo() (string, string) {
 		return "Browser", icon.Browser
 	case selectMode:
 		return "Select", icon.Select
+	case treeMode:
+		return "Tree", icon.Tree
+	case flatMode:
+		if panel.flatListing != nil && panel.flatListing.truncated {
+			return "Flat (truncated)", icon.Flat
+		}
+		return "Flat", icon.Flat
 	default:
 		return "", ""
 	}
@@ -227,11 +245,11 @@ func (m *model) clipboardRender() string {
 				// Last Entry we can render, but there are more that one left
 				r.AddLines(strconv.Itoa(len(m.copyItems.items)-i) + " item left....")
 			} else {
-				fileInfo, err := os.Stat(m.copyItems.items[i])
+				fileInfo, err := archivefs.Stat(m.copyItems.items[i])
 				if err != nil {
 					slog.Error("Clipboard render function get item state ", "error", err)
 				}
-				if !os.IsNotExist(err) {
+				if err == nil {
 					// TODO : There is an inconsistency in parameter that is being passed,
 					// and its name in ClipboardPrettierName function
 					r.AddLines(common.ClipboardPrettierName(m.copyItems.items[i],
@@ -315,6 +333,24 @@ func (m *model) typineModalRender() string {
 		Render(fileLocation + "\n" + m.typingModal.textInput.View() + "\n\n" + tip + err)
 }
 
+func (m *model) passwordModalRender() string {
+	title := common.ModalTitleStyle.Render(" " + common.TruncateText(m.passwordModal.title, common.ModalWidth-4, "..."))
+
+	confirm := common.ModalConfirm.Render(" (" + common.Hotkeys.ConfirmTyping[0] + ") Confirm ")
+	cancel := common.ModalCancel.Render(" (" + common.Hotkeys.CancelTyping[0] + ") Cancel ")
+
+	tip := confirm +
+		lipgloss.NewStyle().Background(common.ModalBGColor).Render("           ") +
+		cancel
+
+	var err string
+	if m.passwordModal.errorMessage != "" {
+		err = "\n\n" + common.ModalErrorStyle.Render(m.passwordModal.errorMessage)
+	}
+	return common.ModalBorderStyle(common.ModalHeight, common.ModalWidth).
+		Render(title + "\n" + m.passwordModal.textInput.View() + "\n\n" + tip + err)
+}
+
 func (m *model) introduceModalRender() string {
 	title := common.SidebarTitleStyle.Render(" Thanks for using superfile!!") +
 		common.ModalStyle.Render("\n You can read the following information before starting to use it!")
@@ -445,6 +481,142 @@ func (m *model) getHelpMenuContent(r *rendering.Renderer, renderHotkeyLength int
 	}
 }
 
+func (m *model) renamePlanRender() string {
+	r := ui.RenamePlanRenderer(m.renamePlanModal.height, m.renamePlanModal.width)
+	r.SetBorderTitle("Bulk rename")
+	ops := m.renamePlanModal.ops
+	r.AddLines(fmt.Sprintf(" %d item(s) will be renamed", len(ops)))
+	r.AddSection()
+	end := min(m.renamePlanModal.renderIndex+m.renamePlanModal.visibleOpCnt(), len(ops))
+	for _, op := range ops[m.renamePlanModal.renderIndex:end] {
+		r.AddLines(" " + filepath.Base(op.src) + " -> " + filepath.Base(op.dst))
+	}
+	r.AddSection()
+	r.AddLines(" " + common.Hotkeys.Confirm[0] + ": apply  " + common.Hotkeys.CancelTyping[0] + ": cancel")
+	return r.Render()
+}
+
+func (m *model) patternRenameRender() string {
+	r := m.patternRenameModal
+	renderer := ui.PatternRenameRenderer(r.height, r.width)
+	renderer.SetBorderTitle("Pattern rename")
+	renderer.AddLines(" Find:    "+r.find.View(), " Replace: "+r.replace.View())
+	renderer.AddSection()
+
+	status := fmt.Sprintf(" Case: %s", r.nameCase)
+	switch conflictCnt := r.conflictCnt(); {
+	case r.patternErr != "":
+		status += "  " + common.ModalErrorStyle.Render(r.patternErr)
+	case conflictCnt > 0:
+		status += "  " + common.ModalErrorStyle.Render(fmt.Sprintf("%d conflict(s)", conflictCnt))
+	case r.pendingCnt() > 0:
+		status += fmt.Sprintf("  Reading %d exif date(s)...", r.pendingCnt())
+	}
+	renderer.AddLines(status)
+	renderer.AddSection()
+
+	// Old names on the left, new names on the right
+	nameWidth := max((r.width-8)/2, 1)
+	end := min(r.renderIndex+r.visibleRowCnt(), len(r.rows))
+	for _, row := range r.rows[r.renderIndex:end] {
+		oldName := common.TruncateText(filepath.Base(row.src), nameWidth, "...")
+		line := " " + oldName + strings.Repeat(" ", max(nameWidth-lipgloss.Width(oldName), 0)) + " -> "
+		switch {
+		case row.conflict != "":
+			line += common.ModalErrorStyle.Render(common.TruncateText(row.conflict, nameWidth, "..."))
+		case row.pending:
+			line += "..."
+		default:
+			line += common.TruncateText(row.newName, nameWidth, "...")
+		}
+		renderer.AddLines(line)
+	}
+	renderer.AddSection()
+	renderer.AddLines(" " + common.Hotkeys.ConfirmTyping[0] + ": apply  " + common.Hotkeys.NextInput[0] +
+		": switch input  " + common.Hotkeys.ToggleCase[0] + ": case  " + common.Hotkeys.CancelTyping[0] + ": cancel")
+	return renderer.Render()
+}
+
+func (m *model) compressModalRender() string {
+	c := m.compressModal
+	r := ui.CompressRenderer(compressModalHeight, compressModalWidth)
+	r.SetBorderTitle("Compress")
+	r.AddLines(fmt.Sprintf(" %d item(s) to compress", len(c.items)))
+	r.AddSection()
+	for i, format := range archiveFormats {
+		cursor := " "
+		if i == c.cursor {
+			cursor = common.FilePanelCursorStyle.Render(icon.Cursor)
+		}
+		r.AddLines(cursor + common.ModalStyle.Render(" "+string(format)))
+	}
+	r.AddSection()
+	level := "none"
+	if archiveFormats[c.cursor].hasLevel() {
+		level = fmt.Sprintf("%d (%d-%d)", c.level, minCompressLevel, maxCompressLevel)
+	}
+	encrypt := "no"
+	switch {
+	case !c.canEncrypt():
+		encrypt = "zip only"
+	case c.encrypt:
+		encrypt = "yes, with AES-256"
+	}
+	r.AddLines(" Compression level: "+level, " Encrypt with a password: "+encrypt)
+	r.AddSection()
+	r.AddLines(" " + common.Hotkeys.Confirm[0] + ": compress  " + common.Hotkeys.NextInput[0] + ": encrypt  " +
+		common.Hotkeys.CancelTyping[0] + ": cancel")
+	return r.Render()
+}
+
+func (m *model) extractModalRender() string {
+	e := m.extractModal
+	r := ui.ExtractRenderer(extractModalHeight, extractModalWidth)
+	r.SetBorderTitle("Extract")
+	name := common.TruncateText(filepath.Base(e.archive), extractModalWidth-20, "...")
+	if len(e.members) == 0 {
+		r.AddLines(" Extract all of " + name)
+	} else {
+		r.AddLines(fmt.Sprintf(" Extract %d item(s) from %s", len(e.members), name))
+	}
+	r.AddSection()
+	label := " Destination:"
+	if e.destErr != "" {
+		label += " " + common.ModalErrorStyle.Render(e.destErr)
+	}
+	r.AddLines(label, " "+e.dest.View())
+	r.AddSection()
+	r.AddLines(" Existing files: " + e.policy.String())
+	r.AddSection()
+	r.AddLines(" " + common.Hotkeys.ConfirmTyping[0] + ": extract  " + common.Hotkeys.NextInput[0] +
+		": existing files  " + common.Hotkeys.CancelTyping[0] + ": cancel")
+	return r.Render()
+}
+
+func (m *model) panelPickerRender() string {
+	p := m.panelPickerModal
+	r := ui.PanelPickerRenderer(p.height(), panelPickerWidth)
+	action := "Copy"
+	if p.cut {
+		action = "Move"
+	}
+	r.SetBorderTitle(action + " to panel")
+	r.AddLines(fmt.Sprintf(" %s %d item(s) to:", action, len(p.items)))
+	r.AddSection()
+	for i, panelIndex := range p.panels {
+		cursor := " "
+		if i == p.cursor {
+			cursor = common.FilePanelCursorStyle.Render(icon.Cursor)
+		}
+		location := common.TruncateTextBeginning(m.fileModel.filePanels[panelIndex].location, panelPickerWidth-8, "...")
+		r.AddLines(cursor + common.ModalStyle.Render(fmt.Sprintf(" %d. %s", panelIndex+1, location)))
+	}
+	r.AddSection()
+	r.AddLines(" " + common.Hotkeys.Confirm[0] + ": " + strings.ToLower(action) + "  " +
+		common.Hotkeys.CancelTyping[0] + ": cancel")
+	return r.Render()
+}
+
 func (m *model) sortOptionsRender() string {
 	panel := m.fileModel.filePanels[m.filePanelFocusIndex]
 	sortOptionsContent := common.ModalTitleStyle.Render(" Sort Options") + "\n\n"
@@ -245,3 +245,40 @@ func TestChooserFile(t *testing.T) {
 		})
 	}
 }
+
+func TestMillerColumns(t *testing.T) {
+	curTestDir := filepath.Join(testDir, "TestMillerColumns")
+	dir1 := filepath.Join(curTestDir, "dir1")
+	dir2 := filepath.Join(curTestDir, "dir2")
+	subDir := filepath.Join(dir2, "subdir")
+	utils.SetupDirectories(t, curTestDir, dir1, dir2, subDir)
+	utils.SetupFiles(t, filepath.Join(subDir, "file1.txt"))
+	t.Cleanup(func() {
+		os.RemoveAll(curTestDir)
+	})
+
+	orig := common.Config.FilePanelLayout
+	common.Config.FilePanelLayout = common.FilePanelLayoutMiller
+	t.Cleanup(func() { common.Config.FilePanelLayout = orig })
+
+	m := defaultTestModel(dir2, dir1)
+	TeaUpdate(m, nil)
+	column := &m.fileModel.parentColumn
+	assert.Equal(t, 2, m.filePanelColumnCnt())
+	assert.Equal(t, curTestDir, column.location)
+	assert.Equal(t, dir2, column.getSelectedItem().location)
+	assert.Contains(t, m.filePanelRender(), "dir1")
+
+	// The parent column follows the focused panel
+	TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.Confirm[0]))
+	require.Equal(t, subDir, m.getFocusedFilePanel().location)
+	TeaUpdate(m, nil)
+	assert.Equal(t, dir2, column.location)
+	assert.Equal(t, subDir, column.getSelectedItem().location)
+
+	TeaUpdate(m, utils.TeaRuneKeyMsg(common.Hotkeys.NextFilePanel[0]))
+	TeaUpdate(m, nil)
+	assert.Equal(t, dir1, m.getFocusedFilePanel().location)
+	assert.Equal(t, curTestDir, column.location)
+	assert.Equal(t, dir1, column.getSelectedItem().location)
+}
@@ -0,0 +1,204 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Case conversion applied to the new names of a pattern rename
+type renameCase int
+
+const (
+	renameCaseKeep renameCase = iota
+	renameCaseLower
+	renameCaseUpper
+	renameCaseTitle
+	renameCaseCnt
+)
+
+func (c renameCase) String() string {
+	switch c {
+	case renameCaseLower:
+		return "lower"
+	case renameCaseUpper:
+		return "UPPER"
+	case renameCaseTitle:
+		return "Title"
+	default:
+		return "unchanged"
+	}
+}
+
+func (c renameCase) next() renameCase {
+	return (c + 1) % renameCaseCnt
+}
+
+// Tokens of the replacement that are expanded for every item, like {n:03} or {mtime:%Y%m%d}.
+// Anything else in braces is kept as is, so ${1} still refers to a capture group
+var renameTokenRegex = regexp.MustCompile(`\{(n|name|ext|mtime|exif)(?::([^{}]*))?\}`) //nolint: gochecknoglobals // This is effectively const.
+
+const defaultRenameDateFormat = "%Y-%m-%d"
+
+// Returns the exif date of an item. Fetching it runs exiftool, so the caller caches it
+type exifDateFunc func(path string) (time.Time, error)
+
+// errExifDatePending is returned for the exif dates that are still being fetched
+var errExifDatePending = errors.New("reading the exif date")
+
+// renamePattern turns the names of the items of a bulk rename into new names
+type renamePattern struct {
+	// nil when find is empty, in which case the replacement is the whole new name
+	find     *regexp.Regexp
+	replace  string
+	nameCase renameCase
+}
+
+func newRenamePattern(find string, replace string, nameCase renameCase) (renamePattern, error) {
+	r := renamePattern{replace: replace, nameCase: nameCase}
+	if find != "" {
+		re, err := regexp.Compile(find)
+		if err != nil {
+			return r, fmt.Errorf("invalid regex: %w", err)
+		}
+		r.find = re
+	}
+	return r, nil
+}
+
+// apply returns the new name of item, the idx-th of the items being renamed
+func (r renamePattern) apply(item string, idx int, exifDate exifDateFunc) (string, error) {
+	newName := filepath.Base(item)
+	if r.find != nil || r.replace != "" {
+		replace, err := r.expandTokens(item, idx, exifDate)
+		if err != nil {
+			return "", err
+		}
+		if r.find == nil {
+			newName = replace
+		} else {
+			newName = r.find.ReplaceAllString(newName, replace)
+		}
+	}
+	return convertNameCase(newName, r.nameCase), nil
+}
+
+func (r renamePattern) expandTokens(item string, idx int, exifDate exifDateFunc) (string, error) {
+	var err error
+	res := renameTokenRegex.ReplaceAllStringFunc(r.replace, func(token string) string {
+		match := renameTokenRegex.FindStringSubmatch(token)
+		value, tokenErr := expandRenameToken(match[1], match[2], item, idx, exifDate)
+		if tokenErr != nil {
+			err = errors.Join(err, tokenErr)
+			return ""
+		}
+		// Values are literal, and must not be taken as capture groups
+		if r.find != nil {
+			value = strings.ReplaceAll(value, "$", "$$")
+		}
+		return value
+	})
+	return res, err
+}
+
+func expandRenameToken(token string, arg string, item string, idx int, exifDate exifDateFunc) (string, error) {
+	name := filepath.Base(item)
+	switch token {
+	case "n":
+		if arg == "" {
+			return strconv.Itoa(idx + 1), nil
+		}
+		width, err := strconv.Atoi(arg)
+		if err != nil || width < 0 {
+			return "", fmt.Errorf("invalid counter padding %q", arg)
+		}
+		return fmt.Sprintf("%0*d", width, idx+1), nil
+	case "name":
+		return strings.TrimSuffix(name, filepath.Ext(name)), nil
+	case "ext":
+		return filepath.Ext(name), nil
+	case "mtime":
+		info, err := os.Stat(item)
+		if err != nil {
+			return "", err
+		}
+		return formatRenameDate(info.ModTime(), arg), nil
+	case "exif":
+		t, err := exifDate(item)
+		if errors.Is(err, errExifDatePending) {
+			return "", err
+		}
+		if err != nil {
+			return "", fmt.Errorf("no exif date: %w", err)
+		}
+		return formatRenameDate(t, arg), nil
+	}
+	return "", fmt.Errorf("unknown token %q", token)
+}
+
+// formatRenameDate formats t with the strftime like format of a date token
+func formatRenameDate(t time.Time, format string) string {
+	if format == "" {
+		format = defaultRenameDateFormat
+	}
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch format[i] {
+		case 'Y':
+			b.WriteString(t.Format("2006"))
+		case 'y':
+			b.WriteString(t.Format("06"))
+		case 'm':
+			b.WriteString(t.Format("01"))
+		case 'd':
+			b.WriteString(t.Format("02"))
+		case 'j':
+			b.WriteString(t.Format("002"))
+		case 'H':
+			b.WriteString(t.Format("15"))
+		case 'M':
+			b.WriteString(t.Format("04"))
+		case 'S':
+			b.WriteString(t.Format("05"))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(format[i])
+		}
+	}
+	return b.String()
+}
+
+func convertNameCase(name string, nameCase renameCase) string {
+	switch nameCase {
+	case renameCaseLower:
+		return strings.ToLower(name)
+	case renameCaseUpper:
+		return strings.ToUpper(name)
+	case renameCaseTitle:
+		// Every word starts with an upper case letter, and the rest is in lower case
+		res := []rune(strings.ToLower(name))
+		wordStart := true
+		for i, r := range res {
+			if wordStart {
+				res[i] = unicode.ToUpper(r)
+			}
+			wordStart = !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}
+		return string(res)
+	default:
+		return name
+	}
+}
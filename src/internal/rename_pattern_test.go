@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func TestRenamePattern(t *testing.T) {
+	curTestDir := t.TempDir()
+	item := filepath.Join(curTestDir, "IMG_0042 holiday.JPG")
+	utils.SetupFiles(t, item)
+	mtime := time.Date(2024, 2, 3, 4, 5, 6, 0, time.Local)
+	require.NoError(t, os.Chtimes(item, mtime, mtime))
+	exifDate := func(string) (time.Time, error) {
+		return time.Date(2021, 12, 31, 23, 59, 0, 0, time.Local), nil
+	}
+	noExifDate := func(string) (time.Time, error) {
+		return time.Time{}, errors.New("exiftool is not available")
+	}
+
+	testdata := []struct {
+		name        string
+		find        string
+		replace     string
+		nameCase    renameCase
+		idx         int
+		exifDate    exifDateFunc
+		expected    string
+		expectedErr bool
+	}{
+		{name: "Nothing to do", expected: "IMG_0042 holiday.JPG"},
+		{name: "Find and replace", find: " ", replace: "_", expected: "IMG_0042_holiday.JPG"},
+		{name: "Capture groups", find: `^IMG_(\d+) (\w+)`, replace: "${2}_$1", expected: "holiday_0042.JPG"},
+		{name: "Invalid regex", find: "(", expectedErr: true},
+		{name: "Counter", replace: "photo_{n}{ext}", idx: 6, expected: "photo_7.JPG"},
+		{name: "Padded counter", replace: "photo_{n:03}{ext}", idx: 6, expected: "photo_007.JPG"},
+		{name: "Invalid counter", replace: "photo_{n:x}{ext}", expectedErr: true},
+		{name: "Name and extension", find: ".*", replace: "{ext}{name}", expected: ".JPGIMG_0042 holiday"},
+		{name: "Modification date", replace: "{mtime}_{name}{ext}", expected: "2024-02-03_IMG_0042 holiday.JPG"},
+		{name: "Date format", find: `^IMG_\d+`, replace: "{mtime:%Y%m%d_%H%M%S}",
+			expected: "20240203_040506 holiday.JPG"},
+		{name: "Exif date", find: `^IMG_\d+`, replace: "{exif:%y.%j}", exifDate: exifDate,
+			expected: "21.365 holiday.JPG"},
+		{name: "No exif date", replace: "{exif}", exifDate: noExifDate, expectedErr: true},
+		{name: "Unknown tokens are kept", replace: "{foo}{ext}", expected: "{foo}.JPG"},
+		{name: "Lower case", nameCase: renameCaseLower, expected: "img_0042 holiday.jpg"},
+		{name: "Upper case", find: "holiday", replace: "trip", nameCase: renameCaseUpper,
+			expected: "IMG_0042 TRIP.JPG"},
+		{name: "Title case", nameCase: renameCaseTitle, expected: "Img_0042 Holiday.Jpg"},
+	}
+	for _, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, err := newRenamePattern(tt.find, tt.replace, tt.nameCase)
+			if err == nil {
+				_, err = pattern.apply(item, tt.idx, tt.exifDate)
+			}
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			res, err := pattern.apply(item, tt.idx, tt.exifDate)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestPatternRename(t *testing.T) {
+	processBar := processbar.New()
+	processBar.ListenForChannelUpdates()
+	t.Cleanup(processBar.SendStopListeningMsgBlocking)
+
+	curTestDir := t.TempDir()
+	a := filepath.Join(curTestDir, "a.txt")
+	b := filepath.Join(curTestDir, "b.txt")
+	other := filepath.Join(curTestDir, "other.txt")
+	utils.SetupFilesWithData(t, []byte("a"), a)
+	utils.SetupFilesWithData(t, []byte("b"), b)
+	utils.SetupFiles(t, other)
+
+	m := defaultTestModel(curTestDir)
+	m.processBarModel = processBar
+	typeText := func(text string) {
+		for _, r := range text {
+			TeaUpdate(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+	setupPanelModeAndSelection(t, m, true, "", []string{a, b})
+	m.openPatternRename()
+	require.True(t, m.patternRenameModal.open)
+	// The key that opened the modal is not typed
+	TeaUpdate(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(common.Hotkeys.PatternRename[0])})
+	assert.Empty(t, m.patternRenameModal.find.Value())
+
+	t.Run("Conflicts are shown", func(t *testing.T) {
+		typeText(`^\w+`)
+		m.patternRenameKey(tea.KeyMsg{Type: tea.KeyTab})
+		typeText("other")
+		require.Len(t, m.patternRenameModal.rows, 2)
+		assert.Equal(t, "other.txt", m.patternRenameModal.rows[0].newName)
+		assert.NotEmpty(t, m.patternRenameModal.rows[0].conflict)
+		assert.NotEmpty(t, m.patternRenameModal.rows[1].conflict)
+		assert.Nil(t, m.confirmPatternRename())
+		assert.True(t, m.patternRenameModal.open)
+	})
+
+	t.Run("Rename with a counter", func(t *testing.T) {
+		typeText("_{n:02}")
+		m.patternRenameKey(tea.KeyMsg{Type: tea.KeyCtrlT})
+		assert.Equal(t, renameCaseLower, m.patternRenameModal.nameCase)
+		assert.Equal(t, "other_02.txt", m.patternRenameModal.rows[1].newName)
+		assert.Zero(t, m.patternRenameModal.conflictCnt())
+
+		cmd := m.patternRenameKey(tea.KeyMsg{Type: tea.KeyEnter})
+		require.NotNil(t, cmd)
+		assert.False(t, m.patternRenameModal.open)
+		msg, ok := cmd().(RenameOperationMsg)
+		require.True(t, ok)
+		assert.Equal(t, processbar.Successful, msg.state)
+		assert.NoFileExists(t, a)
+		data, err := os.ReadFile(filepath.Join(curTestDir, "other_02.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "b", string(data))
+		assert.FileExists(t, other)
+
+		require.NoError(t, switchJournalEntry(m.journal, &processBar, true))
+		assert.FileExists(t, a)
+		assert.FileExists(t, b)
+	})
+}
+
+func TestPatternRenameExifDates(t *testing.T) {
+	curTestDir := t.TempDir()
+	a := filepath.Join(curTestDir, "a.jpg")
+	b := filepath.Join(curTestDir, "b.jpg")
+	utils.SetupFiles(t, a, b)
+
+	m := defaultTestModel(curTestDir)
+	setupPanelModeAndSelection(t, m, true, "", []string{a, b})
+	require.Nil(t, m.openPatternRename(), "No exif date is needed yet")
+	r := &m.patternRenameModal
+	r.replaceFocused = true
+	r.find.Blur()
+	r.replace.Focus()
+	r.replace.SetValue("{exif:%Y}")
+	r.updatePreview()
+
+	// The dates are fetched once, outside of the update
+	cmd := m.getExifDatesCmd()
+	require.NotNil(t, cmd)
+	assert.Nil(t, m.getExifDatesCmd())
+	require.Len(t, r.rows, 2)
+	assert.True(t, r.rows[0].pending)
+	assert.Equal(t, 2, r.pendingCnt())
+	assert.Contains(t, m.patternRenameRender(), "Reading 2 exif date(s)")
+	assert.Nil(t, m.confirmPatternRename())
+
+	msg, ok := ExecuteTeaCmdWithTimeout(cmd, DefaultTestTimeout).(ExifDatesMsg)
+	require.True(t, ok)
+	r.setExifDates(map[string]exifDateResult{
+		a: {date: time.Date(2020, 1, 2, 0, 0, 0, 0, time.Local)},
+		b: msg.dates[b],
+	})
+	assert.Zero(t, r.pendingCnt())
+	assert.Equal(t, "2020", r.rows[0].newName)
+	assert.NotEmpty(t, r.rows[1].conflict, "Plain files have no exif date")
+
+	r.close()
+	r.setExifDates(msg.dates)
+	assert.Empty(t, r.rows, "Dates arriving after the modal is closed are ignored")
+}
@@ -0,0 +1,177 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yorukot/superfile/src/config/icon"
+	"github.com/yorukot/superfile/src/internal/journal"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+)
+
+// A single rename of a bulk rename. Both are full paths
+type renameOp struct {
+	src string
+	dst string
+}
+
+// buildRenamePlan pairs each of the items with its new name, and checks that the renames
+// can be done. Items that keep their name are left out. Swaps and cycles among the items
+// are allowed, as executeRenamePlan goes through temporary names
+func buildRenamePlan(items []string, newNames []string) ([]renameOp, error) {
+	if len(items) != len(newNames) {
+		return nil, fmt.Errorf("expected %d names, got %d. Lines cannot be added or removed",
+			len(items), len(newNames))
+	}
+	srcs := make(map[string]bool, len(items))
+	for _, item := range items {
+		srcs[item] = true
+	}
+
+	var ops []renameOp
+	dsts := make(map[string]string, len(items))
+	for i, item := range items {
+		dst, err := getRenameTarget(item, newNames[i], srcs)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := dsts[dst]; ok {
+			return nil, fmt.Errorf("both %q and %q would be renamed to %q",
+				filepath.Base(other), filepath.Base(item), newNames[i])
+		}
+		dsts[dst] = item
+		if dst != item {
+			ops = append(ops, renameOp{src: item, dst: dst})
+		}
+	}
+	return ops, nil
+}
+
+// getRenameTarget returns the path item would be renamed to, or why it cannot get the
+// new name. Only the items in srcs, which are being renamed away, can be replaced
+func getRenameTarget(item string, newName string, srcs map[string]bool) (string, error) {
+	if newName == "" {
+		return "", fmt.Errorf("new name of %q is empty", filepath.Base(item))
+	}
+	if strings.ContainsRune(newName, '/') || strings.ContainsRune(newName, filepath.Separator) {
+		return "", fmt.Errorf("new name %q cannot contain a path separator", newName)
+	}
+	if err := checkFileNameValidity(newName); err != nil {
+		return "", fmt.Errorf("invalid name %q: %w", newName, err)
+	}
+	dst := filepath.Join(filepath.Dir(item), newName)
+	if dst == item || srcs[dst] || isSameFileRename(item, dst) {
+		return dst, nil
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return "", fmt.Errorf("%q already exists", newName)
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	return dst, nil
+}
+
+// Changing only the case of a name on a case insensitive filesystem finds the item itself at dst
+func isSameFileRename(src string, dst string) bool {
+	srcInfo, err := os.Lstat(src)
+	if err != nil {
+		return false
+	}
+	dstInfo, err := os.Lstat(dst)
+	return err == nil && os.SameFile(srcInfo, dstInfo)
+}
+
+// executeRenameOperation applies the renames as a single process, and records them in the
+// journal. Used by both the bulk and the pattern rename
+func executeRenameOperation(processBarModel *processbar.Model, ops []renameOp,
+	j *journal.Journal) processbar.ProcessState {
+	p, err := processBarModel.SendAddProcessMsg(icon.Rename+icon.Space+"Renaming "+
+		strconv.Itoa(len(ops))+" item(s)", len(ops), true)
+	if err != nil {
+		slog.Error("Cannot spawn a new process", "error", err)
+		return processbar.Failed
+	}
+	p.Operation = operationRename
+	for _, op := range ops {
+		p.Sources = append(p.Sources, op.src)
+	}
+
+	items, err := executeRenamePlan(ops)
+	if err != nil {
+		slog.Error("Error while applying renames", "error", err)
+		p.State = processbar.Failed
+		p.AddError(err.Error())
+	} else {
+		p.State = processbar.Successful
+		p.Done = len(ops)
+		j.Record(journal.RenameOperation, items, nil)
+	}
+	p.DoneTime = time.Now()
+	err = processBarModel.SendUpdateProcessMsg(p, true)
+	if err != nil {
+		slog.Error("Failed to send final rename update", "error", err)
+	}
+	return p.State
+}
+
+// executeRenamePlan moves every item to a temporary name first, and then to its new name,
+// so that swaps and cycles do not overwrite each other. If anything fails, the items
+// that were already renamed are moved back
+func executeRenamePlan(ops []renameOp) ([]journal.Item, error) {
+	tmps := make([]string, len(ops))
+	for i, op := range ops {
+		tmp, err := getRenameTmpPath(op.src, i)
+		if err == nil {
+			err = os.Rename(op.src, tmp)
+		}
+		if err != nil {
+			rollbackRenamePlan(ops[:i], tmps[:i], 0)
+			return nil, fmt.Errorf("failed to rename %q: %w", filepath.Base(op.src), err)
+		}
+		tmps[i] = tmp
+	}
+
+	items := make([]journal.Item, 0, len(ops))
+	for i, op := range ops {
+		if err := os.Rename(tmps[i], op.dst); err != nil {
+			rollbackRenamePlan(ops, tmps, i)
+			return nil, fmt.Errorf("failed to rename %q to %q: %w",
+				filepath.Base(op.src), filepath.Base(op.dst), err)
+		}
+		items = append(items, journal.NewItem(op.src, op.dst))
+	}
+	return items, nil
+}
+
+// rollbackRenamePlan undoes the first renamedCnt renames to the new names, and then the
+// renames to the temporary names
+func rollbackRenamePlan(ops []renameOp, tmps []string, renamedCnt int) {
+	for i := range renamedCnt {
+		if err := os.Rename(ops[i].dst, tmps[i]); err != nil {
+			slog.Error("Could not roll back rename", "path", ops[i].dst, "error", err)
+		}
+	}
+	for i := range ops {
+		if err := os.Rename(tmps[i], ops[i].src); err != nil {
+			slog.Error("Could not roll back rename", "path", tmps[i], "original", ops[i].src, "error", err)
+		}
+	}
+}
+
+// Temporary name next to src, that nothing uses
+func getRenameTmpPath(src string, idx int) (string, error) {
+	prefix := ".spf-rename-" + strconv.Itoa(os.Getpid()) + "-" + strconv.Itoa(idx)
+	for i := range 100 {
+		tmp := filepath.Join(filepath.Dir(src), prefix+"-"+strconv.Itoa(i))
+		if _, err := os.Lstat(tmp); os.IsNotExist(err) {
+			return tmp, nil
+		}
+	}
+	return "", errors.New("could not find a free temporary name")
+}
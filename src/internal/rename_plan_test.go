@@ -0,0 +1,202 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
+	"github.com/yorukot/superfile/src/internal/utils"
+)
+
+func TestBuildRenamePlan(t *testing.T) {
+	curTestDir := t.TempDir()
+	a := filepath.Join(curTestDir, "a.txt")
+	b := filepath.Join(curTestDir, "b.txt")
+	c := filepath.Join(curTestDir, "c.txt")
+	other := filepath.Join(curTestDir, "other.txt")
+	utils.SetupFiles(t, a, b, c, other)
+
+	testdata := []struct {
+		name        string
+		items       []string
+		newNames    []string
+		expectedOps []renameOp
+		expectedErr bool
+	}{
+		{
+			name:        "Unchanged names are left out",
+			items:       []string{a, b},
+			newNames:    []string{"a.txt", "d.txt"},
+			expectedOps: []renameOp{{src: b, dst: filepath.Join(curTestDir, "d.txt")}},
+		},
+		{
+			name:        "Swap",
+			items:       []string{a, b},
+			newNames:    []string{"b.txt", "a.txt"},
+			expectedOps: []renameOp{{src: a, dst: b}, {src: b, dst: a}},
+		},
+		{
+			name:        "Cycle",
+			items:       []string{a, b, c},
+			newNames:    []string{"b.txt", "c.txt", "a.txt"},
+			expectedOps: []renameOp{{src: a, dst: b}, {src: b, dst: c}, {src: c, dst: a}},
+		},
+		{
+			name:        "Line removed",
+			items:       []string{a, b},
+			newNames:    []string{"a.txt"},
+			expectedErr: true,
+		},
+		{
+			name:        "Empty name",
+			items:       []string{a},
+			newNames:    []string{""},
+			expectedErr: true,
+		},
+		{
+			name:        "Path separator",
+			items:       []string{a},
+			newNames:    []string{"dir/a.txt"},
+			expectedErr: true,
+		},
+		{
+			name:        "Invalid name",
+			items:       []string{a},
+			newNames:    []string{".."},
+			expectedErr: true,
+		},
+		{
+			name:        "Duplicate names",
+			items:       []string{a, b},
+			newNames:    []string{"d.txt", "d.txt"},
+			expectedErr: true,
+		},
+		{
+			name:        "Duplicate of an unchanged name",
+			items:       []string{a, b},
+			newNames:    []string{"a.txt", "a.txt"},
+			expectedErr: true,
+		},
+		{
+			name:        "Name of a file that is not renamed",
+			items:       []string{a},
+			newNames:    []string{"other.txt"},
+			expectedErr: true,
+		},
+	}
+	for _, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := buildRenamePlan(tt.items, tt.newNames)
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOps, ops)
+		})
+	}
+}
+
+func TestExecuteRenamePlan(t *testing.T) {
+	setup := func(t *testing.T, names ...string) []string {
+		curTestDir := t.TempDir()
+		var paths []string
+		for _, name := range names {
+			path := filepath.Join(curTestDir, name)
+			utils.SetupFilesWithData(t, []byte(name), path)
+			paths = append(paths, path)
+		}
+		return paths
+	}
+	assertContent := func(t *testing.T, path string, expected string) {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(data))
+	}
+
+	t.Run("Cycle", func(t *testing.T) {
+		p := setup(t, "a", "b", "c")
+		items, err := executeRenamePlan([]renameOp{{src: p[0], dst: p[1]}, {src: p[1], dst: p[2]},
+			{src: p[2], dst: p[0]}})
+		require.NoError(t, err)
+		assert.Len(t, items, 3)
+		assertContent(t, p[0], "c")
+		assertContent(t, p[1], "a")
+		assertContent(t, p[2], "b")
+		entries, err := os.ReadDir(filepath.Dir(p[0]))
+		require.NoError(t, err)
+		assert.Len(t, entries, 3, "Temporary names should not be left behind")
+	})
+
+	t.Run("Failure rolls back", func(t *testing.T) {
+		p := setup(t, "a", "b")
+		_, err := executeRenamePlan([]renameOp{{src: p[0], dst: p[1]},
+			{src: p[1], dst: filepath.Join(filepath.Dir(p[1]), "missing", "b")}})
+		require.Error(t, err)
+		assertContent(t, p[0], "a")
+		assertContent(t, p[1], "b")
+		entries, err := os.ReadDir(filepath.Dir(p[0]))
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+	})
+}
+
+func TestBulkRename(t *testing.T) {
+	curTestDir := t.TempDir()
+	a := filepath.Join(curTestDir, "a.txt")
+	b := filepath.Join(curTestDir, "b.txt")
+	utils.SetupFilesWithData(t, []byte("a"), a)
+	utils.SetupFilesWithData(t, []byte("b"), b)
+
+	m := defaultTestModel(curTestDir)
+	setupPanelModeAndSelection(t, m, true, "", []string{a, b})
+	edited := func(t *testing.T, content string) BulkRenameEditedMsg {
+		tmpPath := filepath.Join(t.TempDir(), "names.txt")
+		require.NoError(t, os.WriteFile(tmpPath, []byte(content), 0o644))
+		return NewBulkRenameEditedMsg([]string{a, b}, tmpPath, nil, 0)
+	}
+
+	t.Run("Invalid names are rejected", func(t *testing.T) {
+		TeaUpdate(m, edited(t, "c.txt\nc.txt\n"))
+		assert.False(t, m.renamePlanModal.open)
+		assert.True(t, m.notifyModel.IsOpen())
+		assert.Equal(t, common.BulkRenameFailedTitle, m.notifyModel.GetTitle())
+		m.notifyModel.Close()
+	})
+
+	t.Run("Cancelled plan", func(t *testing.T) {
+		TeaUpdate(m, edited(t, "c.txt\nd.txt\n"))
+		require.True(t, m.renamePlanModal.open)
+		m.renamePlanKey(common.Hotkeys.CancelTyping[0])
+		assert.False(t, m.renamePlanModal.open)
+		assert.FileExists(t, a)
+		assert.NoFileExists(t, filepath.Join(curTestDir, "c.txt"))
+	})
+
+	t.Run("Swap and undo", func(t *testing.T) {
+		TeaUpdate(m, edited(t, "b.txt\r\na.txt\r\n"))
+		require.True(t, m.renamePlanModal.open)
+		assert.Len(t, m.renamePlanModal.ops, 2)
+		cmd := m.renamePlanKey(common.Hotkeys.Confirm[0])
+		assert.False(t, m.renamePlanModal.open)
+		require.NotNil(t, cmd)
+		msg, ok := cmd().(RenameOperationMsg)
+		require.True(t, ok)
+		assert.Equal(t, processbar.Successful, msg.state)
+		data, err := os.ReadFile(a)
+		require.NoError(t, err)
+		assert.Equal(t, "b", string(data))
+
+		processBar := processbar.New()
+		require.NoError(t, switchJournalEntry(m.journal, &processBar, true))
+		data, err = os.ReadFile(a)
+		require.NoError(t, err)
+		assert.Equal(t, "a", string(data))
+	})
+}
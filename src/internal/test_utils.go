@@ -12,6 +12,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/journal"
+	"github.com/yorukot/superfile/src/internal/ui/processbar"
 	"github.com/yorukot/superfile/src/internal/utils"
 )
 
@@ -34,6 +36,13 @@ func defaultTestModelWithZClient(zClient *zoxidelib.Client, dirs ...string) *mod
 
 func setModelParamsForTest(m *model) *model {
 	m.disableMetadata = true
+	// Dont touch the user's journal file
+	m.journal = journal.New("")
+	m.processBarModel.SetHistory(processbar.NewHistory(""))
+	m.compressModal = newCompressModal("")
+	// The panels are polled, so that tests do not depend on the timing of the watcher
+	_ = m.watcher.Close()
+	m.watcher = nil
 	TeaUpdate(m, tea.WindowSizeMsg{Width: DefaultTestModelWidth, Height: DefaultTestModelHeight})
 	return m
 }
@@ -42,6 +42,29 @@ func (p *TeaProg) getModel() *model {
 	return p.m
 }
 
+// modelReadMsg calls read with the model, from the event loop
+type modelReadMsg struct {
+	BaseMessage
+
+	read func(m *model)
+}
+
+func (msg modelReadMsg) ApplyToModel(m *model) tea.Cmd {
+	msg.read(m)
+	return nil
+}
+
+// ReadModel calls read with the model from the event loop, and waits for it. Reading the
+// model from another goroutine while the event loop runs is a data race
+func (p *TeaProg) ReadModel(read func(m *model)) {
+	done := make(chan struct{})
+	p.Send(modelReadMsg{read: func(m *model) {
+		read(m)
+		close(done)
+	}})
+	<-done
+}
+
 func (p *TeaProg) StartEventLoop() {
 	go func() {
 		_, err := p.prog.Run()
@@ -5,6 +5,8 @@ import (
 
 	zoxidelib "github.com/lazysegtree/go-zoxide"
 
+	"github.com/yorukot/superfile/src/internal/fswatch"
+	"github.com/yorukot/superfile/src/internal/journal"
 	"github.com/yorukot/superfile/src/internal/ui/metadata"
 	"github.com/yorukot/superfile/src/internal/ui/notify"
 	"github.com/yorukot/superfile/src/internal/ui/processbar"
@@ -12,8 +14,10 @@ import (
 
 	"github.com/charmbracelet/bubbles/textinput"
 
+	contentsearchui "github.com/yorukot/superfile/src/internal/ui/contentsearch"
 	"github.com/yorukot/superfile/src/internal/ui/preview"
 	"github.com/yorukot/superfile/src/internal/ui/prompt"
+	trashui "github.com/yorukot/superfile/src/internal/ui/trash"
 	zoxideui "github.com/yorukot/superfile/src/internal/ui/zoxide"
 )
 
@@ -34,6 +38,8 @@ const (
 	globalType hotkeyType = iota
 	normalType
 	selectType
+	// Only works in the modal of its help menu section
+	modalType
 )
 
 // Constants for panel with no focus
@@ -48,6 +54,10 @@ const (
 const (
 	selectMode panelMode = iota
 	browserMode
+	// Browsing with the directories expanded inline, as a tree
+	treeMode
+	// Listing every file under the directory, with their relative paths
+	flatMode
 )
 
 const (
@@ -58,6 +68,22 @@ const (
 	quitDone
 )
 
+// Kinds of operations of the processes, shown in their details
+const (
+	operationCopy            = "Copy"
+	operationMove            = "Move"
+	operationDelete          = "Delete"
+	operationTrash           = "Move to trash"
+	operationCompress        = "Compress"
+	operationExtract         = "Extract"
+	operationRestore         = "Restore from trash"
+	operationDeleteFromTrash = "Delete from trash"
+	operationRename          = "Rename"
+	operationSymlink         = "Symlink"
+	operationHardlink        = "Hardlink"
+	operationContentSearch   = "Content search"
+)
+
 // Main model
 // TODO : We could consider using *model as tea.Model, instead of model.
 // for reducing re-allocations. The struct is 20K bytes. But this could lead to
@@ -77,16 +103,48 @@ type model struct {
 	helpMenu    helpMenuModal
 	promptModal prompt.Model
 	zoxideModal zoxideui.Model
+	trashModal  trashui.Model
+
+	contentSearchModal contentsearchui.Model
+
+	// Renames of a bulk rename, waiting for a confirmation
+	renamePlanModal renamePlanModal
+
+	// Bulk rename of the selected items by a pattern
+	patternRenameModal patternRenameModal
+
+	// Archive format picker, for compressing the selected items
+	compressModal compressModal
+
+	// Destination and conflict policy of an extraction
+	extractModal extractModal
+
+	// Password of an archive being extracted, or of a zip being created
+	passwordModal passwordModal
+
+	// Picker of the file panel that items are copied or moved to
+	panelPickerModal panelPickerModal
+
+	// Paste operation waiting on the conflict dialog
+	pendingPaste *pendingPaste
+
+	// Performed file operations, for undo and redo
+	journal *journal.Journal
 
 	// Zoxide client for directory tracking
 	zClient *zoxidelib.Client
 
+	// Reports the changes in the directories of the file panels. The directories that
+	// it cannot watch are polled
+	watcher *fswatch.Watcher
+	// The files found by the listings of the flat view are sent here
+	flatBatches chan flatBatch
+
 	fileMetaData         metadata.Model
 	ioReqCnt             int
 	modelQuitState       modelQuitStateType
 	firstTextInput       bool
 	toggleDotFile        bool
-	updatedToggleDotFile bool
 	toggleFooter         bool
 	firstLoadingComplete bool
 	firstUse             bool
@@ -110,6 +168,114 @@ type model struct {
 }
 
 // Modal
+type renamePlanModal struct {
+	open        bool
+	ops         []renameOp
+	renderIndex int
+	width       int
+	height      int
+}
+
+// Modal
+type compressModal struct {
+	open bool
+	// Items to compress, and the directory the archive is created in
+	items    []string
+	location string
+	// Index of the chosen format in archiveFormats
+	cursor int
+	level  int
+	// Ask for a password to encrypt the archive with. Not remembered with the choice
+	encrypt bool
+	// File the last choice is remembered in
+	choiceFile string
+}
+
+// Modal
+type panelPickerModal struct {
+	open bool
+	// Items to copy or move, and whether they are moved
+	items []string
+	cut   bool
+	// Indexes of the file panels that can be picked, starting with the next one
+	panels []int
+	cursor int
+}
+
+// Modal
+type extractModal struct {
+	open    bool
+	archive string
+	// Paths of the members to extract, inside the archive. The whole archive if empty
+	members []string
+	dest    textinput.Model
+	policy  pasteConflictPolicy
+	// Why the destination cannot be extracted to, if it cannot
+	destErr string
+}
+
+// Modal
+type passwordModal struct {
+	open      bool
+	textInput textinput.Model
+	// Why the password was not accepted, like a wrong password
+	errorMessage string
+	// What the password is for, shown above the input
+	title string
+	// The extraction, compression or paste waiting on the password. Only one of them is set
+	extract  *pendingExtract
+	compress *pendingCompress
+	paste    *pendingPaste
+}
+
+type pendingExtract struct {
+	archive string
+	opts    extractOptions
+}
+
+type pendingCompress struct {
+	items    []string
+	location string
+	opts     compressOptions
+}
+
+// Modal
+type patternRenameModal struct {
+	open  bool
+	items []string
+	// Regex to find in the names, and what to replace it with
+	find    textinput.Model
+	replace textinput.Model
+	// Whether the replace input has the focus, instead of find
+	replaceFocused bool
+	nameCase       renameCase
+	// New names of the items, in the same order
+	rows []patternRenameRow
+	// Why the pattern itself is invalid, if it is
+	patternErr string
+	// Exif dates are fetched only once for every item, in the background as it runs exiftool
+	exifDates map[string]exifDateResult
+	// Items whose exif date is needed by the preview, and is not being fetched yet
+	exifToFetch []string
+	renderIndex int
+	width       int
+	height      int
+}
+
+type patternRenameRow struct {
+	src     string
+	newName string
+	// Why the item cannot be renamed to newName, if it cannot
+	conflict string
+	// Whether the new name waits for the exif date of the item
+	pending bool
+}
+
+type exifDateResult struct {
+	date time.Time
+	err  error
+}
+
 type helpMenuModal struct {
 	height       int
 	width        int
@@ -141,6 +307,55 @@ type copyItems struct {
 	cut   bool
 }
 
+// Decides what happens when a pasted item already exists at the destination.
+// The order matches the choices shown in the conflict dialog
+type pasteConflictPolicy int
+
+const (
+	conflictOverwrite pasteConflictPolicy = iota
+	conflictSkip
+	conflictKeepBoth
+	conflictOverwriteIfNewer
+	conflictCompareSizes
+)
+
+// Kind of the links created when pasting the clipboard items as links
+type linkKind int
+
+const (
+	// The items are pasted, not linked
+	noLink linkKind = iota
+	symlinkAbsolute
+	// Symlinks with a target relative to the directory of the link
+	symlinkRelative
+	hardlink
+)
+
+// Count and total size of the files in an item, for progress tracking
+type itemSize struct {
+	files int
+	bytes int64
+}
+
+// A paste operation waiting for the user to resolve its conflicts
+type pendingPaste struct {
+	reqID         int
+	panelLocation string
+	items         []string
+	cut           bool
+	// Links to create to the items, instead of pasting them
+	link linkKind
+	// Source paths whose destination already exists, and are yet to be resolved
+	conflicts []string
+	// Resolved policy for each conflicting source path
+	policies map[string]pasteConflictPolicy
+	// Password of the encrypted archive the items are copied out of
+	password string
+	// Trash items to restore to their original location, instead of pasting the items.
+	// Their conflicts are keyed by original path
+	restore []trashui.Item
+}
+
 /* FILE WINDOWS TYPE START*/
 // Model for file windows
 type fileModel struct {
@@ -149,6 +364,9 @@ type fileModel struct {
 	renaming     bool
 	maxFilePanel int
 	filePreview  preview.Model
+
+	// Parent directory of the focused panel, shown on its left in the Miller columns layout
+	parentColumn filePanel
 }
 
 // Panel representing a file
@@ -162,10 +380,26 @@ type filePanel struct {
 	selected           []string
 	element            []element
 	directoryRecords   map[string]directoryRecord
+	expandedDirs       map[string]struct{}
+	flatListing        *flatListing
 	rename             textinput.Model
 	renaming           bool
 	searchBar          textinput.Model
 	lastTimeGetElement time.Time
+	lastElementSource  elementSource
+}
+
+// What the elements of a file panel are read from. They are read again as soon as it changes
+type elementSource struct {
+	location     string
+	search       string
+	sortSelected int
+	sortReversed bool
+	dotFiles     bool
+	tree         bool
+	flat         bool
+	// Number of files listed so far by the flat view
+	flatListed int
 }
 
 // Sort options
@@ -195,6 +429,11 @@ type element struct {
 	location  string
 	directory bool
 	metaData  [][2]string
+	// What the element points to, if it is a symlink. Empty otherwise
+	symlinkTarget string
+	// Nesting level and indentation guides of the element in the tree view
+	depth     int
+	treeGuide string
 }
 
 /* FILE WINDOWS TYPE END*/
@@ -2,8 +2,10 @@ package internal
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui/notify"
 	"github.com/yorukot/superfile/src/internal/utils"
 )
 
@@ -68,6 +70,7 @@ func defaultFilePanel(dir string, focused bool) filePanel {
 		panelMode:        browserMode,
 		isFocused:        focused,
 		directoryRecords: make(map[string]directoryRecord),
+		expandedDirs:     make(map[string]struct{}),
 		searchBar:        common.GenerateSearchBar(),
 	}
 }
@@ -95,7 +98,55 @@ func (p panelMode) String() string {
 		return "selectMode"
 	case browserMode:
 		return "browserMode"
+	case treeMode:
+		return "treeMode"
+	case flatMode:
+		return "flatMode"
 	default:
 		return invalidTypeString
 	}
 }
+
+func (p pasteConflictPolicy) String() string {
+	switch p {
+	case conflictOverwrite:
+		return "Overwrite"
+	case conflictSkip:
+		return "Skip"
+	case conflictKeepBoth:
+		return "Keep both"
+	case conflictOverwriteIfNewer:
+		return "Overwrite if newer"
+	case conflictCompareSizes:
+		return "Compare sizes (overwrite if different)"
+	default:
+		return invalidTypeString
+	}
+}
+
+// Operation of the processes creating links of this kind
+func (k linkKind) operation() string {
+	if k == hardlink {
+		return operationHardlink
+	}
+	return operationSymlink
+}
+
+// Choices shown in the conflict dialog. Index of each choice is its pasteConflictPolicy value
+func pasteConflictChoices() []string {
+	return []string{
+		conflictOverwrite.String(),
+		conflictSkip.String(),
+		conflictKeepBoth.String(),
+		conflictOverwriteIfNewer.String(),
+		conflictCompareSizes.String(),
+	}
+}
+
+// Notify model asking the user how to resolve the first pending conflict
+func (p *pendingPaste) conflictModal() notify.Model {
+	name := common.TruncateText(filepath.Base(p.conflicts[0]), common.ModalWidth-20, "...")
+	return notify.NewWithChoices(true, common.PasteConflictTitle,
+		fmt.Sprintf(common.PasteConflictContent, name, len(p.conflicts)),
+		notify.PasteConflictAction, pasteConflictChoices())
+}
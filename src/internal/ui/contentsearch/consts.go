@@ -0,0 +1,28 @@
+package contentsearch
+
+import "time"
+
+const (
+	contentSearchHeadlineText = "Content Search"
+
+	ContentSearchMinWidth  = 30
+	ContentSearchMinHeight = 9
+
+	// Lines other than the matches. Borders(2), the query, the status line, the hint line and
+	// the three dividers
+	nonMatchLines = 8
+
+	// The search stops once that many matches are found
+	maxMatches = 10000
+	// The excerpts of longer lines are cut
+	maxExcerptLen = 200
+	// Lines longer than that are skipped
+	maxLineLen = 1024 * 1024
+	// Files whose first bytes are not printable are considered binary, and skipped
+	binaryCheckLen = 1024
+
+	// The matches are sent by batches, so that they are shown while the search is running,
+	// without an update per match
+	batchSize     = 500
+	batchInterval = 100 * time.Millisecond
+)
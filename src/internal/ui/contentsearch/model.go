@@ -0,0 +1,159 @@
+package contentsearch
+
+import (
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/yorukot/superfile/src/config/icon"
+	"github.com/yorukot/superfile/src/internal/common"
+)
+
+func DefaultModel() Model {
+	return New(ContentSearchMinHeight, ContentSearchMinWidth)
+}
+
+func New(maxHeight int, width int) Model {
+	m := Model{
+		headline:  icon.Search + icon.Space + contentSearchHeadlineText,
+		textInput: common.GeneratePromptTextInput(),
+		matches:   []Match{},
+	}
+	m.textInput.Placeholder = "Text to search for"
+	m.SetMaxHeight(maxHeight)
+	m.SetWidth(width)
+	return m
+}
+
+// Open shows the modal for a search under location. The matches of the previous search
+// are not kept
+func (m *Model) Open(location string) {
+	m.open = true
+	m.location = location
+	m.textInput.SetValue("")
+	_ = m.textInput.Focus()
+}
+
+// Close cancels the running search
+func (m *Model) Close() {
+	m.open = false
+	m.textInput.Blur()
+	m.textInput.SetValue("")
+	m.setSearch(nil)
+}
+
+func (m *Model) setSearch(search *Search) {
+	if m.search != nil {
+		m.search.Cancel()
+	}
+	m.search = search
+	m.searching = search != nil
+	m.truncated = false
+	m.searchErr = nil
+	m.matches = []Match{}
+	m.cursor = 0
+	m.renderIndex = 0
+}
+
+func (m *Model) IsOpen() bool {
+	return m.open
+}
+
+// UpdateInput updates the query input with msg
+func (m *Model) UpdateInput(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return cmd
+}
+
+func (m *Model) ToggleRegex() {
+	m.regex = !m.regex
+}
+
+func (m *Model) GetQuery() Query {
+	return Query{Text: m.textInput.Value(), Regex: m.regex}
+}
+
+// NeedsSearch reports whether the query was changed since the last search
+func (m *Model) NeedsSearch() bool {
+	query := m.GetQuery()
+	if query.Text == "" {
+		return false
+	}
+	return m.search == nil || m.search.Query() != query
+}
+
+// StartSearch cancels the running search, and returns a new one for the query, which the
+// caller has to Run. An invalid query is shown in the modal, and nil is returned
+func (m *Model) StartSearch(dotFiles bool) *Search {
+	search, err := NewSearch(m.location, m.GetQuery(), dotFiles)
+	if err != nil {
+		m.setSearch(nil)
+		m.searchErr = err
+		return nil
+	}
+	m.setSearch(search)
+	return search
+}
+
+// AddBatch adds the matches of a batch of search. It returns whether more batches of the
+// search have to be received, which is not the case once it is done or replaced
+func (m *Model) AddBatch(search *Search, batch Batch) bool {
+	if search != m.search {
+		slog.Debug("Ignoring the matches of a previous content search")
+		return false
+	}
+	m.matches = append(m.matches, batch.Matches...)
+	if batch.Done {
+		m.searching = false
+		m.truncated = batch.Truncated
+		m.searchErr = batch.Err
+	}
+	return !batch.Done
+}
+
+// GetSelectedMatch returns the match under the cursor, and false if there is none
+func (m *Model) GetSelectedMatch() (Match, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.matches) {
+		return Match{}, false
+	}
+	return m.matches[m.cursor], true
+}
+
+func (m *Model) GetMatches() []Match {
+	out := make([]Match, len(m.matches))
+	copy(out, m.matches)
+	return out
+}
+
+func (m *Model) IsSearching() bool {
+	return m.searching
+}
+
+func (m *Model) GetWidth() int {
+	return m.width
+}
+
+func (m *Model) GetMaxHeight() int {
+	return m.maxHeight
+}
+
+func (m *Model) SetWidth(width int) {
+	if width < ContentSearchMinWidth {
+		slog.Warn("Content search initialized with too less width", "width", width)
+		width = ContentSearchMinWidth
+	}
+	m.width = width
+	// Excluding borders(2) and the padding(1), and one extra character that is appended by
+	// textInput.View()
+	m.textInput.Width = width - 2 - 1 - 1
+}
+
+func (m *Model) SetMaxHeight(maxHeight int) {
+	if maxHeight < ContentSearchMinHeight {
+		slog.Warn("Content search initialized with too less maxHeight", "maxHeight", maxHeight)
+		maxHeight = ContentSearchMinHeight
+	}
+	m.maxHeight = maxHeight
+	m.updateRenderIndex()
+}
@@ -0,0 +1,69 @@
+package contentsearch
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModel(t *testing.T) {
+	dir := t.TempDir()
+	// Room for 3 matches
+	m := New(nonMatchLines+3, 80)
+	m.Open(dir)
+	assert.True(t, m.IsOpen())
+	assert.False(t, m.NeedsSearch(), "An empty query is not searched")
+
+	m.textInput.SetValue("foo")
+	require.True(t, m.NeedsSearch())
+	search := m.StartSearch(false)
+	require.NotNil(t, search)
+	assert.True(t, m.IsSearching())
+	assert.False(t, m.NeedsSearch())
+
+	var matches []Match
+	for i := range 5 {
+		matches = append(matches, Match{Path: filepath.Join(dir, fmt.Sprintf("file%d.txt", i)), Line: 1, Text: "foo"})
+	}
+	assert.True(t, m.AddBatch(search, Batch{Matches: matches[:2]}))
+	assert.False(t, m.AddBatch(search, Batch{Matches: matches[2:], Done: true}))
+	assert.False(t, m.IsSearching())
+	assert.Equal(t, matches, m.GetMatches())
+
+	t.Run("Navigation", func(t *testing.T) {
+		match, ok := m.GetSelectedMatch()
+		require.True(t, ok)
+		assert.Equal(t, matches[0], match)
+		m.ListUp()
+		assert.Equal(t, 4, m.cursor, "Should wrap to the bottom")
+		assert.Equal(t, 2, m.renderIndex)
+		m.ListDown()
+		assert.Equal(t, 0, m.cursor, "Should wrap to the top")
+		assert.Equal(t, 0, m.renderIndex)
+	})
+
+	t.Run("Stale batches are ignored", func(t *testing.T) {
+		m.ToggleRegex()
+		require.True(t, m.NeedsSearch())
+		newSearch := m.StartSearch(false)
+		require.NotNil(t, newSearch)
+		_, ok := search.Next()
+		assert.False(t, ok, "The previous search should be cancelled")
+		assert.False(t, m.AddBatch(search, Batch{Matches: matches}))
+		assert.Empty(t, m.GetMatches())
+	})
+
+	t.Run("Invalid regex", func(t *testing.T) {
+		m.textInput.SetValue("foo(")
+		assert.Nil(t, m.StartSearch(false))
+		assert.Error(t, m.searchErr)
+		assert.False(t, m.IsSearching())
+	})
+
+	m.Close()
+	assert.False(t, m.IsOpen())
+	assert.Empty(t, m.GetMatches())
+}
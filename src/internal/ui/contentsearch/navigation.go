@@ -0,0 +1,39 @@
+package contentsearch
+
+func (m *Model) ListUp() {
+	if len(m.matches) == 0 {
+		return
+	}
+	if m.cursor > 0 {
+		m.cursor--
+	} else {
+		m.cursor = len(m.matches) - 1 // Wrap to bottom
+	}
+	m.updateRenderIndex()
+}
+
+func (m *Model) ListDown() {
+	if len(m.matches) == 0 {
+		return
+	}
+	if m.cursor < len(m.matches)-1 {
+		m.cursor++
+	} else {
+		m.cursor = 0 // Wrap to top
+	}
+	m.updateRenderIndex()
+}
+
+func (m *Model) visibleMatchCnt() int {
+	return m.maxHeight - nonMatchLines
+}
+
+func (m *Model) updateRenderIndex() {
+	if m.cursor < m.renderIndex {
+		m.renderIndex = m.cursor
+	}
+	if m.cursor >= m.renderIndex+m.visibleMatchCnt() {
+		m.renderIndex = m.cursor - m.visibleMatchCnt() + 1
+	}
+	m.renderIndex = max(min(m.renderIndex, len(m.matches)-m.visibleMatchCnt()), 0)
+}
@@ -0,0 +1,84 @@
+package contentsearch
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui"
+)
+
+func (m *Model) Render() string {
+	r := ui.ContentSearchRenderer(m.maxHeight, m.width)
+	r.SetBorderTitle(m.headline)
+
+	r.AddLines(" " + m.textInput.View())
+	r.AddSection()
+	r.AddLines(common.TruncateText(" "+m.renderStatus(), m.width-2, "..."))
+	r.AddSection()
+	switch {
+	case m.search == nil:
+		r.AddLines(" Searching under " + common.TruncateTextBeginning(m.location, m.width-19, "..."))
+	case len(m.matches) == 0 && !m.searching:
+		r.AddLines(" No match found")
+	}
+	end := min(m.renderIndex+m.visibleMatchCnt(), len(m.matches))
+	for i := m.renderIndex; i < end; i++ {
+		line := common.TruncateText(" "+m.renderMatch(m.matches[i]), m.width-2, "...")
+		if i == m.cursor {
+			line = common.ModalCursorStyle.Render(line)
+		}
+		r.AddLines(line)
+	}
+	r.AddSection()
+	r.AddLines(" " + m.renderHints())
+	return r.Render()
+}
+
+func (m *Model) renderStatus() string {
+	mode := "Literal"
+	if m.regex {
+		mode = "Regex"
+	}
+	var status string
+	switch {
+	case m.searchErr != nil:
+		status = m.searchErr.Error()
+	case m.search == nil:
+		status = "Not searched yet"
+	case m.searching:
+		status = fmt.Sprintf("Searching... %d match(es)", len(m.matches))
+	case m.truncated:
+		status = fmt.Sprintf("%d match(es), the search stopped there", len(m.matches))
+	default:
+		status = fmt.Sprintf("%d match(es)", len(m.matches))
+	}
+	return mode + " | " + status
+}
+
+// The path of the match is relative to the searched directory
+func (m *Model) renderMatch(match Match) string {
+	path, err := filepath.Rel(m.location, match.Path)
+	if err != nil {
+		path = match.Path
+	}
+	return path + ":" + strconv.Itoa(match.Line) + ": " + match.Text
+}
+
+func (m *Model) renderHints() string {
+	hints := []string{
+		hotkeyHint(common.Hotkeys.ConfirmTyping, "search/open"),
+		hotkeyHint(common.Hotkeys.SelectContentSearchFile, "select file"),
+		hotkeyHint(common.Hotkeys.ToggleContentSearchRegex, "regex"),
+	}
+	return strings.Join(hints, "  ")
+}
+
+func hotkeyHint(hotkeys []string, action string) string {
+	if len(hotkeys) == 0 {
+		return ""
+	}
+	return hotkeys[0] + ": " + action
+}
@@ -0,0 +1,299 @@
+package contentsearch
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yorukot/superfile/src/internal/archivefs"
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/gitignore"
+)
+
+// ErrCancelled is returned by Run when the search was cancelled with Cancel
+var ErrCancelled = errors.New("search cancelled")
+
+// NewSearch returns a search of query in the files under location. It fails if the query is
+// an invalid regular expression
+func NewSearch(location string, query Query, dotFiles bool) (*Search, error) {
+	if archivefs.IsArchivePath(location) {
+		return nil, errors.New("archives cannot be searched")
+	}
+	s := &Search{
+		location: location,
+		query:    query,
+		dotFiles: dotFiles,
+		batches:  make(chan Batch),
+		cancel:   make(chan struct{}),
+	}
+	if query.Regex {
+		re, err := regexp.Compile(query.Text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		s.re = re
+	}
+	return s, nil
+}
+
+func (s *Search) Location() string {
+	return s.location
+}
+
+func (s *Search) Query() Query {
+	return s.query
+}
+
+// Cancel stops the search. No batch is received after it
+func (s *Search) Cancel() {
+	s.cancelOnce.Do(func() { close(s.cancel) })
+}
+
+func (s *Search) isCancelled() bool {
+	select {
+	case <-s.cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// Next waits for the next batch of matches. It returns false once the search is cancelled
+func (s *Search) Next() (Batch, bool) {
+	select {
+	case batch := <-s.batches:
+		return batch, true
+	case <-s.cancel:
+		return Batch{}, false
+	}
+}
+
+func (s *Search) send(batch Batch) bool {
+	select {
+	case s.batches <- batch:
+		return true
+	case <-s.cancel:
+		return false
+	}
+}
+
+func (s *Search) matchLine(line string) bool {
+	if s.re != nil {
+		return s.re.MatchString(line)
+	}
+	return strings.Contains(line, s.query.Text)
+}
+
+func (s *Search) checkpoint(progress Progress) error {
+	if s.isCancelled() {
+		return ErrCancelled
+	}
+	return progress.Checkpoint()
+}
+
+type fileResult struct {
+	matches []Match
+	err     error
+}
+
+// Run lists the files to search, and searches them in parallel. It blocks until the search
+// is done, and sends the matches found to Next meanwhile. The matches of a file are sent
+// together, in the order of the lines
+func (s *Search) Run(progress Progress) error {
+	files, err := s.listFiles(progress)
+	if err == nil {
+		progress.SetTotal(len(files))
+		err = s.searchFiles(files, progress)
+	}
+	if errors.Is(err, errMaxMatches) {
+		s.send(Batch{Done: true, Truncated: true})
+		return nil
+	}
+	s.send(Batch{Done: true, Err: err})
+	return err
+}
+
+var errMaxMatches = errors.New("too many matches")
+
+func (s *Search) searchFiles(files []string, progress Progress) error {
+	jobs := make(chan string)
+	results := make(chan fileResult)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		defer close(jobs)
+		for _, file := range files {
+			select {
+			case jobs <- file:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	var wg sync.WaitGroup
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				matches, err := s.searchFile(file)
+				select {
+				case results <- fileResult{matches: matches, err: err}:
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var batch []Match
+	lastSent := time.Now()
+	matchCnt := 0
+	for result := range results {
+		if err := s.checkpoint(progress); err != nil {
+			return err
+		}
+		progress.FileSearched()
+		// Unreadable files are skipped, like the binary ones
+		if result.err != nil {
+			continue
+		}
+		if matchCnt+len(result.matches) > maxMatches {
+			result.matches = result.matches[:maxMatches-matchCnt]
+		}
+		matchCnt += len(result.matches)
+		batch = append(batch, result.matches...)
+		if matchCnt == maxMatches || len(batch) >= batchSize || time.Since(lastSent) >= batchInterval {
+			if len(batch) > 0 && !s.send(Batch{Matches: batch}) {
+				return ErrCancelled
+			}
+			batch = nil
+			lastSent = time.Now()
+		}
+		if matchCnt == maxMatches {
+			return errMaxMatches
+		}
+	}
+	if len(batch) > 0 && !s.send(Batch{Matches: batch}) {
+		return ErrCancelled
+	}
+	return nil
+}
+
+// listFiles returns the regular files under the location. The files ignored by .gitignore
+// files are skipped, as well as the dot files if they are hidden
+func (s *Search) listFiles(progress Progress) ([]string, error) {
+	var files []string
+	rules := map[string]gitignore.Rules{s.location: gitignore.RulesFor(s.location)}
+	err := filepath.WalkDir(s.location, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped
+			if d != nil && d.IsDir() && path != s.location {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if path == s.location {
+			return nil
+		}
+		if err := s.checkpoint(progress); err != nil {
+			return err
+		}
+		name := d.Name()
+		dirRules := rules[filepath.Dir(path)]
+		if (name == ".git" && d.IsDir()) || (!s.dotFiles && strings.HasPrefix(name, ".")) ||
+			dirRules.Ignored(path, d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			rules[path] = dirRules.With(path)
+		} else if d.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// searchFile returns the lines of the file that match, unless the file is binary
+func (s *Search) searchFile(path string) ([]Match, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	head, err := reader.Peek(binaryCheckLen)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	if !common.IsBufferPrintable(head) {
+		return nil, nil
+	}
+
+	var matches []Match
+	var buf []byte
+	for lineNum := 1; ; lineNum++ {
+		var tooLong bool
+		buf, tooLong, err = readLine(reader, buf)
+		// Too long lines are skipped, but the lines after them are still searched
+		if !tooLong {
+			line := strings.TrimSuffix(strings.TrimSuffix(string(buf), "\n"), "\r")
+			if s.matchLine(line) {
+				matches = append(matches, Match{Path: path, Line: lineNum, Text: excerpt(line)})
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return matches, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readLine reads the next line into buf, with its line ending. Lines longer than maxLineLen
+// are read to their end without being kept, and reported as too long
+func readLine(reader *bufio.Reader, buf []byte) ([]byte, bool, error) {
+	buf = buf[:0]
+	tooLong := false
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if !tooLong && len(buf)+len(chunk) > maxLineLen+1 {
+			tooLong = true
+			buf = buf[:0]
+		}
+		if !tooLong {
+			buf = append(buf, chunk...)
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return buf, tooLong, err
+		}
+	}
+}
+
+func excerpt(line string) string {
+	line = strings.TrimSpace(strings.ReplaceAll(line, "\t", "    "))
+	if runes := []rune(line); len(runes) > maxExcerptLen {
+		return string(runes[:maxExcerptLen]) + "..."
+	}
+	return line
+}
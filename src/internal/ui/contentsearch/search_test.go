@@ -0,0 +1,101 @@
+package contentsearch
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func batchMatches(batches []Batch) []Match {
+	var matches []Match
+	for _, batch := range batches {
+		matches = append(matches, batch.Matches...)
+	}
+	return matches
+}
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a.txt"), "foo\nbar\n\tfoo bar\n")
+	writeTestFile(t, filepath.Join(dir, "sub", "b.go"), "package sub\n// foo123\n")
+	writeTestFile(t, filepath.Join(dir, "binary.bin"), "foo\x00\x01\x02")
+	writeTestFile(t, filepath.Join(dir, ".hidden"), "foo\n")
+	writeTestFile(t, filepath.Join(dir, ".gitignore"), "ignored/\n")
+	writeTestFile(t, filepath.Join(dir, "ignored", "c.txt"), "foo\n")
+
+	t.Run("Literal search", func(t *testing.T) {
+		search, err := NewSearch(dir, Query{Text: "foo"}, false)
+		require.NoError(t, err)
+		batches, progress, err := runSearch(t, search)
+		require.NoError(t, err)
+		last := batches[len(batches)-1]
+		assert.False(t, last.Truncated)
+		require.NoError(t, last.Err)
+		assert.Equal(t, 3, progress.total)
+		assert.Equal(t, 3, progress.searched)
+		assert.ElementsMatch(t, []Match{
+			{Path: filepath.Join(dir, "a.txt"), Line: 1, Text: "foo"},
+			{Path: filepath.Join(dir, "a.txt"), Line: 3, Text: "foo bar"},
+			{Path: filepath.Join(dir, "sub", "b.go"), Line: 2, Text: "// foo123"},
+		}, batchMatches(batches))
+	})
+
+	t.Run("Dot files are searched when shown", func(t *testing.T) {
+		search, err := NewSearch(dir, Query{Text: "foo"}, true)
+		require.NoError(t, err)
+		batches, _, err := runSearch(t, search)
+		require.NoError(t, err)
+		assert.Contains(t, batchMatches(batches),
+			Match{Path: filepath.Join(dir, ".hidden"), Line: 1, Text: "foo"})
+		assert.Len(t, batchMatches(batches), 4)
+	})
+
+	t.Run("Regex search", func(t *testing.T) {
+		search, err := NewSearch(dir, Query{Text: `foo\d+`, Regex: true}, false)
+		require.NoError(t, err)
+		batches, _, err := runSearch(t, search)
+		require.NoError(t, err)
+		assert.Equal(t, []Match{{Path: filepath.Join(dir, "sub", "b.go"), Line: 2, Text: "// foo123"}},
+			batchMatches(batches))
+	})
+
+	t.Run("Invalid regex", func(t *testing.T) {
+		_, err := NewSearch(dir, Query{Text: "foo(", Regex: true}, false)
+		require.Error(t, err)
+	})
+
+	t.Run("Lines after a too long line are searched", func(t *testing.T) {
+		longDir := t.TempDir()
+		path := filepath.Join(longDir, "long.txt")
+		writeTestFile(t, path, "foo\r\n"+strings.Repeat("foo ", maxLineLen/4+1)+"\nbar foo")
+		search, err := NewSearch(longDir, Query{Text: "foo"}, false)
+		require.NoError(t, err)
+		batches, _, err := runSearch(t, search)
+		require.NoError(t, err)
+		assert.Equal(t, []Match{
+			{Path: path, Line: 1, Text: "foo"},
+			{Path: path, Line: 3, Text: "bar foo"},
+		}, batchMatches(batches))
+	})
+
+	t.Run("Cancelled search", func(t *testing.T) {
+		search, err := NewSearch(dir, Query{Text: "foo"}, false)
+		require.NoError(t, err)
+		search.Cancel()
+		require.ErrorIs(t, search.Run(&testProgress{}), ErrCancelled)
+		_, ok := search.Next()
+		assert.False(t, ok)
+	})
+}
+
+func TestExcerpt(t *testing.T) {
+	assert.Equal(t, "a    b", excerpt("  a\tb  "))
+	long := make([]rune, maxExcerptLen+10)
+	for i := range long {
+		long[i] = 'x'
+	}
+	assert.Equal(t, string(long[:maxExcerptLen])+"...", excerpt(string(long)))
+}
@@ -0,0 +1,52 @@
+package contentsearch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testProgress struct {
+	total    int
+	searched int
+}
+
+func (p *testProgress) SetTotal(total int) {
+	p.total = total
+}
+
+func (p *testProgress) FileSearched() {
+	p.searched++
+}
+
+func (p *testProgress) Checkpoint() error {
+	return nil
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+// runSearch runs search, and returns the batches it sent
+func runSearch(t *testing.T, search *Search) ([]Batch, *testProgress, error) {
+	t.Helper()
+	progress := &testProgress{}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- search.Run(progress)
+	}()
+	var batches []Batch
+	for {
+		batch, ok := search.Next()
+		require.True(t, ok)
+		batches = append(batches, batch)
+		if batch.Done {
+			break
+		}
+	}
+	return batches, progress, <-errCh
+}
@@ -0,0 +1,79 @@
+package contentsearch
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// No need to name it as ContentSearchModel. It will be imported as contentsearch.Model
+type Model struct {
+	// Configuration
+	headline string
+
+	// State
+	open      bool
+	location  string
+	textInput textinput.Model
+	regex     bool
+	// The search whose matches are shown. It is nil until the first search
+	search      *Search
+	searching   bool
+	truncated   bool
+	searchErr   error
+	matches     []Match
+	cursor      int
+	renderIndex int
+
+	// Dimensions
+	width     int
+	maxHeight int
+}
+
+// Query is searched for in each line, as a literal string or as a regular expression
+type Query struct {
+	Text  string
+	Regex bool
+}
+
+// Match is a line of a file containing the query
+type Match struct {
+	Path string
+	// Starts at 1
+	Line int
+	// The line, trimmed and cut if it is too long
+	Text string
+}
+
+// Batch holds the matches found since the previous batch of a search. The last batch is done,
+// and has the error that stopped the search, if any
+type Batch struct {
+	Matches []Match
+	Done    bool
+	// Whether the search stopped at maxMatches
+	Truncated bool
+	Err       error
+}
+
+// Search runs in its own goroutine, and sends its matches to the model by batches
+type Search struct {
+	location string
+	query    Query
+	dotFiles bool
+	re       *regexp.Regexp
+
+	batches    chan Batch
+	cancel     chan struct{}
+	cancelOnce sync.Once
+}
+
+// Progress is told about the progress of a search
+type Progress interface {
+	// SetTotal is called with the number of files to search, once they are listed
+	SetTotal(total int)
+	// FileSearched is called after each searched file, always from the same goroutine
+	FileSearched()
+	// Checkpoint is called between files. The search stops if it returns an error
+	Checkpoint() error
+}
@@ -27,3 +27,9 @@ var sortPriority = map[string]int{ //nolint: gochecknoglobals // This is effecti
 	keyOwner:        5,
 	keyGroup:        6,
 }
+
+// Exiftool tags holding the date a photo was taken, by priority
+var exifDateKeys = []string{"DateTimeOriginal", "CreateDate"} //nolint: gochecknoglobals // This is effectively const.
+
+// Layout of exif dates. Time zone and sub seconds, if any, follow it
+const exifDateLayout = "2006:01:02 15:04:05"
@@ -0,0 +1,43 @@
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/barasher/go-exiftool"
+)
+
+// GetExifDate returns the date the file was taken at, as recorded in its exif metadata
+func GetExifDate(filePath string, et *exiftool.Exiftool) (time.Time, error) {
+	if et == nil {
+		return time.Time{}, errors.New("exiftool is not available")
+	}
+	fileInfos := et.ExtractMetadata(filePath)
+	if len(fileInfos) == 0 {
+		return time.Time{}, errors.New("no metadata found")
+	}
+	if fileInfos[0].Err != nil {
+		return time.Time{}, fileInfos[0].Err
+	}
+	for _, key := range exifDateKeys {
+		value, err := fileInfos[0].GetString(key)
+		if err != nil {
+			continue
+		}
+		return parseExifDate(value)
+	}
+	return time.Time{}, errors.New("no exif date")
+}
+
+// Exif dates have no time zone unless an offset is recorded, so they are taken as local time
+func parseExifDate(value string) (time.Time, error) {
+	if len(value) < len(exifDateLayout) {
+		return time.Time{}, fmt.Errorf("invalid exif date %q", value)
+	}
+	t, err := time.ParseInLocation(exifDateLayout, value[:len(exifDateLayout)], time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid exif date %q", value)
+	}
+	return t, nil
+}
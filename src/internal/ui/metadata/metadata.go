@@ -9,6 +9,7 @@ import (
 
 	"github.com/barasher/go-exiftool"
 
+	"github.com/yorukot/superfile/src/internal/archivefs"
 	"github.com/yorukot/superfile/src/internal/common"
 	"github.com/yorukot/superfile/src/internal/utils"
 )
@@ -81,6 +82,9 @@ func GetMetadata(filePath string, metadataFocussed bool, et *exiftool.Exiftool)
 }
 
 func getMetaDataUnsorted(filePath string, metadataFocussed bool, et *exiftool.Exiftool) Metadata {
+	if archivefs.IsArchivePath(filePath) {
+		return getArchiveMemberMetadata(filePath)
+	}
 	res := Metadata{
 		filepath: filePath,
 	}
@@ -133,6 +137,39 @@ func getMetaDataUnsorted(filePath string, metadataFocussed bool, et *exiftool.Ex
 	return res
 }
 
+// Members of archives only have the metadata kept in the archive
+func getArchiveMemberMetadata(filePath string) Metadata {
+	res := Metadata{
+		filepath: filePath,
+	}
+	fileInfo, err := archivefs.Stat(filePath)
+	if err != nil {
+		res.infoMsg = fileStatErrorMsg
+		return res
+	}
+	size := fileInfo.Size()
+	if fileInfo.IsDir() {
+		// Sizes of the members are known from the index of the archive, so this is cheap
+		size = 0
+		err = archivefs.Walk(filePath, func(_ string, info os.FileInfo) error {
+			if !info.IsDir() {
+				size += info.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Error("Error while getting size of directory in archive", "error", err)
+		}
+	}
+	res.data = append(res.data,
+		[2]string{keyName, fileInfo.Name()},
+		[2]string{keySize, common.FormatFileSize(size)},
+		[2]string{keyDataModified, fileInfo.ModTime().String()},
+		[2]string{keyPermissions, fileInfo.Mode().String()},
+	)
+	return res
+}
+
 func updateExiftoolMetadata(filePath string, et *exiftool.Exiftool, res *Metadata) {
 	if !common.Config.Metadata || et == nil {
 		return
@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/barasher/go-exiftool"
 	"github.com/stretchr/testify/assert"
@@ -48,3 +49,29 @@ func TestGetMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestParseExifDate(t *testing.T) {
+	testdata := []struct {
+		name        string
+		value       string
+		expected    time.Time
+		expectedErr bool
+	}{
+		{"Plain", "2023:07:14 18:30:05", time.Date(2023, 7, 14, 18, 30, 5, 0, time.Local), false},
+		{"Sub seconds and offset", "2023:07:14 18:30:05.12+02:00",
+			time.Date(2023, 7, 14, 18, 30, 5, 0, time.Local), false},
+		{"Too short", "2023:07:14", time.Time{}, true},
+		{"Not a date", "0000:00:00 00:00:00", time.Time{}, true},
+	}
+	for _, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := parseExifDate(tt.value)
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, res)
+		})
+	}
+}
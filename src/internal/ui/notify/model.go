@@ -1,6 +1,9 @@
 package notify
 
 import (
+	"fmt"
+
+	"github.com/yorukot/superfile/src/config/icon"
 	"github.com/yorukot/superfile/src/internal/common"
 )
 
@@ -9,6 +12,11 @@ type Model struct {
 	title         string
 	content       string
 	confirmAction ConfirmActionType
+
+	// Optional list of choices the user can pick from before confirming
+	choices    []string
+	cursor     int
+	applyToAll bool
 }
 
 func New(open bool, title string, content string, confirmAction ConfirmActionType) Model {
@@ -20,6 +28,19 @@ func New(open bool, title string, content string, confirmAction ConfirmActionTyp
 	}
 }
 
+// NewWithChoices creates a notify model that lets the user pick one of the choices
+// and optionally apply it to all remaining items
+func NewWithChoices(open bool, title string, content string, confirmAction ConfirmActionType,
+	choices []string) Model {
+	return Model{
+		open:          open,
+		title:         title,
+		content:       content,
+		confirmAction: confirmAction,
+		choices:       choices,
+	}
+}
+
 func (m *Model) GetTitle() string {
 	return m.title
 }
@@ -44,6 +65,36 @@ func (m *Model) GetConfirmAction() ConfirmActionType {
 	return m.confirmAction
 }
 
+func (m *Model) HasChoices() bool {
+	return len(m.choices) > 0
+}
+
+func (m *Model) GetChoice() int {
+	return m.cursor
+}
+
+func (m *Model) IsApplyToAll() bool {
+	return m.applyToAll
+}
+
+func (m *Model) ToggleApplyToAll() {
+	m.applyToAll = !m.applyToAll
+}
+
+func (m *Model) ChoiceUp() {
+	if len(m.choices) == 0 {
+		return
+	}
+	m.cursor = (m.cursor - 1 + len(m.choices)) % len(m.choices)
+}
+
+func (m *Model) ChoiceDown() {
+	if len(m.choices) == 0 {
+		return
+	}
+	m.cursor = (m.cursor + 1) % len(m.choices)
+}
+
 // TODO: Remove code duplication with typineModalRender
 func (m *Model) Render() string {
 	var inputKeysText string
@@ -52,6 +103,32 @@ func (m *Model) Render() string {
 	} else {
 		inputKeysText = common.ModalConfirmInputText + common.ModalInputSpacingText + common.ModalCancelInputText
 	}
+	if m.HasChoices() {
+		return common.ModalBorderStyle(common.ModalHeight+len(m.choices)+2, common.ModalWidth).
+			Render(m.title + "\n\n" + m.content + "\n\n" + m.renderChoices() + "\n\n" + inputKeysText)
+	}
 	return common.ModalBorderStyle(common.ModalHeight, common.ModalWidth).
 		Render(m.title + "\n\n" + m.content + "\n\n" + inputKeysText)
 }
+
+func (m *Model) renderChoices() string {
+	// Pad all choices to the same width so that they stay aligned in the centered modal
+	maxLen := 0
+	for _, choice := range m.choices {
+		maxLen = max(maxLen, len(choice))
+	}
+	r := ""
+	for i, choice := range m.choices {
+		cursor := " "
+		if i == m.cursor {
+			cursor = common.FilePanelCursorStyle.Render(icon.Cursor)
+		}
+		r += cursor + common.ModalStyle.Render(fmt.Sprintf(" %-*s", maxLen, choice)) + "\n"
+	}
+	checkbox := common.CheckboxEmpty
+	if m.applyToAll {
+		checkbox = common.CheckboxChecked
+	}
+	return r + "\n" + checkbox + common.ModalStyle.Render(applyToAllText+
+		" ("+common.Hotkeys.FilePanelSelectAllItem[0]+")")
+}
@@ -8,4 +8,9 @@ const (
 	QuitAction
 	NoAction
 	PermanentDeleteAction
+	PasteConflictAction
+	DeleteTrashItemAction
+	EmptyTrashAction
 )
+
+const applyToAllText = "Apply to all remaining conflicts"
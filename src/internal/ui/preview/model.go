@@ -7,7 +7,6 @@ import (
 	"image"
 	"io/fs"
 	"log/slog"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
@@ -18,6 +17,7 @@ import (
 	"github.com/yorukot/superfile/src/internal/ui"
 	"github.com/yorukot/superfile/src/internal/ui/rendering"
 
+	"github.com/yorukot/superfile/src/internal/archivefs"
 	"github.com/yorukot/superfile/src/internal/common"
 	"github.com/yorukot/superfile/src/internal/utils"
 
@@ -127,7 +127,7 @@ func renderUnsupportedFileMode(r *rendering.Renderer) string {
 }
 
 func renderDirectoryPreview(r *rendering.Renderer, itemPath string, previewHeight int) string {
-	files, err := os.ReadDir(itemPath)
+	files, err := archivefs.ReadDir(itemPath)
 	if err != nil {
 		slog.Error("Error render directory preview", "error", err)
 		r.AddLines(common.FilePreviewDirectoryUnreadableText)
@@ -248,7 +248,7 @@ func (m *Model) RenderWithPath(itemPath string, fullModelWidth int) string {
 	r := ui.FilePreviewPanelRenderer(previewHeight, previewWidth)
 	clearCmd := m.imagePreviewer.ClearKittyImages()
 
-	fileInfo, infoErr := os.Stat(itemPath)
+	fileInfo, infoErr := archivefs.Stat(itemPath)
 	if infoErr != nil {
 		return renderFileInfoError(r, infoErr) + clearCmd
 	}
@@ -262,7 +262,9 @@ func (m *Model) RenderWithPath(itemPath string, fullModelWidth int) string {
 	}
 
 	ext := filepath.Ext(itemPath)
-	if slices.Contains(common.UnsupportedPreviewFormats, ext) {
+	// Only the directories inside archives are previewed, files would have to be extracted first
+	if slices.Contains(common.UnsupportedPreviewFormats, ext) ||
+		(!fileInfo.IsDir() && archivefs.IsArchivePath(itemPath)) {
 		return renderUnsupportedFormat(box) + clearCmd
 	}
 
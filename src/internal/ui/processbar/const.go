@@ -1,5 +1,7 @@
 package processbar
 
+import "time"
+
 const (
 	// Min width and height for borders
 	minHeight = 2
@@ -8,4 +10,30 @@ const (
 	// This should allow smooth tracking of 5-10 active processes
 	// In case we have issues in future, we could attempt to change this
 	msgChannelSize = 50
+
+	// Byte progress is updated on every write. Sending each of them would flood the
+	// channel, so they are sent at most this often
+	updateThrottleInterval = 100 * time.Millisecond
+
+	// The rate is sampled at this interval, and smoothed with an exponential moving average
+	rateSampleInterval = time.Second
+	rateSmoothing      = 0.3
+
+	detailsWidth  = 70
+	detailsHeight = 20
+	// Lines of the details view other than the details. Borders(2), the divider and the hints
+	detailsNonContentLines = 4
+
+	// Older entries are dropped once the process history grows beyond this
+	maxHistoryEntries = 1000
+	// Entries the history file can have over maxHistoryEntries, before it is rewritten
+	// without the old ones
+	historyTrimSlack = 200
+
+	historyMinWidth  = 40
+	historyMinHeight = 14
+	// Lines of the details of the selected entry, below the list
+	historyDetailsLines = 7
+	// Lines other than the entries. Borders(2), the divider and the details
+	historyNonEntryLines = 3 + historyDetailsLines
 )
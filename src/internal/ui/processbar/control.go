@@ -0,0 +1,61 @@
+package processbar
+
+import "sync"
+
+// control is shared by all copies of a Process. The UI uses it to request the
+// goroutine performing the process to pause, resume or cancel, and the goroutine
+// picks up these requests via checkpoint() between units of work.
+type control struct {
+	mu        sync.Mutex
+	resumed   *sync.Cond
+	paused    bool
+	cancelled bool
+}
+
+func newControl() *control {
+	c := &control{}
+	c.resumed = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *control) cancel() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cancelled = true
+	// Wake up the goroutine if its paused, so that it can see the cancellation
+	c.resumed.Broadcast()
+}
+
+func (c *control) setPaused(paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = paused
+	if !paused {
+		c.resumed.Broadcast()
+	}
+}
+
+func (c *control) isPaused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+func (c *control) isCancelled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cancelled
+}
+
+// Blocks while the process is paused. Returns error if the process is cancelled
+func (c *control) checkpoint() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.paused && !c.cancelled {
+		c.resumed.Wait()
+	}
+	if c.cancelled {
+		return &ProcessCancelledError{}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package processbar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessControl(t *testing.T) {
+	t.Run("Nil and zero value processes are never paused or cancelled", func(t *testing.T) {
+		var nilProcess *Process
+		require.NoError(t, nilProcess.Checkpoint())
+
+		p := Process{}
+		p.Cancel()
+		p.SetPaused(true)
+		assert.False(t, p.IsPaused())
+		assert.False(t, p.IsCancelRequested())
+		require.NoError(t, p.Checkpoint())
+	})
+
+	t.Run("Control is shared among copies", func(t *testing.T) {
+		p := NewProcess("1", "test", 10)
+		pCopy := p
+		pCopy.Cancel()
+		assert.True(t, p.IsCancelRequested())
+		require.Error(t, p.Checkpoint())
+	})
+
+	t.Run("Checkpoint blocks while paused", func(t *testing.T) {
+		p := NewProcess("1", "test", 10)
+		p.SetPaused(true)
+		assert.True(t, p.IsPaused())
+
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- p.Checkpoint()
+		}()
+		select {
+		case <-errChan:
+			require.Fail(t, "Checkpoint should block while paused")
+		case <-time.After(50 * time.Millisecond):
+		}
+		p.SetPaused(false)
+		require.NoError(t, <-errChan)
+	})
+}
+
+func TestModelProcessControl(t *testing.T) {
+	m := New()
+	p1 := NewProcess("1", "test", 10)
+	p2 := NewProcess("2", "test2", 10)
+	p2.State = Successful
+	require.NoError(t, m.AddProcess(p1))
+	require.NoError(t, m.AddProcess(p2))
+
+	// Running process is first in sorted order
+	m.TogglePauseSelectedProcess()
+	assert.True(t, p1.IsPaused())
+	m.TogglePauseSelectedProcess()
+	assert.False(t, p1.IsPaused())
+	m.CancelSelectedProcess()
+	assert.True(t, p1.IsCancelRequested())
+
+	// Finished processes are not affected
+	m.ListDown(m.height)
+	m.CancelSelectedProcess()
+	assert.False(t, p2.IsCancelRequested())
+}
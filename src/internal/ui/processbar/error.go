@@ -22,3 +22,10 @@ type ProcessAlreadyExistsError struct {
 func (p *ProcessAlreadyExistsError) Error() string {
 	return "process already exists with id : " + p.id
 }
+
+type ProcessCancelledError struct {
+}
+
+func (p *ProcessCancelledError) Error() string {
+	return "process cancelled by user"
+}
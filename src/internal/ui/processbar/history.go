@@ -0,0 +1,196 @@
+package processbar
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// HistoryEntry is a finished process, as recorded in the process history
+type HistoryEntry struct {
+	Name      string       `json:"name"`
+	Operation string       `json:"operation,omitempty"`
+	Sources   []string     `json:"sources,omitempty"`
+	Dest      string       `json:"dest,omitempty"`
+	State     ProcessState `json:"state"`
+	// Errors and warnings of the process, the first error being the reason it failed
+	Errors     []string  `json:"errors,omitempty"`
+	Warnings   []string  `json:"warnings,omitempty"`
+	StartTime  time.Time `json:"start_time"`
+	DoneTime   time.Time `json:"done_time"`
+	DurationMs int64     `json:"duration_ms"`
+	Files      int       `json:"files"`
+	TotalFiles int       `json:"total_files"`
+	Bytes      int64     `json:"bytes"`
+}
+
+func newHistoryEntry(p Process) HistoryEntry {
+	return HistoryEntry{
+		Name:       p.Name,
+		Operation:  p.Operation,
+		Sources:    p.Sources,
+		Dest:       p.Dest,
+		State:      p.State,
+		Errors:     p.Errors,
+		Warnings:   p.Warnings,
+		StartTime:  p.StartTime,
+		DoneTime:   p.DoneTime,
+		DurationMs: p.DoneTime.Sub(p.StartTime).Milliseconds(),
+		Files:      p.Done,
+		TotalFiles: p.Total,
+		Bytes:      p.DoneBytes,
+	}
+}
+
+func (e HistoryEntry) Duration() time.Duration {
+	return time.Duration(e.DurationMs) * time.Millisecond
+}
+
+// History is the persistent log of finished processes, so that they can be looked at
+// after superfile is restarted. Entries are appended to the file, one JSON object per
+// line, and only the most recent ones are kept.
+// All methods are safe for concurrent use, and are no-op on a nil History.
+type History struct {
+	mu sync.Mutex
+	// Not persisted if empty
+	filePath string
+	loaded   bool
+	entries  []HistoryEntry
+	// Lines in the file, including the entries that were dropped from memory
+	fileLines int
+}
+
+func NewHistory(filePath string) *History {
+	return &History{
+		filePath: filePath,
+	}
+}
+
+// Record adds a finished process to the history
+func (h *History) Record(e HistoryEntry) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.load()
+
+	h.entries = append(h.entries, e)
+	if len(h.entries) > maxHistoryEntries {
+		h.entries = h.entries[len(h.entries)-maxHistoryEntries:]
+	}
+	if h.filePath == "" {
+		return
+	}
+	var err error
+	// The file is only rewritten once in a while, to drop the old entries
+	if h.fileLines >= maxHistoryEntries+historyTrimSlack {
+		err = h.rewrite()
+	} else {
+		err = h.appendEntry(e)
+	}
+	if err != nil {
+		slog.Error("Error writing process history", "error", err)
+	}
+}
+
+// Entries returns the recorded processes, the most recent first
+func (h *History) Entries() []HistoryEntry {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.load()
+
+	entries := slices.Clone(h.entries)
+	slices.Reverse(entries)
+	return entries
+}
+
+// Must be called with lock held
+func (h *History) load() {
+	if h.loaded {
+		return
+	}
+	h.loaded = true
+	if h.filePath == "" {
+		return
+	}
+	data, err := os.ReadFile(h.filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		slog.Error("Error reading process history file", "error", err)
+		return
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		h.fileLines++
+		var e HistoryEntry
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			slog.Error("Skipping invalid process history entry", "error", err)
+			continue
+		}
+		h.entries = append(h.entries, e)
+	}
+	if len(h.entries) > maxHistoryEntries {
+		h.entries = h.entries[len(h.entries)-maxHistoryEntries:]
+	}
+}
+
+// Must be called with lock held
+func (h *History) appendEntry(e HistoryEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("error encoding process history entry : %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(h.filePath), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.filePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	h.fileLines++
+	return f.Close()
+}
+
+// Must be called with lock held
+func (h *History) rewrite() error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, e := range h.entries {
+		if err := encoder.Encode(e); err != nil {
+			return fmt.Errorf("error encoding process history entry : %w", err)
+		}
+	}
+	// Write to a temporary file first, so that a crash does not leave a truncated history
+	tmpFile := h.filePath + ".tmp"
+	if err := os.WriteFile(tmpFile, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpFile, h.filePath); err != nil {
+		return err
+	}
+	h.fileLines = len(h.entries)
+	return nil
+}
@@ -0,0 +1,183 @@
+package processbar
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func finishProcess(t *testing.T, m *Model, id string, state ProcessState, doneTime time.Time) {
+	t.Helper()
+	p, ok := m.GetByID(id)
+	require.True(t, ok)
+	p.State = state
+	p.DoneTime = doneTime
+	require.NoError(t, m.UpdateExistingProcess(p))
+}
+
+func TestHistory(t *testing.T) {
+	t.Run("Entries are persisted", func(t *testing.T) {
+		historyFile := filepath.Join(t.TempDir(), "state", "history.jsonl")
+		h := NewHistory(historyFile)
+		h.Record(HistoryEntry{Name: "first", State: Successful, Sources: []string{"/a"}, Dest: "/b"})
+		h.Record(HistoryEntry{Name: "second", State: Failed, Errors: []string{"no space left"}})
+
+		// Invalid lines are skipped
+		f, err := os.OpenFile(historyFile, os.O_WRONLY|os.O_APPEND, 0o644)
+		require.NoError(t, err)
+		_, err = f.WriteString("{invalid\n")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		entries := NewHistory(historyFile).Entries()
+		require.Len(t, entries, 2)
+		assert.Equal(t, "second", entries[0].Name)
+		assert.Equal(t, Failed, entries[0].State)
+		assert.Equal(t, []string{"no space left"}, entries[0].Errors)
+		assert.Equal(t, HistoryEntry{Name: "first", State: Successful, Sources: []string{"/a"}, Dest: "/b"},
+			entries[1])
+
+		data, err := os.ReadFile(historyFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"state":"Failed"`)
+	})
+
+	t.Run("Old entries are dropped", func(t *testing.T) {
+		historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+		h := NewHistory(historyFile)
+		total := maxHistoryEntries + historyTrimSlack + 1
+		for i := range total {
+			h.Record(HistoryEntry{Name: fmt.Sprintf("p%d", i)})
+		}
+		entries := h.Entries()
+		require.Len(t, entries, maxHistoryEntries)
+		assert.Equal(t, fmt.Sprintf("p%d", total-1), entries[0].Name)
+
+		data, err := os.ReadFile(historyFile)
+		require.NoError(t, err)
+		assert.Equal(t, maxHistoryEntries, strings.Count(string(data), "\n"))
+		assert.Len(t, NewHistory(historyFile).Entries(), maxHistoryEntries)
+	})
+
+	t.Run("Nil history", func(t *testing.T) {
+		var h *History
+		h.Record(HistoryEntry{Name: "ignored"})
+		assert.Empty(t, h.Entries())
+	})
+}
+
+func TestFinishedProcesses(t *testing.T) {
+	t.Run("Finished processes are recorded once", func(t *testing.T) {
+		m := New()
+		m.SetHistory(NewHistory(""))
+		p := NewProcess("1", "copy", 2)
+		p.Sources, p.Dest = []string{"/src/a", "/src/b"}, "/dest"
+		require.NoError(t, m.AddProcess(p))
+		assert.Empty(t, m.history.Entries())
+
+		p.Done = 1
+		p.SetFailed(errors.New("disk full"))
+		p.DoneTime = p.StartTime.Add(1500 * time.Millisecond)
+		require.NoError(t, m.UpdateExistingProcess(p))
+		require.NoError(t, m.UpdateExistingProcess(p))
+
+		entries := m.history.Entries()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "copy", entries[0].Name)
+		assert.Equal(t, []string{"/src/a", "/src/b"}, entries[0].Sources)
+		assert.Equal(t, "/dest", entries[0].Dest)
+		assert.Equal(t, Failed, entries[0].State)
+		assert.Equal(t, []string{"disk full"}, entries[0].Errors)
+		assert.Equal(t, 1500*time.Millisecond, entries[0].Duration())
+		assert.Equal(t, 1, entries[0].Files)
+	})
+
+	t.Run("Cancelling is not an error", func(t *testing.T) {
+		p := NewProcess("1", "copy", 2)
+		p.SetFailed(&ProcessCancelledError{})
+		assert.Equal(t, Cancelled, p.State)
+		assert.Empty(t, p.Errors)
+	})
+
+	t.Run("Only the most recent finished processes are kept", func(t *testing.T) {
+		m := New()
+		m.SetDimensions(20, 14)
+		m.maxFinished = 2
+		now := time.Now()
+		for i := range 4 {
+			require.NoError(t, m.AddProcess(NewProcess(fmt.Sprint(i), "copy", 1)))
+		}
+		m.cursor = 3
+		for i := range 3 {
+			finishProcess(t, &m, fmt.Sprint(i), Successful, now.Add(time.Duration(i)*time.Second))
+		}
+		assert.Equal(t, 3, m.cntProcesses())
+		_, ok := m.GetByID("0")
+		assert.False(t, ok)
+		_, ok = m.GetByID("3")
+		assert.True(t, ok, "Running processes are kept")
+		assert.Equal(t, 2, m.cursor)
+		assert.True(t, m.isValid())
+	})
+
+	t.Run("Finished processes expire", func(t *testing.T) {
+		m := New()
+		m.finishedTTL = time.Minute
+		now := time.Now()
+		for i := range 3 {
+			require.NoError(t, m.AddProcess(NewProcess(fmt.Sprint(i), "copy", 1)))
+		}
+		finishProcess(t, &m, "0", Successful, now.Add(-2*time.Minute))
+		finishProcess(t, &m, "1", Failed, now)
+		_, ok := m.GetByID("0")
+		assert.False(t, ok)
+
+		m.cursor = 1
+		m.OpenSelectedProcessDetails()
+		m.removeExpiredProcesses(now.Add(2 * time.Minute))
+		_, ok = m.GetByID("1")
+		assert.True(t, ok, "The process whose details are open is kept")
+		m.CloseProcessDetails()
+		m.removeExpiredProcesses(now.Add(2 * time.Minute))
+		assert.Equal(t, 1, m.cntProcesses())
+		assert.Equal(t, 0, m.cursor)
+	})
+}
+
+func TestProcessHistoryView(t *testing.T) {
+	m := New()
+	m.SetHistory(NewHistory(""))
+	m.OpenProcessHistory()
+	assert.True(t, m.IsProcessHistoryOpen())
+	assert.Contains(t, m.RenderProcessHistory(), "No finished processes yet")
+	m.CloseProcessHistory()
+
+	for i := range 12 {
+		m.history.Record(HistoryEntry{Name: fmt.Sprintf("move %d", i), State: Successful,
+			Sources: []string{"/src"}, Dest: fmt.Sprintf("/dest%d", i)})
+	}
+	m.OpenProcessHistory()
+	out := m.RenderProcessHistory()
+	assert.Contains(t, out, "move 11")
+	assert.Contains(t, out, "/dest11")
+	assert.NotContains(t, out, "move 0")
+
+	m.ProcessHistoryListUp()
+	assert.Equal(t, 11, m.historyView.cursor)
+	out = m.RenderProcessHistory()
+	assert.Contains(t, out, "move 0")
+	assert.Contains(t, out, "/dest0")
+	m.ProcessHistoryListDown()
+	assert.Equal(t, 0, m.historyView.cursor)
+	assert.Equal(t, 0, m.historyView.renderIndex)
+
+	m.CloseProcessHistory()
+	assert.False(t, m.IsProcessHistoryOpen())
+}
@@ -2,9 +2,15 @@ package processbar
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
+	"slices"
+	"time"
 
+	variable "github.com/yorukot/superfile/src/config"
+	"github.com/yorukot/superfile/src/config/icon"
 	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/desktopnotify"
 	"github.com/yorukot/superfile/src/internal/ui"
 )
 
@@ -17,18 +23,49 @@ type Model struct {
 	height int
 	width  int
 
-	// TODO: Fix this. No mechanism to remove completed processes from memory
-	// processes map grows indefinitely
-	// Maybe, TTL or cleanup mechanism for successful/failed processes
+	// Finished processes are removed by removeExpiredProcesses()
 	processes map[string]Process
 	msgChan   chan UpdateMsg
 	reqCnt    int
+
+	// Finished processes are removed once they finished longer than finishedTTL ago, or
+	// when there are more than maxFinished of them. Zero disables the limit
+	finishedTTL time.Duration
+	maxFinished int
+	// Finished processes are recorded here, even after they are removed
+	history *History
+	// Shared by all copies of the model, as the processes wait for their turn in it
+	queue *Queue
+	// Tells the user when a process that ran for at least notifyAfter finishes. Nil disables it
+	notifier    desktopnotify.Notifier
+	notifyAfter time.Duration
+
+	// ID of the process whose details are shown. Empty if the details view is closed
+	detailsID string
+	// First line of the details shown, when they do not fit in the view
+	detailsScroll int
+
+	historyView historyView
 }
 
 func New() Model {
 	return NewModelWithOptions(minWidth, minHeight)
 }
 
+// DefaultModel records the finished processes in the history file of superfile, and
+// removes them from the process bar as set in the config. Notifications sent through the
+// terminal are written to notifyOut
+func DefaultModel(notifyOut io.Writer) Model {
+	m := New()
+	m.history = NewHistory(variable.ProcessHistoryFile)
+	m.finishedTTL = time.Duration(common.Config.FinishedProcessTTL) * time.Minute
+	m.maxFinished = common.Config.MaxFinishedProcesses
+	m.queue = NewQueue(common.Config.MaxProcessesPerDevice)
+	m.notifier = newNotifier(notifyOut)
+	m.notifyAfter = time.Duration(common.Config.NotificationThreshold) * time.Second
+	return m
+}
+
 // Note: We should considering our internal models, they
 // should be returning pointer object, and implement tea.Model
 func NewModelWithOptions(width int, height int) Model {
@@ -38,8 +75,10 @@ func NewModelWithOptions(width int, height int) Model {
 		processes:   make(map[string]Process),
 		msgChan:     make(chan UpdateMsg, msgChannelSize),
 		reqCnt:      0,
+		queue:       NewQueue(0),
 	}
 	m.SetDimensions(width, height)
+	m.SetProcessHistoryDimensions(historyMinWidth, historyMinHeight)
 	return m
 }
 
@@ -56,11 +95,27 @@ func (m *Model) SetDimensions(width int, height int) {
 	m.height = height
 }
 
+func (m *Model) SetHistory(history *History) {
+	m.history = history
+}
+
+// SetNotifier sets the notifier used when a process that ran for at least notifyAfter finishes
+func (m *Model) SetNotifier(notifier desktopnotify.Notifier, notifyAfter time.Duration) {
+	m.notifier = notifier
+	m.notifyAfter = notifyAfter
+}
+
+// SetQueue sets the queue that limits the processes running at once on each device
+func (m *Model) SetQueue(queue *Queue) {
+	m.queue = queue
+}
+
 func (m *Model) AddProcess(p Process) error {
 	if _, ok := m.processes[p.ID]; ok {
 		return &ProcessAlreadyExistsError{id: p.ID}
 	}
 	m.processes[p.ID] = p
+	m.removeExpiredProcesses(time.Now())
 	return nil
 }
 
@@ -69,13 +124,51 @@ func (m *Model) AddOrUpdateProcess(p Process) {
 }
 
 func (m *Model) UpdateExistingProcess(p Process) error {
-	if _, ok := m.processes[p.ID]; !ok {
+	old, ok := m.processes[p.ID]
+	if !ok {
 		return &NoProcessFoundError{id: p.ID}
 	}
 	m.processes[p.ID] = p
+	if !old.State.isFinished() && p.State.isFinished() {
+		m.history.Record(newHistoryEntry(p))
+		m.notifyFinished(p)
+		m.removeExpiredProcesses(time.Now())
+	}
 	return nil
 }
 
+// RemoveExpiredProcesses removes the finished processes that are past the limits. Processes
+// that expire while no update is received are only removed on this call
+func (m *Model) RemoveExpiredProcesses() {
+	m.removeExpiredProcesses(time.Now())
+}
+
+func (m *Model) removeExpiredProcesses(now time.Time) {
+	var finished []Process
+	for id, p := range m.processes {
+		// Dont remove the process whose details are open
+		if !p.State.isFinished() || id == m.detailsID {
+			continue
+		}
+		if m.finishedTTL > 0 && now.Sub(p.DoneTime) > m.finishedTTL {
+			delete(m.processes, id)
+			continue
+		}
+		finished = append(finished, p)
+	}
+	if m.maxFinished > 0 && len(finished) > m.maxFinished {
+		slices.SortFunc(finished, func(a, b Process) int {
+			return b.DoneTime.Compare(a.DoneTime)
+		})
+		for _, p := range finished[m.maxFinished:] {
+			delete(m.processes, p.ID)
+		}
+	}
+	// Removed processes are at the end of the list, as they are the oldest finished ones
+	m.cursor = max(min(m.cursor, m.cntProcesses()-1), 0)
+	m.renderIndex = min(m.renderIndex, m.cursor)
+}
+
 func (m *Model) GetByID(id string) (Process, bool) {
 	p, ok := m.processes[id]
 	return p, ok
@@ -83,13 +176,83 @@ func (m *Model) GetByID(id string) (Process, bool) {
 
 func (m *Model) HasRunningProcesses() bool {
 	for _, data := range m.processes {
-		if data.State == InOperation && data.Done != data.Total {
+		if data.State == Queued || (data.State == InOperation && data.Done != data.Total) {
 			return true
 		}
 	}
 	return false
 }
 
+// Returns the process under the cursor
+func (m *Model) getSelectedProcess() (Process, bool) {
+	processes := m.getSortedProcesses()
+	if m.cursor < 0 || m.cursor >= len(processes) {
+		return Process{}, false
+	}
+	return processes[m.cursor], true
+}
+
+// Request cancellation of the process under the cursor, if its still running or queued
+func (m *Model) CancelSelectedProcess() {
+	p, ok := m.getSelectedProcess()
+	if !ok || p.State.isFinished() {
+		return
+	}
+	slog.Debug("Cancelling process", "id", p.ID, "name", p.Name)
+	p.Cancel()
+	// Has to be after Cancel(), so that the process sees the cancellation once it leaves the queue
+	m.queue.cancel(p.ID)
+}
+
+// Pause the process under the cursor if its running, or resume it if its paused
+func (m *Model) TogglePauseSelectedProcess() {
+	p, ok := m.getSelectedProcess()
+	if !ok || p.State != InOperation {
+		return
+	}
+	slog.Debug("Toggling pause of process", "id", p.ID, "name", p.Name, "paused", !p.IsPaused())
+	p.SetPaused(!p.IsPaused())
+}
+
+// MoveSelectedProcessInQueue moves the queued process under the cursor by delta places
+// in the queue, towards the front if delta is negative. The cursor follows the process
+func (m *Model) MoveSelectedProcessInQueue(delta int) {
+	p, ok := m.getSelectedProcess()
+	if !ok || !m.queue.move(p.ID, delta) {
+		return
+	}
+	slog.Debug("Moved process in queue", "id", p.ID, "delta", delta)
+	m.setCursorToProcess(p.ID)
+}
+
+// PrioritizeSelectedProcess moves the queued process under the cursor to the front of the
+// queue, so that it is the next to start on its device
+func (m *Model) PrioritizeSelectedProcess() {
+	p, ok := m.getSelectedProcess()
+	if !ok || !m.queue.prioritize(p.ID) {
+		return
+	}
+	slog.Debug("Prioritized process", "id", p.ID)
+	m.setCursorToProcess(p.ID)
+}
+
+// Move the cursor to the process, and scroll to keep it visible
+func (m *Model) setCursorToProcess(id string) {
+	i := slices.IndexFunc(m.getSortedProcesses(), func(p Process) bool {
+		return p.ID == id
+	})
+	if i < 0 {
+		return
+	}
+	m.cursor = i
+	renderable := cntRenderableProcess(m.viewHeight())
+	if m.cursor < m.renderIndex {
+		m.renderIndex = m.cursor
+	} else if m.cursor > m.renderIndex+renderable-1 {
+		m.renderIndex = m.cursor - renderable + 1
+	}
+}
+
 func (m *Model) Render(processBarFocussed bool) string {
 	r := ui.ProcessBarRenderer(m.height, m.width, processBarFocussed)
 	if !m.isValid() {
@@ -130,16 +293,29 @@ func (m *Model) Render(processBarFocussed bool) string {
 			cursor = common.FooterCursorStyle.Render("  ")
 		}
 
+		stateIcon := curProcess.State.Icon()
+		if curProcess.State == InOperation && curProcess.IsPaused() {
+			stateIcon = common.ProcessCancelStyle.Render(icon.Pause)
+		}
 		r.AddLines(cursor + common.FooterStyle.Render(
 			common.TruncateText(curProcess.Name, m.viewWidth()-7, "...")+" ") +
-			curProcess.State.Icon())
+			stateIcon)
+		if curProcess.State == Queued {
+			position := fmt.Sprintf("Queued #%d", m.queue.Position(curProcess.ID))
+			r.AddLines(cursor+curProcess.Progress.ViewAs(0),
+				cursor+common.FooterStyle.Render(common.TruncateText(position, m.viewWidth()-2, "...")))
+			continue
+		}
 
 		// calculate progress percentage
 		// if the total is 0, that means the process only have directory
 		// so we can set the progress to 100%
 		if curProcess.Total != 0 {
 			progressPercentage := float64(curProcess.Done) / float64(curProcess.Total)
-			r.AddLines(cursor+curProcess.Progress.ViewAs(progressPercentage), "")
+			if curProcess.TotalBytes != 0 {
+				progressPercentage = float64(curProcess.DoneBytes) / float64(curProcess.TotalBytes)
+			}
+			r.AddLines(cursor+curProcess.Progress.ViewAs(progressPercentage), m.renderBytesProgress(cursor, curProcess))
 		} else {
 			r.AddLines(cursor + curProcess.Progress.ViewAs(1))
 		}
@@ -0,0 +1,184 @@
+package processbar
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui"
+)
+
+// Open the details view for the process under the cursor
+func (m *Model) OpenSelectedProcessDetails() {
+	p, ok := m.getSelectedProcess()
+	if !ok {
+		return
+	}
+	slog.Debug("Opening process details", "id", p.ID)
+	m.detailsID = p.ID
+	m.detailsScroll = 0
+}
+
+func (m *Model) CloseProcessDetails() {
+	m.detailsID = ""
+}
+
+func (m *Model) IsProcessDetailsOpen() bool {
+	return m.detailsID != ""
+}
+
+// GetProcessInDetails returns the process whose details are shown
+func (m *Model) GetProcessInDetails() (Process, bool) {
+	p, ok := m.processes[m.detailsID]
+	return p, ok
+}
+
+func (m *Model) ProcessDetailsScrollUp() {
+	if m.detailsScroll > 0 {
+		m.detailsScroll--
+	}
+}
+
+func (m *Model) ProcessDetailsScrollDown() {
+	p, ok := m.processes[m.detailsID]
+	if !ok {
+		return
+	}
+	if m.detailsScroll < maxDetailsScroll(p) {
+		m.detailsScroll++
+	}
+}
+
+func maxDetailsScroll(p Process) int {
+	return max(len(processDetailsLines(p))-(detailsHeight-detailsNonContentLines), 0)
+}
+
+// RenderProcessDetails renders the details view. It is re-rendered with each update
+// of the process, so the progress stays live while it is open
+func (m *Model) RenderProcessDetails() string {
+	r := ui.ProcessDetailsRenderer(detailsHeight, detailsWidth)
+	r.SetBorderTitle("Process details")
+	p, ok := m.processes[m.detailsID]
+	if !ok {
+		r.AddLines(" Process not found")
+		return r.Render()
+	}
+
+	lines := processDetailsLines(p)
+	visibleCnt := detailsHeight - detailsNonContentLines
+	// The details can get shorter while the view is open, as the process progresses
+	m.detailsScroll = min(m.detailsScroll, maxDetailsScroll(p))
+	end := min(m.detailsScroll+visibleCnt, len(lines))
+	if len(lines) > visibleCnt {
+		r.SetBorderInfoItems(fmt.Sprintf("%d-%d/%d", m.detailsScroll+1, end, len(lines)))
+	}
+	for _, line := range lines[m.detailsScroll:end] {
+		r.AddLines(common.TruncateText(line, detailsWidth-2, "..."))
+	}
+	// Keep the hints at the bottom
+	for i := end - m.detailsScroll; i < visibleCnt; i++ {
+		r.AddLines("")
+	}
+	r.AddSection()
+	hints := " " + common.Hotkeys.ListUp[0] + "/" + common.Hotkeys.ListDown[0] + ": scroll"
+	if p.CanRetry() {
+		hints += "  " + common.Hotkeys.RetryProcess[0] + ": retry failed items"
+	}
+	r.AddLines(hints + "  " + common.Hotkeys.Confirm[0] + ": close")
+	return r.Render()
+}
+
+func processDetailsLines(p Process) []string {
+	state := p.State.String()
+	if p.State == InOperation && p.IsPaused() {
+		state = "Paused"
+	} else if p.State == InOperation && p.Verifying {
+		state = "Verifying"
+	}
+	lines := []string{" " + p.Name, ""}
+	if p.Operation != "" {
+		lines = append(lines, detailsLine("Operation", p.Operation))
+	}
+	lines = append(lines,
+		detailsLine("State", state),
+		detailsLine("Files", fmt.Sprintf("%d/%d", p.Done, p.Total)),
+	)
+	if p.TotalBytes != 0 {
+		lines = append(lines,
+			detailsLine("Size", common.FormatFileSize(p.DoneBytes)+"/"+common.FormatFileSize(p.TotalBytes)))
+	}
+	if p.State == InOperation && p.TotalBytes != 0 {
+		eta := "Calculating..."
+		if d, ok := p.ETA(); ok {
+			eta = formatDuration(d)
+		}
+		lines = append(lines, detailsLine("Speed", formatRate(p.Rate)), detailsLine("ETA", eta))
+	}
+	lines = append(lines, detailsLine("Started", p.StartTime.Format(time.DateTime)))
+	if p.State.isFinished() {
+		lines = append(lines, detailsLine("Finished", p.DoneTime.Format(time.DateTime)),
+			detailsLine("Duration", formatDuration(p.DoneTime.Sub(p.StartTime))))
+	} else {
+		lines = append(lines, detailsLine("Elapsed", formatDuration(time.Since(p.StartTime))))
+	}
+	if p.Dest != "" {
+		lines = append(lines, detailsLine("To", p.Dest))
+	}
+
+	if len(p.Errors) > 0 {
+		lines = append(lines, "", fmt.Sprintf(" Errors (%d)", len(p.Errors)))
+		for _, err := range p.Errors {
+			lines = append(lines, wrapDetails(err)...)
+		}
+	}
+	if len(p.Warnings) > 0 {
+		lines = append(lines, "", fmt.Sprintf(" Warnings (%d)", len(p.Warnings)))
+		for _, warning := range p.Warnings {
+			lines = append(lines, wrapDetails(warning)...)
+		}
+	}
+	return append(lines, sourceLines(p)...)
+}
+
+// Lists the sources of the process. Those of the processes that can be retried are
+// marked as done or pending
+func sourceLines(p Process) []string {
+	if len(p.Sources) == 0 {
+		return nil
+	}
+	if !p.Retryable {
+		lines := []string{"", fmt.Sprintf(" From (%d)", len(p.Sources))}
+		for _, src := range p.Sources {
+			lines = append(lines, "  "+src)
+		}
+		return lines
+	}
+	pending := p.PendingSources()
+	lines := []string{"", fmt.Sprintf(" From (%d done, %d pending)", len(p.Sources)-len(pending), len(pending))}
+	for _, src := range p.Sources {
+		status := "done"
+		if slices.Contains(pending, src) {
+			status = "pending"
+		}
+		lines = append(lines, fmt.Sprintf("  %-8s %s", status, src))
+	}
+	return lines
+}
+
+// Wraps long errors and warnings over several indented lines, so that they are shown in full
+func wrapDetails(text string) []string {
+	lines := strings.Split(ansi.Wrap(text, detailsWidth-5, ""), "\n")
+	for i := range lines {
+		lines[i] = "  " + lines[i]
+	}
+	return lines
+}
+
+func detailsLine(label string, value string) string {
+	return fmt.Sprintf(" %-9s %s", label, value)
+}
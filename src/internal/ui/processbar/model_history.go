@@ -0,0 +1,158 @@
+package processbar
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui"
+)
+
+// historyView lists the entries of the process history, with the details of the selected one
+type historyView struct {
+	open bool
+	// Snapshot of the history when the view was opened, the most recent first
+	entries     []HistoryEntry
+	cursor      int
+	renderIndex int
+
+	width  int
+	height int
+}
+
+// OpenProcessHistory opens the history view, with the processes finished so far
+func (m *Model) OpenProcessHistory() {
+	slog.Debug("Opening process history")
+	m.historyView.open = true
+	m.historyView.entries = m.history.Entries()
+	m.historyView.cursor = 0
+	m.historyView.renderIndex = 0
+}
+
+func (m *Model) CloseProcessHistory() {
+	m.historyView.open = false
+	m.historyView.entries = nil
+}
+
+func (m *Model) IsProcessHistoryOpen() bool {
+	return m.historyView.open
+}
+
+func (m *Model) SetProcessHistoryDimensions(width int, height int) {
+	m.historyView.width = max(width, historyMinWidth)
+	m.historyView.height = max(height, historyMinHeight)
+	m.historyView.updateRenderIndex()
+}
+
+func (m *Model) GetProcessHistoryDimensions() (int, int) {
+	return m.historyView.width, m.historyView.height
+}
+
+func (m *Model) ProcessHistoryListUp() {
+	h := &m.historyView
+	if len(h.entries) == 0 {
+		return
+	}
+	if h.cursor > 0 {
+		h.cursor--
+	} else {
+		h.cursor = len(h.entries) - 1 // Wrap to bottom
+	}
+	h.updateRenderIndex()
+}
+
+func (m *Model) ProcessHistoryListDown() {
+	h := &m.historyView
+	if len(h.entries) == 0 {
+		return
+	}
+	if h.cursor < len(h.entries)-1 {
+		h.cursor++
+	} else {
+		h.cursor = 0 // Wrap to top
+	}
+	h.updateRenderIndex()
+}
+
+func (h *historyView) visibleEntryCnt() int {
+	return h.height - historyNonEntryLines
+}
+
+func (h *historyView) updateRenderIndex() {
+	if h.cursor < h.renderIndex {
+		h.renderIndex = h.cursor
+	}
+	if h.cursor >= h.renderIndex+h.visibleEntryCnt() {
+		h.renderIndex = h.cursor - h.visibleEntryCnt() + 1
+	}
+	h.renderIndex = max(min(h.renderIndex, len(h.entries)-h.visibleEntryCnt()), 0)
+}
+
+func (m *Model) RenderProcessHistory() string {
+	h := &m.historyView
+	r := ui.ProcessHistoryRenderer(h.height, h.width)
+	r.SetBorderTitle("Process history")
+	if len(h.entries) == 0 {
+		r.AddLines(" No finished processes yet")
+		return r.Render()
+	}
+	r.SetBorderInfoItems(fmt.Sprintf("%d/%d", h.cursor+1, len(h.entries)))
+
+	end := min(h.renderIndex+h.visibleEntryCnt(), len(h.entries))
+	for i := h.renderIndex; i < end; i++ {
+		line := renderHistoryEntry(h.entries[i], h.width-2)
+		if i == h.cursor {
+			line = common.ModalCursorStyle.Render(line)
+		}
+		r.AddLines(line)
+	}
+	// Keep the details at the bottom
+	for i := end - h.renderIndex; i < h.visibleEntryCnt(); i++ {
+		r.AddLines("")
+	}
+	r.AddSection()
+	for _, line := range historyEntryDetails(h.entries[h.cursor]) {
+		r.AddLines(common.TruncateText(line, h.width-2, "..."))
+	}
+	return r.Render()
+}
+
+// Renders the finish time, the state and the name of the entry in a line of given width
+func renderHistoryEntry(e HistoryEntry, width int) string {
+	line := fmt.Sprintf(" %s  %-12s %s", e.DoneTime.Format(time.DateOnly+" 15:04"), e.State.String(), e.Name)
+	return common.TruncateText(line, width, "...")
+}
+
+// Always returns historyDetailsLines lines
+func historyEntryDetails(e HistoryEntry) []string {
+	size := fmt.Sprintf("%d/%d files", e.Files, e.TotalFiles)
+	if e.Bytes != 0 {
+		size = common.FormatFileSize(e.Bytes) + ", " + size
+	}
+	sources := strings.Join(e.Sources, ", ")
+	if len(e.Sources) > 1 {
+		sources = fmt.Sprintf("%s (and %d more)", e.Sources[0], len(e.Sources)-1)
+	}
+	state := e.State.String()
+	if e.Operation != "" {
+		state = e.Operation + ", " + strings.ToLower(state)
+	}
+	errMsg := ""
+	if len(e.Errors) > 0 {
+		errMsg = e.Errors[0]
+		if len(e.Errors) > 1 {
+			errMsg += fmt.Sprintf(" (and %d more)", len(e.Errors)-1)
+		}
+	}
+	return []string{
+		" " + e.Name,
+		detailsLine("State", state),
+		detailsLine("Started", e.StartTime.Format(time.DateTime)+", took "+formatDuration(e.Duration())),
+		detailsLine("Size", size),
+		detailsLine("From", sources),
+		detailsLine("To", e.Dest),
+		detailsLine("Error", errMsg),
+	}
+}
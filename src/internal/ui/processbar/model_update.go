@@ -2,6 +2,8 @@ package processbar
 
 import (
 	"log/slog"
+	"sync"
+	"time"
 )
 
 // Only used in tests, to have processbar used in a standalone way without model
@@ -86,6 +88,47 @@ func (m *Model) TrySendingUpdateProcessMsg(p Process) {
 	}
 }
 
+// TrySendingThrottledUpdateProcessMsg is for frequent updates, like byte progress.
+// It drops the update if the last one for this process was sent too recently
+func (m *Model) TrySendingThrottledUpdateProcessMsg(p *Process) {
+	now := time.Now()
+	if now.Sub(p.lastUpdateTime) < updateThrottleInterval {
+		return
+	}
+	p.lastUpdateTime = now
+	m.TrySendingUpdateProcessMsg(*p)
+}
+
+// WaitForTurn blocks until p can run on the device, as per the limit of processes running
+// at once on each device. p is shown as queued while it waits. If p is cancelled while
+// queued, WaitForTurn returns right away, and the next Checkpoint() of p reports it.
+// The returned function must be called once p is done, to let the next processes start
+func (m *Model) WaitForTurn(p *Process, device string) func() {
+	start, started := m.queue.enqueue(p.ID, device)
+	if !started {
+		slog.Debug("Process queued", "id", p.ID, "device", device)
+		p.State = Queued
+		if err := m.SendUpdateProcessMsg(*p, true); err != nil {
+			slog.Error("Error sending process update", "error", err)
+		}
+		started = <-start
+		p.State = InOperation
+		if !started {
+			return func() {}
+		}
+		// The time spent in the queue does not count for the duration and the rate
+		p.StartTime = time.Now()
+		p.rateSampleTime = p.StartTime
+		if err := m.SendUpdateProcessMsg(*p, true); err != nil {
+			slog.Error("Error sending process update", "error", err)
+		}
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() { m.queue.release(device) })
+	}
+}
+
 func (m *Model) SendStopListeningMsgBlocking() {
 	m.sendMsgToChannelBlocking(stopListeningMsg{BaseMsg: BaseMsg{reqID: m.newReqCnt()}})
 }
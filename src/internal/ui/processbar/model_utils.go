@@ -2,8 +2,11 @@ package processbar
 
 import (
 	"sort"
+	"time"
 
 	"github.com/lithammer/shortuuid"
+
+	"github.com/yorukot/superfile/src/internal/common"
 )
 
 func (m *Model) cntProcesses() int {
@@ -41,14 +44,24 @@ func (m *Model) getSortedProcesses() []Process {
 	}
 	// sort by the process
 	sort.Slice(processes, func(i, j int) bool {
-		doneI := (processes[i].State == Successful || processes[i].State == Failed)
-		doneJ := (processes[j].State == Successful || processes[j].State == Failed)
+		doneI := processes[i].State.isFinished()
+		doneJ := processes[j].State.isFinished()
 
 		// sort by done or not
 		if doneI != doneJ {
 			return !doneI
 		}
 
+		// Queued processes come after the running ones, in the order of the queue
+		queuedI := processes[i].State == Queued
+		queuedJ := processes[j].State == Queued
+		if queuedI != queuedJ {
+			return !queuedI
+		}
+		if queuedI {
+			return m.queue.Position(processes[i].ID) < m.queue.Position(processes[j].ID)
+		}
+
 		// if both not done
 		if !doneI {
 			completionI := float64(processes[i].Done) / float64(processes[i].Total)
@@ -63,6 +76,32 @@ func (m *Model) getSortedProcesses() []Process {
 	return processes
 }
 
+// Size, transfer rate and ETA of the process, rendered below its progress bar
+func (m *Model) renderBytesProgress(cursor string, p Process) string {
+	if p.TotalBytes == 0 {
+		return ""
+	}
+	text := common.FormatFileSize(p.DoneBytes) + "/" + common.FormatFileSize(p.TotalBytes)
+	if p.Verifying && p.State == InOperation {
+		text = "Verifying " + text
+	}
+	if p.State == InOperation && p.Rate > 0 {
+		text += " " + formatRate(p.Rate)
+	}
+	if eta, ok := p.ETA(); ok && p.State == InOperation {
+		text += " ETA " + formatDuration(eta)
+	}
+	return cursor + common.FooterStyle.Render(common.TruncateText(text, m.viewWidth()-2, "..."))
+}
+
+func formatRate(rate float64) string {
+	return common.FormatFileSize(int64(rate)) + "/s"
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
+
 func (m *Model) newReqCnt() int {
 	m.reqCnt++
 	return m.reqCnt
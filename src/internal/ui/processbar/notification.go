@@ -0,0 +1,74 @@
+package processbar
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/desktopnotify"
+)
+
+// newNotifier returns the notifier set in the config, writing to the terminal through w.
+// Returns nil if notifications are disabled
+func newNotifier(w io.Writer) desktopnotify.Notifier {
+	if common.Config.NotificationThreshold <= 0 {
+		return nil
+	}
+	var notifiers desktopnotify.Multi
+	switch common.Config.NotificationMethod {
+	case common.NotificationAuto:
+		notifiers = append(notifiers, desktopnotify.NewTerminal(w, desktopnotify.DetectSequence()))
+	case common.NotificationOSC9:
+		notifiers = append(notifiers, desktopnotify.NewTerminal(w, desktopnotify.OSC9))
+	case common.NotificationOSC777:
+		notifiers = append(notifiers, desktopnotify.NewTerminal(w, desktopnotify.OSC777))
+	case common.NotificationBell:
+		notifiers = append(notifiers, desktopnotify.NewTerminal(w, desktopnotify.Bell))
+	}
+	if common.Config.NotificationCommand != "" {
+		notifiers = append(notifiers, desktopnotify.NewCommand(common.Config.NotificationCommand))
+	}
+	if len(notifiers) == 0 {
+		return nil
+	}
+	return notifiers
+}
+
+// notifyFinished tells the user that the process finished, if it ran for long enough that
+// they might have looked away. Cancelled processes are skipped, as the user cancelled them
+func (m *Model) notifyFinished(p Process) {
+	if m.notifier == nil || m.notifyAfter <= 0 || p.State == Cancelled ||
+		p.DoneTime.Sub(p.StartTime) < m.notifyAfter {
+		return
+	}
+	title, body := notificationMessage(p)
+	if err := m.notifier.Notify(title, body); err != nil {
+		slog.Error("Error sending notification", "id", p.ID, "error", err)
+	}
+}
+
+func notificationMessage(p Process) (string, string) {
+	operation := p.Operation
+	if operation == "" {
+		operation = "Process"
+	}
+	result := "finished"
+	if p.State == Failed {
+		result = "failed"
+	}
+	// The name starts with an icon, which notifications may not be able to show
+	subject := p.Name
+	if len(p.Sources) > 0 {
+		subject = filepath.Base(p.Sources[0])
+		if len(p.Sources) > 1 {
+			subject += fmt.Sprintf(" and %d more", len(p.Sources)-1)
+		}
+	}
+	body := subject + ", took " + formatDuration(p.DoneTime.Sub(p.StartTime))
+	if p.State == Failed && len(p.Errors) > 0 {
+		body += ". " + p.Errors[0]
+	}
+	return "superfile: " + operation + " " + result, body
+}
@@ -0,0 +1,85 @@
+package processbar
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yorukot/superfile/src/internal/common"
+)
+
+type notification struct {
+	title string
+	body  string
+}
+
+type fakeNotifier struct {
+	sent []notification
+}
+
+func (f *fakeNotifier) Notify(title string, body string) error {
+	f.sent = append(f.sent, notification{title: title, body: body})
+	return nil
+}
+
+func TestNotifyFinished(t *testing.T) {
+	n := &fakeNotifier{}
+	m := New()
+	m.SetNotifier(n, time.Minute)
+
+	finish := func(id string, state ProcessState, took time.Duration, update func(p *Process)) {
+		t.Helper()
+		p := NewProcess(id, "name", 2)
+		p.Operation = "Copy"
+		p.Sources = []string{"/src/a.txt", "/src/b.txt"}
+		require.NoError(t, m.AddProcess(p))
+		if update != nil {
+			update(&p)
+		}
+		p.State = state
+		p.DoneTime = p.StartTime.Add(took)
+		require.NoError(t, m.UpdateExistingProcess(p))
+		// Only the first update of a finished process notifies
+		require.NoError(t, m.UpdateExistingProcess(p))
+	}
+
+	finish("1", Successful, 2*time.Minute, nil)
+	require.Len(t, n.sent, 1)
+	assert.Equal(t, notification{"superfile: Copy finished", "a.txt and 1 more, took 2m0s"}, n.sent[0])
+
+	finish("2", Successful, 30*time.Second, nil)
+	finish("3", Cancelled, 2*time.Minute, nil)
+	assert.Len(t, n.sent, 1, "Short and cancelled processes do not notify")
+
+	finish("4", Failed, time.Hour, func(p *Process) {
+		p.Operation = ""
+		p.Sources = nil
+		p.SetFailed(errors.New("disk full"))
+	})
+	require.Len(t, n.sent, 2)
+	assert.Equal(t, notification{"superfile: Process failed", "name, took 1h0m0s. disk full"}, n.sent[1])
+}
+
+func TestNewNotifier(t *testing.T) {
+	oldConfig := common.Config
+	t.Cleanup(func() {
+		common.Config = oldConfig
+	})
+	common.Config.NotificationThreshold = 10
+	common.Config.NotificationMethod = common.NotificationOSC9
+	common.Config.NotificationCommand = ""
+
+	var buf bytes.Buffer
+	n := newNotifier(&buf)
+	require.NotNil(t, n)
+	require.NoError(t, n.Notify("superfile: Copy finished", "a.txt"))
+	assert.Contains(t, buf.String(), "]9;superfile: Copy finished: a.txt\a",
+		"The sequence should be written to the given writer")
+
+	common.Config.NotificationThreshold = 0
+	assert.Nil(t, newNotifier(&buf), "A threshold of 0 disables notifications")
+}
@@ -1,6 +1,8 @@
 package processbar
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
@@ -19,19 +21,185 @@ type Process struct {
 	Total    int
 	Done     int
 	DoneTime time.Time
+
+	// Byte level progress. TotalBytes is zero for processes that only track files
+	TotalBytes int64
+	DoneBytes  int64
+	StartTime  time.Time
+	// Transfer rate in bytes per second, smoothed over the recent samples
+	Rate float64
+
+	// Used by the goroutine performing the process, to sample the rate
+	// and throttle the updates
+	rateSampleTime  time.Time
+	rateSampleBytes int64
+	lastUpdateTime  time.Time
+
+	// Set while the copied files are re-read and compared with their source
+	Verifying bool
+
+	// Things that did not fail the process, but the user should know about
+	Warnings []string
+	// Things that made the process fail, along with the paths they happened on
+	Errors []string
+
+	// Kind of operation, like "Copy" or "Delete", shown in the details
+	Operation string
+	// What the process works on, and where the result goes. Recorded in the history
+	Sources []string
+	Dest    string
+	// Sources that were fully processed, for the processes that can be retried
+	Completed []string
+	// Whether the sources that were not completed can be processed again once the process failed
+	Retryable bool
+
+	// Shared across all copies of this process
+	control *control
 }
 
 func NewProcess(id string, name string, total int) Process {
 	prog := progress.New(common.GenerateGradientColor())
 	prog.PercentageStyle = common.FooterStyle
+	now := time.Now()
 	return Process{
-		ID:       id,
-		Name:     name,
-		Progress: prog,
-		State:    InOperation,
-		Total:    total,
-		Done:     0,
+		ID:             id,
+		Name:           name,
+		Progress:       prog,
+		State:          InOperation,
+		Total:          total,
+		Done:           0,
+		StartTime:      now,
+		rateSampleTime: now,
+		control:        newControl(),
+	}
+}
+
+// AddDoneBytes records that n more bytes were written
+func (p *Process) AddDoneBytes(n int64) {
+	p.SetDoneBytes(p.DoneBytes + n)
+}
+
+// SetDoneBytes is for the work that is not tracked byte by byte, like
+// moves via rename, or skipped items
+func (p *Process) SetDoneBytes(n int64) {
+	p.DoneBytes = n
+	now := time.Now()
+	elapsed := now.Sub(p.rateSampleTime)
+	if elapsed < rateSampleInterval {
+		return
+	}
+	curRate := float64(p.DoneBytes-p.rateSampleBytes) / elapsed.Seconds()
+	if p.Rate == 0 {
+		p.Rate = curRate
+	} else {
+		p.Rate = rateSmoothing*curRate + (1-rateSmoothing)*p.Rate
 	}
+	p.rateSampleTime = now
+	p.rateSampleBytes = p.DoneBytes
+}
+
+// AddWarning is safe to call on a nil Process
+func (p *Process) AddWarning(warning string) {
+	if p == nil {
+		return
+	}
+	p.Warnings = append(p.Warnings, warning)
+}
+
+// AddError is safe to call on a nil Process
+func (p *Process) AddError(err string) {
+	if p == nil {
+		return
+	}
+	p.Errors = append(p.Errors, err)
+}
+
+// SetFailed ends the process because of err. It is marked as cancelled if err is a
+// ProcessCancelledError, and as failed with err added to its errors otherwise
+func (p *Process) SetFailed(err error) {
+	var cancelledErr *ProcessCancelledError
+	if errors.As(err, &cancelledErr) {
+		p.State = Cancelled
+		return
+	}
+	p.State = Failed
+	p.AddError(err.Error())
+}
+
+// PendingSources returns the sources that were not completed
+func (p *Process) PendingSources() []string {
+	completed := make(map[string]bool, len(p.Completed))
+	for _, src := range p.Completed {
+		completed[src] = true
+	}
+	var pending []string
+	for _, src := range p.Sources {
+		if !completed[src] {
+			pending = append(pending, src)
+		}
+	}
+	return pending
+}
+
+// CanRetry reports whether the process failed, and the sources it did not complete can be retried
+func (p *Process) CanRetry() bool {
+	return p.Retryable && p.State == Failed && len(p.PendingSources()) > 0
+}
+
+// StartVerifying restarts the progress for the verifying phase, which re-reads
+// totalBytes bytes over total files
+func (p *Process) StartVerifying(total int, totalBytes int64) {
+	p.Verifying = true
+	p.Done = 0
+	p.Total = total
+	p.DoneBytes = 0
+	p.TotalBytes = totalBytes
+	p.Rate = 0
+	p.rateSampleTime = time.Now()
+	p.rateSampleBytes = 0
+}
+
+// ETA returns the estimated time left, and false if it cannot be estimated yet
+func (p *Process) ETA() (time.Duration, bool) {
+	if p.TotalBytes == 0 || p.Rate <= 0 {
+		return 0, false
+	}
+	remaining := max(p.TotalBytes-p.DoneBytes, 0)
+	return time.Duration(float64(remaining) / p.Rate * float64(time.Second)), true
+}
+
+// Request the goroutine performing the process to stop. It will see it at its
+// next Checkpoint()
+func (p *Process) Cancel() {
+	if p.control == nil {
+		return
+	}
+	p.control.cancel()
+}
+
+func (p *Process) SetPaused(paused bool) {
+	if p.control == nil {
+		return
+	}
+	p.control.setPaused(paused)
+}
+
+func (p *Process) IsPaused() bool {
+	return p.control != nil && p.control.isPaused()
+}
+
+func (p *Process) IsCancelRequested() bool {
+	return p.control != nil && p.control.isCancelled()
+}
+
+// Checkpoint should be called by the goroutine performing the process between units of work.
+// It blocks while the process is paused, and returns ProcessCancelledError once the
+// process is cancelled. It is safe to call on a nil Process.
+func (p *Process) Checkpoint() error {
+	if p == nil || p.control == nil {
+		return nil
+	}
+	return p.control.checkpoint()
 }
 
 type ProcessState int
@@ -41,8 +209,47 @@ const (
 	Successful
 	Cancelled
 	Failed
+	// Waiting for the processes running on the same device to finish
+	Queued
 )
 
+func (p ProcessState) String() string {
+	switch p {
+	case InOperation:
+		return "In operation"
+	case Successful:
+		return "Successful"
+	case Cancelled:
+		return "Cancelled"
+	case Failed:
+		return "Failed"
+	case Queued:
+		return "Queued"
+	default:
+		return "Unknown"
+	}
+}
+
+// Reports whether the process is done, whether it succeeded or not
+func (p ProcessState) isFinished() bool {
+	return p != InOperation && p != Queued
+}
+
+// The state is written by name in the process history
+func (p ProcessState) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *ProcessState) UnmarshalText(text []byte) error {
+	for state := InOperation; state <= Queued; state++ {
+		if state.String() == string(text) {
+			*p = state
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown process state %q", text)
+}
+
 // TODO : Should we store in a global map for efficiency ? At least need to prerender
 // Yes, this is a Render() call, which is expensive
 func (p ProcessState) Icon() string {
@@ -53,6 +260,8 @@ func (p ProcessState) Icon() string {
 		return common.ProcessSuccessfulStyle.Render(icon.Done)
 	case InOperation:
 		return common.ProcessInOperationStyle.Render(icon.InOperation)
+	case Queued:
+		return common.ProcessInOperationStyle.Render(icon.Queued)
 	case Cancelled:
 		fallthrough
 	default:
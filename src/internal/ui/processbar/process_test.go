@@ -0,0 +1,108 @@
+package processbar
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessByteProgress(t *testing.T) {
+	t.Run("Rate and ETA", func(t *testing.T) {
+		p := NewProcess("1", "copy", 1)
+		p.TotalBytes = 3000
+		_, ok := p.ETA()
+		assert.False(t, ok, "ETA should not be known before the rate is sampled")
+
+		// Pretend that the sample started two seconds ago
+		p.rateSampleTime = time.Now().Add(-2 * time.Second)
+		p.AddDoneBytes(1000)
+		assert.InDelta(t, 500, p.Rate, 10)
+		eta, ok := p.ETA()
+		require.True(t, ok)
+		assert.InDelta(t, 4*time.Second, eta, float64(100*time.Millisecond))
+
+		// Updates within the sample interval don't change the rate
+		p.AddDoneBytes(1000)
+		assert.InDelta(t, 500, p.Rate, 10)
+		assert.Equal(t, int64(2000), p.DoneBytes)
+	})
+
+	t.Run("Process without bytes", func(t *testing.T) {
+		p := NewProcess("1", "delete", 1)
+		_, ok := p.ETA()
+		assert.False(t, ok)
+	})
+
+	t.Run("Throttled updates", func(t *testing.T) {
+		m := New()
+		p, err := m.SendAddProcessMsg("copy", 1, true)
+		require.NoError(t, err)
+		m.TrySendingThrottledUpdateProcessMsg(&p)
+		m.TrySendingThrottledUpdateProcessMsg(&p)
+		// One for adding the process, and one update
+		assert.Len(t, m.msgChan, 2)
+	})
+}
+
+func TestProcessDetails(t *testing.T) {
+	m := New()
+	m.SetDimensions(20, 10)
+	p := NewProcess("1", "copy", 4)
+	p.TotalBytes = 2048
+	require.NoError(t, m.AddProcess(p))
+
+	assert.False(t, m.IsProcessDetailsOpen())
+	m.OpenSelectedProcessDetails()
+	assert.True(t, m.IsProcessDetailsOpen())
+	details := m.RenderProcessDetails()
+	assert.Contains(t, details, "copy")
+	assert.Contains(t, details, "0/4")
+	assert.Contains(t, details, "Calculating...")
+
+	m.CloseProcessDetails()
+	assert.False(t, m.IsProcessDetailsOpen())
+
+	t.Run("Failed process with its pending sources", func(t *testing.T) {
+		p := NewProcess("2", "move", 3)
+		p.Operation = "Move"
+		p.Sources, p.Dest = []string{"/src/a", "/src/b", "/src/c"}, "/dest"
+		p.Completed = []string{"/src/a"}
+		p.Retryable = true
+		p.SetFailed(errors.New("/src/b: open /dest/b/" + strings.Repeat("x", 2*detailsWidth) + ": permission denied"))
+		p.DoneTime = time.Now()
+		require.NoError(t, m.AddProcess(p))
+		m.cursor = slices.IndexFunc(m.getSortedProcesses(), func(p Process) bool { return p.ID == "2" })
+		m.OpenSelectedProcessDetails()
+		got, ok := m.GetProcessInDetails()
+		require.True(t, ok)
+		require.Equal(t, "2", got.ID)
+		assert.True(t, got.CanRetry())
+		assert.Equal(t, []string{"/src/b", "/src/c"}, got.PendingSources())
+
+		details := m.RenderProcessDetails()
+		assert.Contains(t, details, "Move")
+		assert.Contains(t, details, "/dest")
+		assert.Contains(t, details, "retry failed items")
+		assert.Contains(t, details, "permission denied", "Long errors are wrapped, not truncated")
+		assert.NotContains(t, details, "pending  /src/c", "Sources below the view are scrolled to")
+
+		for range 20 {
+			m.ProcessDetailsScrollDown()
+		}
+		assert.Equal(t, maxDetailsScroll(got), m.detailsScroll)
+		details = m.RenderProcessDetails()
+		assert.Contains(t, details, "done     /src/a")
+		assert.Contains(t, details, "pending  /src/c")
+		m.ProcessDetailsScrollUp()
+		assert.Equal(t, maxDetailsScroll(got)-1, m.detailsScroll)
+
+		p.Retryable = false
+		assert.False(t, p.CanRetry())
+		m.CloseProcessDetails()
+	})
+}
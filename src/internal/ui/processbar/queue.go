@@ -0,0 +1,151 @@
+package processbar
+
+import (
+	"slices"
+	"sync"
+)
+
+// Queue limits how many processes run at once on each device, so that several large
+// operations on the same disk dont slow each other down. The other processes wait in
+// the queue, and start in its order as the running ones finish.
+// All methods are safe for concurrent use. A nil Queue has no limit.
+type Queue struct {
+	mu sync.Mutex
+	// Max processes running at once on a device. Zero means no limit
+	limit   int
+	running map[string]int
+	// Processes waiting for their turn, the first to start first
+	waiting []*queuedProcess
+}
+
+type queuedProcess struct {
+	id     string
+	device string
+	// Receives true once the process can start, and false if it was cancelled while queued
+	start chan bool
+}
+
+func NewQueue(limit int) *Queue {
+	return &Queue{
+		limit:   limit,
+		running: make(map[string]int),
+	}
+}
+
+// enqueue starts the process right away if there is room on its device, and adds it
+// to the queue otherwise. Returns the channel that tells when a queued process can start
+func (q *Queue) enqueue(id string, device string) (chan bool, bool) {
+	if q == nil {
+		return nil, true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.hasRoom(device) && !slices.ContainsFunc(q.waiting, func(w *queuedProcess) bool {
+		return w.device == device
+	}) {
+		q.running[device]++
+		return nil, true
+	}
+	w := &queuedProcess{id: id, device: device, start: make(chan bool, 1)}
+	q.waiting = append(q.waiting, w)
+	return w.start, false
+}
+
+// Must be called with lock held
+func (q *Queue) hasRoom(device string) bool {
+	return q.limit <= 0 || q.running[device] < q.limit
+}
+
+// release frees the room of a finished process on its device, and starts the next ones
+func (q *Queue) release(device string) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.running[device]--
+	if q.running[device] <= 0 {
+		delete(q.running, device)
+	}
+	q.startWaiting()
+}
+
+// Must be called with lock held
+func (q *Queue) startWaiting() {
+	q.waiting = slices.DeleteFunc(q.waiting, func(w *queuedProcess) bool {
+		if !q.hasRoom(w.device) {
+			return false
+		}
+		q.running[w.device]++
+		w.start <- true
+		return true
+	})
+}
+
+// cancel removes the process from the queue. It is a no-op if the process is not queued
+func (q *Queue) cancel(id string) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := q.indexOf(id)
+	if i < 0 {
+		return
+	}
+	q.waiting[i].start <- false
+	q.waiting = slices.Delete(q.waiting, i, i+1)
+}
+
+// move moves the queued process by delta places, towards the front if delta is negative.
+// Returns false if the process is not queued, or cannot move further
+func (q *Queue) move(id string, delta int) bool {
+	if q == nil {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := q.indexOf(id)
+	j := i + delta
+	if i < 0 || j < 0 || j >= len(q.waiting) {
+		return false
+	}
+	w := q.waiting[i]
+	q.waiting = slices.Insert(slices.Delete(q.waiting, i, i+1), j, w)
+	return true
+}
+
+// prioritize moves the queued process to the front of the queue, so that it is the next
+// to start on its device. Returns false if the process is not queued, or already first
+func (q *Queue) prioritize(id string) bool {
+	if q == nil {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := q.indexOf(id)
+	if i <= 0 {
+		return false
+	}
+	w := q.waiting[i]
+	q.waiting = slices.Insert(slices.Delete(q.waiting, i, i+1), 0, w)
+	return true
+}
+
+// Position returns the place of the process in the queue, starting from 1, or 0 if
+// the process is not queued
+func (q *Queue) Position(id string) int {
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.indexOf(id) + 1
+}
+
+// Must be called with lock held
+func (q *Queue) indexOf(id string) int {
+	return slices.IndexFunc(q.waiting, func(w *queuedProcess) bool {
+		return w.id == id
+	})
+}
@@ -0,0 +1,128 @@
+package processbar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Starts a process that waits for its turn on the device, in a goroutine. The returned
+// channel receives the function that ends its turn, once it has started
+func startQueuedProcess(t *testing.T, m *Model, name string, device string) (Process, chan func()) {
+	t.Helper()
+	p, err := m.SendAddProcessMsg(name, 1, true)
+	require.NoError(t, err)
+	started := make(chan func(), 1)
+	waiting := p
+	go func() {
+		started <- m.WaitForTurn(&waiting, device)
+	}()
+	return p, started
+}
+
+// applyUpdatesUntil applies the updates sent to m, in the test goroutine, until cond is true
+func applyUpdatesUntil(t *testing.T, m *Model, cond func() bool) {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for !cond() {
+		select {
+		case msg := <-m.msgChan:
+			_, err := msg.Apply(m)
+			require.NoError(t, err)
+		case <-timeout:
+			require.FailNow(t, "Condition not met before the timeout")
+		}
+	}
+}
+
+func processState(m *Model, id string) ProcessState {
+	p, ok := m.GetByID(id)
+	if !ok {
+		return InOperation
+	}
+	return p.State
+}
+
+func TestQueue(t *testing.T) {
+	m := New()
+	m.SetDimensions(20, 20)
+	m.SetQueue(NewQueue(1))
+
+	_, firstStarted := startQueuedProcess(t, &m, "first", "/dev/a")
+	endFirst := <-firstStarted
+	second, secondStarted := startQueuedProcess(t, &m, "second", "/dev/a")
+	applyUpdatesUntil(t, &m, func() bool {
+		return processState(&m, second.ID) == Queued
+	})
+	third, thirdStarted := startQueuedProcess(t, &m, "third", "/dev/a")
+	applyUpdatesUntil(t, &m, func() bool {
+		return processState(&m, third.ID) == Queued
+	})
+	assert.Equal(t, 1, m.queue.Position(second.ID))
+	assert.Equal(t, 2, m.queue.Position(third.ID))
+	assert.True(t, m.HasRunningProcesses(), "Queued processes are running processes")
+
+	// Other devices are not limited by the processes of this one
+	_, otherStarted := startQueuedProcess(t, &m, "other", "/dev/b")
+	(<-otherStarted)()
+	applyUpdatesUntil(t, &m, func() bool {
+		return m.cntProcesses() == 4
+	})
+
+	t.Run("Queued processes can be reordered", func(t *testing.T) {
+		sorted := m.getSortedProcesses()
+		require.Equal(t, third.ID, sorted[3].ID, "Queued processes are after the running ones")
+		m.cursor = 3
+		m.MoveSelectedProcessInQueue(-1)
+		assert.Equal(t, 1, m.queue.Position(third.ID))
+		assert.Equal(t, 2, m.cursor, "The cursor follows the process")
+		m.MoveSelectedProcessInQueue(-1)
+		assert.Equal(t, 1, m.queue.Position(third.ID), "Already at the front")
+
+		m.cursor = 3
+		m.PrioritizeSelectedProcess()
+		assert.Equal(t, 1, m.queue.Position(second.ID))
+		assert.Equal(t, 2, m.cursor)
+		assert.Contains(t, m.Render(false), "Queued #1")
+	})
+
+	t.Run("Cancelled processes leave the queue", func(t *testing.T) {
+		m.setCursorToProcess(third.ID)
+		m.CancelSelectedProcess()
+		endThird := <-thirdStarted
+		assert.Equal(t, 0, m.queue.Position(third.ID))
+		require.Error(t, third.Checkpoint())
+		endThird()
+	})
+
+	t.Run("Queued processes start in order", func(t *testing.T) {
+		select {
+		case <-secondStarted:
+			require.Fail(t, "Second process should wait for the first one")
+		case <-time.After(20 * time.Millisecond):
+		}
+		endFirst()
+		endFirst()
+		endSecond := <-secondStarted
+		applyUpdatesUntil(t, &m, func() bool {
+			return processState(&m, second.ID) == InOperation
+		})
+		endSecond()
+		assert.Equal(t, 0, m.queue.Position(second.ID))
+		assert.Empty(t, m.queue.running)
+	})
+}
+
+func TestQueueWithoutLimit(t *testing.T) {
+	q := NewQueue(0)
+	for range 3 {
+		_, started := q.enqueue("id", "/dev/a")
+		assert.True(t, started)
+	}
+	var nilQueue *Queue
+	_, started := nilQueue.enqueue("id", "/dev/a")
+	assert.True(t, started)
+	assert.Equal(t, 0, nilQueue.Position("id"))
+}
@@ -1,5 +1,7 @@
 package sidebar
 
+import "github.com/yorukot/superfile/src/config/icon"
+
 // These are effectively consts
 // Had to use `var` as go doesn't allows const structs
 var pinnedDividerDir = directory{ //nolint: gochecknoglobals // This is more like a const.
@@ -12,5 +14,11 @@ var diskDividerDir = directory{ //nolint: gochecknoglobals // This is more like
 	Location: "Disks+-*/=?",
 }
 
+// Opens the trash browser instead of a directory
+var trashDir = directory{ //nolint: gochecknoglobals // This is more like a const.
+	Name:     icon.Delete + icon.Space + "Trash",
+	Location: "Trash+-*/=?",
+}
+
 // superfile logo + blank line + search bar
 const sideBarInitialHeight = 3
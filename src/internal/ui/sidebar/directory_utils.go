@@ -37,12 +37,12 @@ func fuzzySearch(query string, dirs []directory) []directory {
 }
 
 // Return all sidebar directories
-func getDirectories(pinnedMgr *PinnedManager) []directory {
-	return formDirctorySlice(getWellKnownDirectories(), pinnedMgr.Load(), getExternalMediaFolders())
+func getDirectories(pinnedMgr *PinnedManager, showTrash bool) []directory {
+	return formDirctorySlice(getWellKnownDirectories(showTrash), pinnedMgr.Load(), getExternalMediaFolders())
 }
 
-// Return system default directory e.g. Home, Downloads, etc
-func getWellKnownDirectories() []directory {
+// Return system default directory e.g. Home, Downloads, etc, and the trash entry if showTrash
+func getWellKnownDirectories(showTrash bool) []directory {
 	wellKnownDirectories := []directory{
 		{Location: xdg.Home, Name: icon.Home + icon.Space + "Home"},
 		{Location: xdg.UserDirs.Download, Name: icon.Download + icon.Space + "Downloads"},
@@ -54,16 +54,20 @@ func getWellKnownDirectories() []directory {
 		{Location: xdg.UserDirs.PublicShare, Name: icon.PublicShare + icon.Space + "PublicShare"},
 	}
 
-	return slices.DeleteFunc(wellKnownDirectories, func(d directory) bool {
+	wellKnownDirectories = slices.DeleteFunc(wellKnownDirectories, func(d directory) bool {
 		_, err := os.Stat(d.Location)
 		return err != nil
 	})
+	if showTrash {
+		wellKnownDirectories = append(wellKnownDirectories, trashDir)
+	}
+	return wellKnownDirectories
 }
 
 // Get filtered directories using fuzzy search logic with three haystacks.
-func getFilteredDirectories(query string, pinnedMgr *PinnedManager) []directory {
+func getFilteredDirectories(query string, pinnedMgr *PinnedManager, showTrash bool) []directory {
 	return formDirctorySlice(
-		fuzzySearch(query, getWellKnownDirectories()),
+		fuzzySearch(query, getWellKnownDirectories(showTrash)),
 		fuzzySearch(query, pinnedMgr.Load()),
 		fuzzySearch(query, getExternalMediaFolders()),
 	)
@@ -89,9 +89,9 @@ func (s *Model) HandleSearchBarKey(msg string) {
 // which is a disk heavy operation.
 func (s *Model) UpdateDirectories() {
 	if s.searchBar.Value() != "" {
-		s.directories = getFilteredDirectories(s.searchBar.Value(), s.pinnedMgr)
+		s.directories = getFilteredDirectories(s.searchBar.Value(), s.pinnedMgr, s.showTrash)
 	} else {
-		s.directories = getDirectories(s.pinnedMgr)
+		s.directories = getDirectories(s.pinnedMgr, s.showTrash)
 	}
 	// This is needed, as due to filtering, the cursor might be invalid
 	if s.isCursorInvalid() {
@@ -103,15 +103,17 @@ func (s *Model) TogglePinnedDirectory(dir string) error {
 	return s.pinnedMgr.Toggle(dir)
 }
 
-// New creates a new sidebar model with the given parameters
-func New() Model {
+// New creates a new sidebar model with the given parameters. showTrash lists an entry that
+// opens the trash browser
+func New(showTrash bool) Model {
 	// pinnedMgr is created here, can be done higher up in the call chain
 	pinnedMgr := NewPinnedFileManager(variable.PinnedFile)
 	res := Model{
 		renderIndex: 0,
-		directories: getDirectories(&pinnedMgr),
+		directories: getDirectories(&pinnedMgr, showTrash),
 		searchBar:   common.GenerateSearchBar(),
 		pinnedMgr:   &pinnedMgr,
+		showTrash:   showTrash,
 	}
 
 	// Excluding borders(2), Searchbar Prompt(2), and one extra character than is appended
@@ -15,4 +15,6 @@ type Model struct {
 	renaming    bool
 	searchBar   textinput.Model
 	pinnedMgr   *PinnedManager
+	// Whether the trash entry is listed, after the well-known directories
+	showTrash bool
 }
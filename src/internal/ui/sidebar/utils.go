@@ -68,6 +68,11 @@ func (s *Model) GetCurrentDirectoryLocation() string {
 	return s.directories[s.cursor].Location
 }
 
+// IsTrashSelected returns whether the trash entry is selected, instead of a directory
+func (s *Model) IsTrashSelected() bool {
+	return !s.isCursorInvalid() && s.directories[s.cursor] == trashDir
+}
+
 func (s *Model) pinnedIndexRange() (int, int) {
 	// pinned directories start after well-known directories and the divider
 	// Can't use getPinnedDirectories() here, as if we are in search mode, we would be showing
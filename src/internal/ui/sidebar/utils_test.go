@@ -154,3 +154,30 @@ func Test_resetCursor(t *testing.T) {
 		})
 	}
 }
+
+func Test_IsTrashSelected(t *testing.T) {
+	directories := formDirctorySlice(append(dirSlice(2), trashDir), dirSlice(2), nil)
+	testcases := []struct {
+		name     string
+		cursor   int
+		expected bool
+	}{
+		{"Directory selected", 1, false},
+		{"Trash selected", 2, true},
+		{"Divider is not the trash", 3, false},
+		{"Invalid cursor", len(directories), false},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Model{directories: directories, cursor: tt.cursor}
+			assert.Equal(t, tt.expected, s.IsTrashSelected())
+		})
+	}
+}
+
+func Test_getWellKnownDirectories(t *testing.T) {
+	assert.NotContains(t, getWellKnownDirectories(false), trashDir)
+	dirs := getWellKnownDirectories(true)
+	assert.Equal(t, trashDir, dirs[len(dirs)-1], "Trash should be listed after the well-known directories")
+}
@@ -91,6 +91,34 @@ func ZoxideRenderer(totalHeight int, totalWidth int) *rendering.Renderer {
 	return PromptRenderer(totalHeight, totalWidth)
 }
 
+func TrashRenderer(totalHeight int, totalWidth int) *rendering.Renderer {
+	return PromptRenderer(totalHeight, totalWidth)
+}
+
+func ContentSearchRenderer(totalHeight int, totalWidth int) *rendering.Renderer {
+	return PromptRenderer(totalHeight, totalWidth)
+}
+
+func RenamePlanRenderer(totalHeight int, totalWidth int) *rendering.Renderer {
+	return PromptRenderer(totalHeight, totalWidth)
+}
+
+func PatternRenameRenderer(totalHeight int, totalWidth int) *rendering.Renderer {
+	return PromptRenderer(totalHeight, totalWidth)
+}
+
+func CompressRenderer(totalHeight int, totalWidth int) *rendering.Renderer {
+	return PromptRenderer(totalHeight, totalWidth)
+}
+
+func ExtractRenderer(totalHeight int, totalWidth int) *rendering.Renderer {
+	return PromptRenderer(totalHeight, totalWidth)
+}
+
+func PanelPickerRenderer(totalHeight int, totalWidth int) *rendering.Renderer {
+	return PromptRenderer(totalHeight, totalWidth)
+}
+
 func HelpMenuRenderer(totalHeight int, totalWidth int) *rendering.Renderer {
 	cfg := rendering.DefaultRendererConfig(totalHeight, totalWidth)
 	cfg.ContentFGColor = common.ModalFGColor
@@ -110,6 +138,14 @@ func HelpMenuRenderer(totalHeight int, totalWidth int) *rendering.Renderer {
 	return r
 }
 
+func ProcessDetailsRenderer(totalHeight int, totalWidth int) *rendering.Renderer {
+	return PromptRenderer(totalHeight, totalWidth)
+}
+
+func ProcessHistoryRenderer(totalHeight int, totalWidth int) *rendering.Renderer {
+	return PromptRenderer(totalHeight, totalWidth)
+}
+
 func DefaultFooterRenderer(totalHeight int, totalWidth int, focussed bool) *rendering.Renderer {
 	cfg := rendering.DefaultRendererConfig(totalHeight, totalWidth)
 
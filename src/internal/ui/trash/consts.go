@@ -0,0 +1,16 @@
+package trash
+
+const (
+	trashHeadlineText = "Trash"
+
+	TrashMinWidth  = 30
+	TrashMinHeight = 8
+
+	// Lines other than the items. Borders(2), item count, the hint line and the two dividers
+	nonItemLines = 6
+
+	trashInfoExt    = ".trashinfo"
+	trashInfoHeader = "[Trash Info]"
+	// DeletionDate is in local time, without a time zone
+	trashInfoDateLayout = "2006-01-02T15:04:05"
+)
@@ -0,0 +1,106 @@
+package trash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LoadItems lists the items in an XDG trash, newest first. Items whose .trashinfo
+// file is invalid or whose file is missing are skipped
+func LoadItems(filesDir string, infoDir string) ([]Item, error) {
+	entries, err := os.ReadDir(infoDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash info directory: %w", err)
+	}
+	items := []Item{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), trashInfoExt) {
+			continue
+		}
+		item, err := loadItem(filesDir, filepath.Join(infoDir, entry.Name()))
+		if err != nil {
+			slog.Debug("Skipping trash item", "info", entry.Name(), "error", err)
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletionDate.After(items[j].DeletionDate)
+	})
+	return items, nil
+}
+
+func loadItem(filesDir string, infoPath string) (Item, error) {
+	f, err := os.Open(infoPath)
+	if err != nil {
+		return Item{}, err
+	}
+	defer f.Close()
+	originalPath, deletionDate, err := ParseTrashInfo(f)
+	if err != nil {
+		return Item{}, err
+	}
+	if !filepath.IsAbs(originalPath) {
+		// Trash directories at the top of other filesystems have paths relative to that
+		originalPath = filepath.Join(filepath.Dir(filepath.Dir(filesDir)), originalPath)
+	}
+	name := strings.TrimSuffix(filepath.Base(infoPath), trashInfoExt)
+	path := filepath.Join(filesDir, name)
+	if _, err = os.Lstat(path); err != nil {
+		return Item{}, err
+	}
+	return Item{
+		Name:         name,
+		Path:         path,
+		InfoPath:     infoPath,
+		OriginalPath: originalPath,
+		DeletionDate: deletionDate,
+	}, nil
+}
+
+// ParseTrashInfo returns the original path and the deletion date from the contents
+// of a .trashinfo file. A missing or invalid deletion date is not an error, as the
+// item can still be restored without it
+func ParseTrashInfo(r io.Reader) (string, time.Time, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != trashInfoHeader {
+		return "", time.Time{}, errors.New("missing " + trashInfoHeader + " header")
+	}
+	var originalPath string
+	var deletionDate time.Time
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "Path":
+			path, err := url.PathUnescape(value)
+			if err != nil {
+				return "", time.Time{}, fmt.Errorf("invalid path %q: %w", value, err)
+			}
+			originalPath = path
+		case "DeletionDate":
+			date, err := time.ParseInLocation(trashInfoDateLayout, value, time.Local)
+			if err == nil {
+				deletionDate = date
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", time.Time{}, err
+	}
+	if originalPath == "" {
+		return "", time.Time{}, errors.New("missing original path")
+	}
+	return originalPath, deletionDate, nil
+}
@@ -0,0 +1,86 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrashInfo(t *testing.T) {
+	testdata := []struct {
+		name         string
+		content      string
+		expectedPath string
+		expectedDate time.Time
+		expectedErr  bool
+	}{
+		{
+			name:         "Valid info",
+			content:      "[Trash Info]\nPath=/home/user/file.txt\nDeletionDate=2025-01-02T15:04:05\n",
+			expectedPath: "/home/user/file.txt",
+			expectedDate: time.Date(2025, 1, 2, 15, 4, 5, 0, time.Local),
+		},
+		{
+			name:         "Escaped path",
+			content:      "[Trash Info]\nPath=/home/user/my%20file%25.txt\nDeletionDate=2025-01-02T15:04:05\n",
+			expectedPath: "/home/user/my file%.txt",
+			expectedDate: time.Date(2025, 1, 2, 15, 4, 5, 0, time.Local),
+		},
+		{
+			name:         "Invalid date is ignored",
+			content:      "[Trash Info]\nPath=/file.txt\nDeletionDate=yesterday\n",
+			expectedPath: "/file.txt",
+		},
+		{
+			name:        "Missing header",
+			content:     "Path=/file.txt\n",
+			expectedErr: true,
+		},
+		{
+			name:        "Missing path",
+			content:     "[Trash Info]\nDeletionDate=2025-01-02T15:04:05\n",
+			expectedErr: true,
+		},
+	}
+	for _, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			path, date, err := ParseTrashInfo(strings.NewReader(tt.content))
+			if tt.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPath, path)
+			assert.True(t, tt.expectedDate.Equal(date), "expected %v, got %v", tt.expectedDate, date)
+		})
+	}
+}
+
+func TestLoadItems(t *testing.T) {
+	filesDir, infoDir := setupTrash(t)
+	addTrashItem(t, filesDir, infoDir, "old.txt", "/home/user/old.txt", "2024-01-01T10:00:00")
+	addTrashItem(t, filesDir, infoDir, "new.txt", "/home/user/new.txt", "2025-01-01T10:00:00")
+	addTrashItem(t, filesDir, infoDir, "relative.txt", "relative.txt", "2023-01-01T10:00:00")
+	// Info without the file, and a file without info
+	require.NoError(t, os.WriteFile(filepath.Join(infoDir, "gone.txt.trashinfo"),
+		[]byte("[Trash Info]\nPath=/home/user/gone.txt\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(filesDir, "orphan.txt"), nil, 0o644))
+
+	items, err := LoadItems(filesDir, infoDir)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, "new.txt", items[0].Name)
+	assert.Equal(t, filepath.Join(filesDir, "new.txt"), items[0].Path)
+	assert.Equal(t, filepath.Join(infoDir, "new.txt.trashinfo"), items[0].InfoPath)
+	assert.Equal(t, "/home/user/new.txt", items[0].OriginalPath)
+	assert.Equal(t, "old.txt", items[1].Name)
+	assert.Equal(t, filepath.Join(filepath.Dir(filepath.Dir(filesDir)), "relative.txt"), items[2].OriginalPath)
+
+	_, err = LoadItems(filesDir, filepath.Join(infoDir, "missing"))
+	require.Error(t, err)
+}
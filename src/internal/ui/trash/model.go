@@ -0,0 +1,102 @@
+package trash
+
+import (
+	"log/slog"
+
+	variable "github.com/yorukot/superfile/src/config"
+	"github.com/yorukot/superfile/src/config/icon"
+)
+
+// DefaultModel browses the XDG trash of the user
+func DefaultModel(supported bool) Model {
+	return New(variable.LinuxTrashDirectoryFiles, variable.LinuxTrashDirectoryInfo, supported,
+		TrashMinHeight, TrashMinWidth)
+}
+
+func New(filesDir string, infoDir string, supported bool, maxHeight int, width int) Model {
+	m := Model{
+		headline:  icon.Delete + icon.Space + trashHeadlineText,
+		filesDir:  filesDir,
+		infoDir:   infoDir,
+		supported: supported,
+		items:     []Item{},
+	}
+	m.SetMaxHeight(maxHeight)
+	m.SetWidth(width)
+	return m
+}
+
+func (m *Model) Open() {
+	m.open = true
+	m.cursor = 0
+	m.renderIndex = 0
+	m.Reload()
+}
+
+func (m *Model) Close() {
+	m.open = false
+	m.items = []Item{}
+	m.loadErr = nil
+	m.cursor = 0
+	m.renderIndex = 0
+}
+
+// Reload re-reads the trash, keeping the cursor in place as far as possible
+func (m *Model) Reload() {
+	if !m.supported {
+		return
+	}
+	m.items, m.loadErr = LoadItems(m.filesDir, m.infoDir)
+	if m.loadErr != nil {
+		slog.Error("Could not load trash items", "error", m.loadErr)
+	}
+	m.cursor = max(min(m.cursor, len(m.items)-1), 0)
+	m.updateRenderIndex()
+}
+
+func (m *Model) IsOpen() bool {
+	return m.open
+}
+
+func (m *Model) IsSupported() bool {
+	return m.supported
+}
+
+// GetSelectedItem returns the item under the cursor, and false if the trash is empty
+func (m *Model) GetSelectedItem() (Item, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.items) {
+		return Item{}, false
+	}
+	return m.items[m.cursor], true
+}
+
+func (m *Model) GetItems() []Item {
+	out := make([]Item, len(m.items))
+	copy(out, m.items)
+	return out
+}
+
+func (m *Model) GetWidth() int {
+	return m.width
+}
+
+func (m *Model) GetMaxHeight() int {
+	return m.maxHeight
+}
+
+func (m *Model) SetWidth(width int) {
+	if width < TrashMinWidth {
+		slog.Warn("Trash initialized with too less width", "width", width)
+		width = TrashMinWidth
+	}
+	m.width = width
+}
+
+func (m *Model) SetMaxHeight(maxHeight int) {
+	if maxHeight < TrashMinHeight {
+		slog.Warn("Trash initialized with too less maxHeight", "maxHeight", maxHeight)
+		maxHeight = TrashMinHeight
+	}
+	m.maxHeight = maxHeight
+	m.updateRenderIndex()
+}
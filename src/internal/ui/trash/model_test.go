@@ -0,0 +1,54 @@
+package trash
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNavigation(t *testing.T) {
+	filesDir, infoDir := setupTrash(t)
+	for i := range 5 {
+		name := fmt.Sprintf("file%d.txt", i)
+		addTrashItem(t, filesDir, infoDir, name, "/"+name, fmt.Sprintf("2025-01-0%dT10:00:00", 5-i))
+	}
+	// Room for 3 items
+	m := New(filesDir, infoDir, true, nonItemLines+3, 80)
+	m.Open()
+	require.Len(t, m.GetItems(), 5)
+
+	item, ok := m.GetSelectedItem()
+	require.True(t, ok)
+	assert.Equal(t, "file0.txt", item.Name)
+
+	m.ListUp()
+	assert.Equal(t, 4, m.cursor, "Should wrap to the bottom")
+	assert.Equal(t, 2, m.renderIndex)
+	m.ListDown()
+	assert.Equal(t, 0, m.cursor, "Should wrap to the top")
+	assert.Equal(t, 0, m.renderIndex)
+
+	m.ListUp()
+	require.NoError(t, os.Remove(item.InfoPath))
+	m.Reload()
+	assert.Len(t, m.GetItems(), 4)
+	assert.Equal(t, 3, m.cursor, "Cursor should stay within the items")
+
+	m.Close()
+	assert.False(t, m.IsOpen())
+	assert.Empty(t, m.GetItems())
+}
+
+func TestUnsupported(t *testing.T) {
+	filesDir, infoDir := setupTrash(t)
+	addTrashItem(t, filesDir, infoDir, "file.txt", "/file.txt", "2025-01-01T10:00:00")
+	m := New(filesDir, infoDir, false, 20, 80)
+	m.Open()
+	assert.True(t, m.IsOpen())
+	assert.Empty(t, m.GetItems())
+	_, ok := m.GetSelectedItem()
+	assert.False(t, ok)
+}
@@ -0,0 +1,39 @@
+package trash
+
+func (m *Model) ListUp() {
+	if len(m.items) == 0 {
+		return
+	}
+	if m.cursor > 0 {
+		m.cursor--
+	} else {
+		m.cursor = len(m.items) - 1 // Wrap to bottom
+	}
+	m.updateRenderIndex()
+}
+
+func (m *Model) ListDown() {
+	if len(m.items) == 0 {
+		return
+	}
+	if m.cursor < len(m.items)-1 {
+		m.cursor++
+	} else {
+		m.cursor = 0 // Wrap to top
+	}
+	m.updateRenderIndex()
+}
+
+func (m *Model) visibleItemCnt() int {
+	return m.maxHeight - nonItemLines
+}
+
+func (m *Model) updateRenderIndex() {
+	if m.cursor < m.renderIndex {
+		m.renderIndex = m.cursor
+	}
+	if m.cursor >= m.renderIndex+m.visibleItemCnt() {
+		m.renderIndex = m.cursor - m.visibleItemCnt() + 1
+	}
+	m.renderIndex = max(min(m.renderIndex, len(m.items)-m.visibleItemCnt()), 0)
+}
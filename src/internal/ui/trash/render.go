@@ -0,0 +1,70 @@
+package trash
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yorukot/superfile/src/internal/common"
+	"github.com/yorukot/superfile/src/internal/ui"
+)
+
+// Width of the deletion date column, including the padding and the separator
+const dateColumnWidth = 20
+
+func (m *Model) Render() string {
+	r := ui.TrashRenderer(m.maxHeight, m.width)
+	r.SetBorderTitle(m.headline)
+
+	switch {
+	case !m.supported:
+		r.AddLines(" Only the XDG trash can be browsed")
+		return r.Render()
+	case m.loadErr != nil:
+		r.AddLines(" Could not read the trash: " + m.loadErr.Error())
+		return r.Render()
+	}
+
+	r.AddLines(fmt.Sprintf(" %d item(s) in trash", len(m.items)))
+	r.AddSection()
+	if len(m.items) == 0 {
+		r.AddLines(" Trash is empty")
+	}
+	end := min(m.renderIndex+m.visibleItemCnt(), len(m.items))
+	for i := m.renderIndex; i < end; i++ {
+		line := renderItem(m.items[i], m.width-2)
+		if i == m.cursor {
+			line = common.ModalCursorStyle.Render(line)
+		}
+		r.AddLines(line)
+	}
+	r.AddSection()
+	r.AddLines(" " + m.renderHints())
+	return r.Render()
+}
+
+// Renders the deletion date and the original path of the item in a line of given width
+func renderItem(item Item, width int) string {
+	date := "Unknown"
+	if !item.DeletionDate.IsZero() {
+		date = item.DeletionDate.Format(time.DateOnly + " 15:04")
+	}
+	path := common.TruncateTextBeginning(item.OriginalPath, width-dateColumnWidth, "...")
+	return fmt.Sprintf(" %-16s | %s", date, path)
+}
+
+func (m *Model) renderHints() string {
+	hints := []string{
+		hotkeyHint(common.Hotkeys.Confirm, "restore"),
+		hotkeyHint(common.Hotkeys.PermanentlyDeleteItems, "delete"),
+		hotkeyHint(common.Hotkeys.EmptyTrash, "empty"),
+	}
+	return strings.Join(hints, "  ")
+}
+
+func hotkeyHint(hotkeys []string, action string) string {
+	if len(hotkeys) == 0 {
+		return ""
+	}
+	return hotkeys[0] + ": " + action
+}
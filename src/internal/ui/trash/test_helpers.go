@@ -0,0 +1,27 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupTrash(t *testing.T) (string, string) {
+	t.Helper()
+	trashDir := filepath.Join(t.TempDir(), "Trash")
+	filesDir := filepath.Join(trashDir, "files")
+	infoDir := filepath.Join(trashDir, "info")
+	require.NoError(t, os.MkdirAll(filesDir, 0o755))
+	require.NoError(t, os.MkdirAll(infoDir, 0o755))
+	return filesDir, infoDir
+}
+
+func addTrashItem(t *testing.T, filesDir string, infoDir string, name string, originalPath string,
+	deletionDate string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(filesDir, name), []byte(name), 0o644))
+	info := "[Trash Info]\nPath=" + originalPath + "\nDeletionDate=" + deletionDate + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(infoDir, name+".trashinfo"), []byte(info), 0o644))
+}
@@ -0,0 +1,34 @@
+package trash
+
+import "time"
+
+// No need to name it as TrashModel. It will be imported as trash.Model
+type Model struct {
+	// Configuration
+	headline string
+	filesDir string
+	infoDir  string
+	// Only the XDG trash can be browsed
+	supported bool
+
+	// State
+	open        bool
+	items       []Item
+	loadErr     error
+	cursor      int
+	renderIndex int
+
+	// Dimensions
+	width     int
+	maxHeight int
+}
+
+// An item in the trash, as described by its .trashinfo file
+type Item struct {
+	// Name of the item inside the trash
+	Name         string
+	Path         string
+	InfoPath     string
+	OriginalPath string
+	DeletionDate time.Time
+}
@@ -0,0 +1,304 @@
+package zipcrypto
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // WinZip AES derives its keys with HMAC-SHA1
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+// aesExtra is the extra field of the files encrypted with WinZip AES
+type aesExtra struct {
+	version uint16
+	keyLen  int
+	// Compression method of the content, before it was encrypted
+	method uint16
+}
+
+func parseAESExtra(extra []byte) (aesExtra, error) {
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra)
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		extra = extra[4:]
+		if size > len(extra) {
+			break
+		}
+		if id != aesExtraID || size < aesExtraLen {
+			extra = extra[size:]
+			continue
+		}
+		e := aesExtra{
+			version: binary.LittleEndian.Uint16(extra),
+			method:  binary.LittleEndian.Uint16(extra[5:]),
+		}
+		// Strengths 1 to 3 are AES-128, AES-192 and AES-256
+		strength := int(extra[4])
+		if strength < 1 || strength > aesStrength256 {
+			return e, fmt.Errorf("unknown AES strength %d", strength)
+		}
+		e.keyLen = 8 + 8*strength
+		return e, nil
+	}
+	return aesExtra{}, errors.New("missing AES extra field")
+}
+
+func (e aesExtra) bytes() []byte {
+	b := make([]byte, 4+aesExtraLen)
+	binary.LittleEndian.PutUint16(b, aesExtraID)
+	binary.LittleEndian.PutUint16(b[2:], aesExtraLen)
+	binary.LittleEndian.PutUint16(b[4:], e.version)
+	copy(b[6:], aesVendorID)
+	b[8] = aesStrength256
+	binary.LittleEndian.PutUint16(b[9:], e.method)
+	return b
+}
+
+// deriveAESKeys returns the key to encrypt with, the key of the authentication code, and the
+// value the password is verified with
+func deriveAESKeys(password string, salt []byte, keyLen int) ([]byte, []byte, []byte, error) {
+	key, err := pbkdf2.Key(sha1.New, password, salt, aesKeyIterations, 2*keyLen+aesVerifierLen)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return key[:keyLen], key[keyLen : 2*keyLen], key[2*keyLen:], nil
+}
+
+// winZipCTR is AES in counter mode as used by WinZip. Unlike cipher.NewCTR, the counter is
+// little endian, and the first block is encrypted with a counter of 1
+type winZipCTR struct {
+	block     cipher.Block
+	counter   []byte
+	keyStream []byte
+	used      int
+}
+
+func newWinZipCTR(block cipher.Block) *winZipCTR {
+	return &winZipCTR{
+		block:     block,
+		counter:   make([]byte, block.BlockSize()),
+		keyStream: make([]byte, block.BlockSize()),
+		used:      block.BlockSize(),
+	}
+}
+
+func (c *winZipCTR) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if c.used == len(c.keyStream) {
+			for j := range c.counter {
+				c.counter[j]++
+				if c.counter[j] != 0 {
+					break
+				}
+			}
+			c.block.Encrypt(c.keyStream, c.counter)
+			c.used = 0
+		}
+		dst[i] = src[i] ^ c.keyStream[c.used]
+		c.used++
+	}
+}
+
+// newAESStream returns the cipher stream and the authentication code of a file encrypted
+// with WinZip AES
+func newAESStream(encKey, authKey []byte) (cipher.Stream, hash.Hash, error) {
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newWinZipCTR(block), hmac.New(sha1.New, authKey), nil
+}
+
+func openAES(f *zip.File, raw io.Reader, password string) (io.ReadCloser, error) {
+	extra, err := parseAESExtra(f.Extra)
+	if err != nil {
+		return nil, err
+	}
+	saltLen := extra.keyLen / 2
+	header := make([]byte, saltLen+aesVerifierLen)
+	if _, err = io.ReadFull(raw, header); err != nil {
+		return nil, err
+	}
+	encKey, authKey, verifier, err := deriveAESKeys(password, header[:saltLen], extra.keyLen)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(verifier, header[saltLen:]) != 1 {
+		return nil, ErrWrongPassword
+	}
+	dataLen := int64(f.CompressedSize64) - int64(len(header)) - aesAuthCodeLen //nolint:gosec // Sizes fit in int64
+	if dataLen < 0 {
+		return nil, errors.New("encrypted file is too short")
+	}
+	stream, mac, err := newAESStream(encKey, authKey)
+	if err != nil {
+		return nil, err
+	}
+	plain := cipher.StreamReader{S: stream, R: io.TeeReader(io.LimitReader(raw, dataLen), mac)}
+	content, err := decompress(plain, extra.method)
+	if err != nil {
+		return nil, err
+	}
+	crc := crc32.NewIEEE()
+	check := func() error {
+		// The compressed content can end before all of the data is read
+		if _, err := io.Copy(io.Discard, plain); err != nil {
+			return err
+		}
+		authCode := make([]byte, aesAuthCodeLen)
+		if _, err := io.ReadFull(raw, authCode); err != nil {
+			return err
+		}
+		if !hmac.Equal(mac.Sum(nil)[:aesAuthCodeLen], authCode) {
+			return ErrAuthentication
+		}
+		if extra.version == aesVersion1 && crc.Sum32() != f.CRC32 {
+			return ErrAuthentication
+		}
+		return nil
+	}
+	return &checkedReader{r: io.TeeReader(content, crc), c: content, check: check}, nil
+}
+
+// aesWriter encrypts the content of a file while it is written. The sizes of the file are
+// only known once it is closed
+type aesWriter struct {
+	fh         *zip.FileHeader
+	raw        io.Writer
+	compressor io.WriteCloser
+	mac        hash.Hash
+	// Bytes written before and after compression
+	written    int64
+	compressed *countWriter
+	headerLen  int
+}
+
+// Create adds a file to w, encrypted with AES-256 as specified by WinZip (AE-2). Its content is
+// compressed with the method of fh, Store or Deflate, at level. The returned writer must be
+// closed before anything else is added to w, as the sizes of the file are written then
+func Create(w *zip.Writer, fh *zip.FileHeader, password string, level int) (io.WriteCloser, error) {
+	if password == "" {
+		return nil, ErrPasswordRequired
+	}
+	if fh.Method != zip.Store && fh.Method != zip.Deflate {
+		return nil, zip.ErrAlgorithm
+	}
+	salt := make([]byte, aes256KeyLen/2)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	encKey, authKey, verifier, err := deriveAESKeys(password, salt, aes256KeyLen)
+	if err != nil {
+		return nil, err
+	}
+	stream, mac, err := newAESStream(encKey, authKey)
+	if err != nil {
+		return nil, err
+	}
+
+	method := fh.Method
+	prepareAESHeader(fh)
+	raw, err := w.CreateRaw(fh)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = raw.Write(append(salt, verifier...)); err != nil {
+		return nil, err
+	}
+	compressed := &countWriter{w: io.MultiWriter(raw, mac)}
+	encrypted := cipher.StreamWriter{S: stream, W: compressed}
+	aw := &aesWriter{fh: fh, raw: raw, mac: mac, compressed: compressed, headerLen: len(salt) + len(verifier)}
+	if method == zip.Deflate {
+		aw.compressor, err = flate.NewWriter(encrypted, level)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		aw.compressor = encrypted
+	}
+	return aw, nil
+}
+
+// prepareAESHeader sets what zip.Writer.CreateHeader would, as CreateRaw does not, and marks
+// the file as encrypted. The sizes are written after the content, once they are known
+func prepareAESHeader(fh *zip.FileHeader) {
+	fh.Extra = append(fh.Extra, aesExtra{version: aesVersion2, method: fh.Method}.bytes()...)
+	fh.Method = methodAES
+	fh.Flags |= flagEncrypted | flagDataDescriptor
+	if !isASCII(fh.Name) && utf8.ValidString(fh.Name) && !fh.NonUTF8 {
+		fh.Flags |= flagUTF8
+	}
+	fh.CreatorVersion = fh.CreatorVersion&0xff00 | zipVersionAES
+	fh.ReaderVersion = zipVersionAES
+	fh.CRC32 = 0
+	fh.CompressedSize64, fh.UncompressedSize64 = 0, 0
+	if !fh.Modified.IsZero() {
+		fh.ModifiedDate, fh.ModifiedTime = timeToMsDosTime(fh.Modified)
+		// Extended timestamp, with the modification time only
+		extTime := make([]byte, 9)
+		binary.LittleEndian.PutUint16(extTime, extTimeExtraID)
+		binary.LittleEndian.PutUint16(extTime[2:], 5)
+		extTime[4] = 1
+		binary.LittleEndian.PutUint32(extTime[5:], uint32(fh.Modified.Unix())) //nolint:gosec // Like archive/zip
+		fh.Extra = append(fh.Extra, extTime...)
+	}
+}
+
+func (a *aesWriter) Write(p []byte) (int, error) {
+	n, err := a.compressor.Write(p)
+	a.written += int64(n)
+	return n, err
+}
+
+func (a *aesWriter) Close() error {
+	if err := a.compressor.Close(); err != nil {
+		return err
+	}
+	if _, err := a.raw.Write(a.mac.Sum(nil)[:aesAuthCodeLen]); err != nil {
+		return err
+	}
+	a.fh.CompressedSize64 = uint64(int64(a.headerLen) + a.compressed.n + aesAuthCodeLen) //nolint:gosec // Not negative
+	a.fh.UncompressedSize64 = uint64(a.written)                                          //nolint:gosec // Not negative
+	a.fh.CompressedSize = uint32(min(a.fh.CompressedSize64, math.MaxUint32))
+	a.fh.UncompressedSize = uint32(min(a.fh.UncompressedSize64, math.MaxUint32))
+	return nil
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func isASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func timeToMsDosTime(t time.Time) (uint16, uint16) {
+	date := uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9) //nolint:gosec // Like archive/zip
+	clock := uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)     //nolint:gosec // Like archive/zip
+	return date, clock
+}
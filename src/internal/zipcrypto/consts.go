@@ -0,0 +1,32 @@
+package zipcrypto
+
+const (
+	// Flag bits of zip file headers
+	flagEncrypted      = 0x1
+	flagDataDescriptor = 0x8
+	flagUTF8           = 0x800
+
+	// Compression method of the files encrypted with WinZip AES. Their actual method is
+	// in the AES extra field
+	methodAES = 99
+	// Version needed to extract files encrypted with WinZip AES
+	zipVersionAES = 51
+
+	aesExtraID  = 0x9901
+	aesExtraLen = 7
+	// AE-1 keeps the CRC of the content. AE-2 does not, as the authentication code covers it
+	aesVersion1 = 1
+	aesVersion2 = 2
+	aesVendorID = "AE"
+	// Strength 3 is AES-256
+	aesStrength256   = 3
+	aes256KeyLen     = 32
+	aesKeyIterations = 1000
+	aesVerifierLen   = 2
+	aesAuthCodeLen   = 10
+
+	// Length of the header before the content of files with the traditional PKWARE encryption
+	pkwareHeaderLen = 12
+
+	extTimeExtraID = 0x5455
+)
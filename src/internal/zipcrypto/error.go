@@ -0,0 +1,11 @@
+package zipcrypto
+
+import "errors"
+
+var (
+	ErrPasswordRequired = errors.New("the archive is encrypted, and needs a password")
+	ErrWrongPassword    = errors.New("wrong password")
+	// The content does not match its authentication code or checksum. The archive is
+	// corrupt, or the password is wrong
+	ErrAuthentication = errors.New("encrypted content failed authentication")
+)
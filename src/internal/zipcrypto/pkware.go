@@ -0,0 +1,78 @@
+package zipcrypto
+
+import (
+	"archive/zip"
+	"hash/crc32"
+	"io"
+)
+
+// pkwareKeys is the state of the traditional PKWARE encryption, also known as ZipCrypto
+type pkwareKeys [3]uint32
+
+func newPKWAREKeys(password string) *pkwareKeys {
+	k := &pkwareKeys{0x12345678, 0x23456789, 0x34567890}
+	for _, b := range []byte(password) {
+		k.update(b)
+	}
+	return k
+}
+
+func (k *pkwareKeys) update(b byte) {
+	k[0] = crc32Update(k[0], b)
+	k[1] = (k[1]+k[0]&0xff)*134775813 + 1
+	k[2] = crc32Update(k[2], byte(k[1]>>24))
+}
+
+func (k *pkwareKeys) streamByte() byte {
+	t := k[2] | 2
+	return byte((t * (t ^ 1)) >> 8)
+}
+
+func (k *pkwareKeys) decrypt(buf []byte) {
+	for i := range buf {
+		buf[i] ^= k.streamByte()
+		k.update(buf[i])
+	}
+}
+
+func crc32Update(crc uint32, b byte) uint32 {
+	return crc32.IEEETable[byte(crc)^b] ^ (crc >> 8)
+}
+
+type pkwareReader struct {
+	r    io.Reader
+	keys *pkwareKeys
+}
+
+func (p *pkwareReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.keys.decrypt(buf[:n])
+	return n, err
+}
+
+func openPKWARE(f *zip.File, raw io.Reader, password string) (io.ReadCloser, error) {
+	keys := newPKWAREKeys(password)
+	header := make([]byte, pkwareHeaderLen)
+	if _, err := io.ReadFull(raw, header); err != nil {
+		return nil, err
+	}
+	keys.decrypt(header)
+	// The last byte of the header is the high byte of the CRC, or of the modification time
+	// when the CRC is only written after the content
+	check := header[pkwareHeaderLen-1]
+	if check != byte(f.CRC32>>24) && (f.Flags&flagDataDescriptor == 0 || check != byte(f.ModifiedTime>>8)) {
+		return nil, ErrWrongPassword
+	}
+	content, err := decompress(&pkwareReader{r: raw, keys: keys}, f.Method)
+	if err != nil {
+		return nil, err
+	}
+	crc := crc32.NewIEEE()
+	checkCRC := func() error {
+		if crc.Sum32() != f.CRC32 {
+			return ErrAuthentication
+		}
+		return nil
+	}
+	return &checkedReader{r: io.TeeReader(content, crc), c: content, check: checkCRC}, nil
+}
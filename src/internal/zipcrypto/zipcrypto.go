@@ -0,0 +1,69 @@
+package zipcrypto
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"errors"
+	"io"
+)
+
+// IsEncrypted reports whether a file of a zip archive is encrypted
+func IsEncrypted(f *zip.File) bool {
+	return f.Flags&flagEncrypted != 0
+}
+
+// Open opens an encrypted file of a zip archive for reading. Files encrypted with WinZip AES
+// and with the traditional PKWARE encryption are supported. The password is checked right
+// away, and the content is checked once it is read to the end
+func Open(f *zip.File, password string) (io.ReadCloser, error) {
+	if password == "" {
+		return nil, ErrPasswordRequired
+	}
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, err
+	}
+	if f.Method == methodAES {
+		return openAES(f, raw, password)
+	}
+	return openPKWARE(f, raw, password)
+}
+
+// decompress returns the content of a file compressed with method. Only the methods that
+// archive/zip supports by default are supported
+func decompress(r io.Reader, method uint16) (io.ReadCloser, error) {
+	switch method {
+	case zip.Store:
+		return io.NopCloser(r), nil
+	case zip.Deflate:
+		return flate.NewReader(r), nil
+	}
+	return nil, zip.ErrAlgorithm
+}
+
+// checkedReader calls check once the content is read to the end, and returns its error
+// instead of io.EOF
+type checkedReader struct {
+	r     io.Reader
+	c     io.Closer
+	check func() error
+	err   error
+}
+
+func (c *checkedReader) Read(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.r.Read(p)
+	if errors.Is(err, io.EOF) {
+		if checkErr := c.check(); checkErr != nil {
+			err = checkErr
+		}
+	}
+	c.err = err
+	return n, err
+}
+
+func (c *checkedReader) Close() error {
+	return c.c.Close()
+}
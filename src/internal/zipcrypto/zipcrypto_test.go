@@ -0,0 +1,189 @@
+package zipcrypto
+
+import (
+	"archive/zip"
+	"bytes"
+	"hash/crc32"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPassword = "hunter2"
+
+// Content long enough to take more than one AES block, and to be compressed
+var testContent = strings.Repeat("superfile encrypted content ", 100) //nolint:gochecknoglobals // This is effectively const.
+
+func writeAESZip(t *testing.T, method uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	_, err := w.Create("dir/")
+	require.NoError(t, err)
+	for _, name := range []string{"dir/a.txt", "b.txt"} {
+		fw, err := Create(w, &zip.FileHeader{Name: name, Method: method, Modified: time.Now()}, testPassword, 6)
+		require.NoError(t, err)
+		_, err = io.WriteString(fw, name+testContent)
+		require.NoError(t, err)
+		require.NoError(t, fw.Close())
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// writePKWAREZip writes a zip with a single file encrypted with the traditional PKWARE encryption
+func writePKWAREZip(t *testing.T, name string, content string) []byte {
+	t.Helper()
+	fh := &zip.FileHeader{Name: name, Method: zip.Store, Flags: flagEncrypted,
+		CRC32: crc32.ChecksumIEEE([]byte(content))}
+	header := make([]byte, pkwareHeaderLen)
+	header[pkwareHeaderLen-1] = byte(fh.CRC32 >> 24)
+	data := append(header, content...)
+	keys := newPKWAREKeys(testPassword)
+	for i, b := range data {
+		data[i] = b ^ keys.streamByte()
+		keys.update(b)
+	}
+	fh.CompressedSize64 = uint64(len(data))
+	fh.UncompressedSize64 = uint64(len(content))
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	raw, err := w.CreateRaw(fh)
+	require.NoError(t, err)
+	_, err = raw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func readZipFile(t *testing.T, archive []byte, name string) *zip.File {
+	t.Helper()
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	for _, f := range r.File {
+		if f.Name == name {
+			return f
+		}
+	}
+	require.Fail(t, "missing file", name)
+	return nil
+}
+
+func readEncrypted(f *zip.File, password string) (string, error) {
+	rc, err := Open(f, password)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	return string(data), err
+}
+
+func TestAES(t *testing.T) {
+	for _, method := range []uint16{zip.Deflate, zip.Store} {
+		archive := writeAESZip(t, method)
+
+		assert.False(t, IsEncrypted(readZipFile(t, archive, "dir/")))
+		for _, name := range []string{"dir/a.txt", "b.txt"} {
+			f := readZipFile(t, archive, name)
+			assert.True(t, IsEncrypted(f))
+			assert.Equal(t, uint64(len(name+testContent)), f.UncompressedSize64)
+			content, err := readEncrypted(f, testPassword)
+			require.NoError(t, err)
+			assert.Equal(t, name+testContent, content)
+
+			_, err = readEncrypted(f, "wrong")
+			require.ErrorIs(t, err, ErrWrongPassword)
+			_, err = readEncrypted(f, "")
+			require.ErrorIs(t, err, ErrPasswordRequired)
+		}
+	}
+
+	t.Run("Tampered content fails authentication", func(t *testing.T) {
+		archive := writeAESZip(t, zip.Store)
+		f := readZipFile(t, archive, "b.txt")
+		offset, err := f.DataOffset()
+		require.NoError(t, err)
+		// After the salt and the password verifier
+		archive[offset+aes256KeyLen/2+aesVerifierLen+5] ^= 0xff
+		_, err = readEncrypted(readZipFile(t, archive, "b.txt"), testPassword)
+		require.ErrorIs(t, err, ErrAuthentication)
+	})
+}
+
+func TestPKWARE(t *testing.T) {
+	archive := writePKWAREZip(t, "legacy.txt", testContent)
+	f := readZipFile(t, archive, "legacy.txt")
+	assert.True(t, IsEncrypted(f))
+
+	content, err := readEncrypted(f, testPassword)
+	require.NoError(t, err)
+	assert.Equal(t, testContent, content)
+	_, err = readEncrypted(f, "wrong password")
+	require.ErrorIs(t, err, ErrWrongPassword)
+	_, err = readEncrypted(f, "")
+	require.ErrorIs(t, err, ErrPasswordRequired)
+}
+
+// The zip in testdata was created with Info-ZIP's `zip -P hunter2`, to test
+// against an archive that was not written by the code under test
+func TestPKWAREExternalZip(t *testing.T) {
+	r, err := zip.OpenReader(filepath.Join("testdata", "pkware.zip"))
+	require.NoError(t, err)
+	defer r.Close()
+	require.Len(t, r.File, 1)
+	f := r.File[0]
+	assert.Equal(t, "legacy.txt", f.Name)
+	assert.True(t, IsEncrypted(f))
+
+	content, err := readEncrypted(f, testPassword)
+	require.NoError(t, err)
+	assert.Equal(t, testContent, content)
+	_, err = readEncrypted(f, "wrong password")
+	require.ErrorIs(t, err, ErrWrongPassword)
+	_, err = readEncrypted(f, "")
+	require.ErrorIs(t, err, ErrPasswordRequired)
+}
+
+// The zip in testdata was created with libarchive's
+// `bsdtar --format zip --options zip:encryption=aes256 --passphrase hunter2`. It writes
+// AE-2 for files shorter than 20 bytes, and AE-1 for the others
+func TestAESExternalZip(t *testing.T) {
+	r, err := zip.OpenReader(filepath.Join("testdata", "aes.zip"))
+	require.NoError(t, err)
+	defer r.Close()
+	require.Len(t, r.File, 2)
+
+	testdata := []struct {
+		name    string
+		version uint16
+		content string
+	}{
+		{"ae1.txt", aesVersion1, testContent},
+		{"ae2.txt", aesVersion2, "superfile"},
+	}
+	for i, tt := range testdata {
+		t.Run(tt.name, func(t *testing.T) {
+			f := r.File[i]
+			assert.Equal(t, tt.name, f.Name)
+			assert.True(t, IsEncrypted(f))
+			extra, err := parseAESExtra(f.Extra)
+			require.NoError(t, err)
+			assert.Equal(t, tt.version, extra.version)
+
+			content, err := readEncrypted(f, testPassword)
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, content)
+			_, err = readEncrypted(f, "wrong password")
+			require.ErrorIs(t, err, ErrWrongPassword)
+			_, err = readEncrypted(f, "")
+			require.ErrorIs(t, err, ErrPasswordRequired)
+		})
+	}
+}